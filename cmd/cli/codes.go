@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/codegen"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+	"gorm.io/driver/sqlite" // Driver SQLite pour GORM
+	"gorm.io/gorm"
+)
+
+// CodesCmd regroupe les commandes d'administration des codes courts.
+var CodesCmd = &cobra.Command{
+	Use:   "codes",
+	Short: "Administre la génération des codes courts.",
+}
+
+// KeyspaceCmd représente la commande 'codes keyspace'
+var KeyspaceCmd = &cobra.Command{
+	Use:   "keyspace",
+	Short: "Affiche l'occupation de l'espace des codes courts.",
+	Long: `Cette commande affiche, pour chaque domaine et chaque longueur, le nombre de codes déjà
+utilisés (liens, corbeille et quarantaine comprises, alias inclus) et la part de l'espace de codes
+qu'ils occupent avec le générateur configuré. Pour un générateur aléatoire, cette part est aussi
+la probabilité qu'un nouveau code soit déjà pris ; au-delà de codes.max_collision_rate, les codes
+gagnent automatiquement un caractère.
+
+Exemple:
+  url-shortener codes keyspace`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := cmd2.Cfg
+		if cfg == nil {
+			log.Fatalf("FATAL: Configuration non chargée")
+		}
+
+		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		if err != nil {
+			log.Fatalf("FATAL: Échec de la connexion à la base de données: %v", err)
+		}
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			log.Fatalf("FATAL: Échec de l'obtention de la base de données SQL sous-jacente: %v", err)
+		}
+		defer sqlDB.Close()
+
+		linkRepo := repository.NewLinkRepository(db)
+		linkService := services.NewLinkService(linkRepo)
+		if err := cmd2.SetupCodeGeneration(linkService, db); err != nil {
+			log.Fatalf("FATAL: Échec de la configuration de la génération des codes courts: %v", err)
+		}
+
+		report, err := linkService.GetKeyspaceReport()
+		if err != nil {
+			fmt.Printf("Erreur: %v\n", err)
+			os.Exit(1)
+		}
+
+		if report.Length > 0 {
+			fmt.Printf("Générateur: %s (longueur actuelle: %d, maximum: %d)\n\n", cfg.Codes.Generator, report.Length, report.Policy.MaxLength)
+		} else {
+			fmt.Printf("Générateur: %s (espace de codes par longueur inconnu)\n\n", cfg.Codes.Generator)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "DOMAINE\tLONGUEUR\tCODES\tCAPACITÉ\tOCCUPATION\t")
+		var crowded []services.KeyspaceUsage
+		for _, usage := range report.Usage {
+			domain := usage.Domain
+			if domain == "" {
+				domain = "(défaut)"
+			}
+			capacity, occupancy := "-", "-"
+			if usage.Capacity > 0 {
+				capacity = fmt.Sprintf("%.4g", usage.Capacity)
+				occupancy = fmt.Sprintf("%.4f%%", usage.Occupancy()*100)
+			}
+			marker := ""
+			if usage.Length == report.Length {
+				marker = "← actuelle"
+				if usage.Occupancy() >= report.Policy.MaxCollisionRate/2 {
+					crowded = append(crowded, usage)
+				}
+			}
+			fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\t%s\n", domain, usage.Length, usage.Used, capacity, occupancy, marker)
+		}
+		w.Flush()
+
+		if report.Attempts > 0 {
+			fmt.Printf("\nCollisions enregistrées: %d sur %d codes générés (%.2f%%)\n",
+				report.Collisions, report.Attempts, float64(report.Collisions)/float64(report.Attempts)*100)
+		} else {
+			fmt.Printf("\nCollisions enregistrées: aucune mesure pour l'instant\n")
+		}
+
+		for _, usage := range crowded {
+			domain := usage.Domain
+			if domain == "" {
+				domain = "par défaut"
+			}
+			fmt.Printf("Attention: l'espace des codes de %d caractères du domaine %s est occupé à %.1f%% ; les codes s'allongeront au-delà de %.1f%%.\n",
+				usage.Length, domain, usage.Occupancy()*100, report.Policy.MaxCollisionRate*100)
+		}
+	},
+}
+
+// CheckCodeCmd représente la commande 'codes check'
+var CheckCodeCmd = &cobra.Command{
+	Use:   "check <code>",
+	Short: "Indique si un code court peut être choisi, et sinon pourquoi.",
+	Long: `Cette commande vérifie un code comme le ferait la création d'un lien avec cet alias :
+format, mots réservés, mots bloqués (y compris déguisés en leetspeak ou coupés par des
+séparateurs) et disponibilité sur le domaine. Le code peut être qualifié par son domaine
+(domaine/code). La commande se termine en erreur si le code est refusé.
+
+Exemple:
+  url-shortener codes check spring-sale
+  url-shortener codes check go.brand.example/promo`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := cmd2.Cfg
+		if cfg == nil {
+			log.Fatalf("FATAL: Configuration non chargée")
+		}
+
+		domain, code, err := parseCodeFlag(cfg.Server, args[0])
+		if err != nil {
+			fmt.Printf("Erreur: %v\n", err)
+			os.Exit(1)
+		}
+
+		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		if err != nil {
+			log.Fatalf("FATAL: Échec de la connexion à la base de données: %v", err)
+		}
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			log.Fatalf("FATAL: Échec de l'obtention de la base de données SQL sous-jacente: %v", err)
+		}
+		defer sqlDB.Close()
+
+		linkRepo := repository.NewLinkRepository(db)
+		linkService := services.NewLinkService(linkRepo)
+		if err := cmd2.SetupCodeGeneration(linkService, db); err != nil {
+			log.Fatalf("FATAL: Échec de la configuration de la génération des codes courts: %v", err)
+		}
+
+		err = linkService.CheckAlias(domain, code)
+		if err == nil {
+			fmt.Printf("Code %q accepté.\n", code)
+			return
+		}
+
+		switch rejection := linkService.CheckShortCode(code); {
+		case errors.Is(err, services.ErrInvalidAlias):
+			fmt.Printf("Code %q refusé: format invalide (%v).\n", code, err)
+		case rejection != nil && rejection.Reason == codegen.ReasonReserved:
+			fmt.Printf("Code %q refusé: c'est un mot réservé, utilisé par le service ou la configuration (codes.reserved).\n", code)
+		case rejection != nil:
+			fmt.Printf("Code %q refusé: il contient le mot bloqué %q, écrit %q.\n", code, rejection.Word, rejection.Match)
+		case errors.Is(err, services.ErrAliasTaken):
+			fmt.Printf("Code %q refusé: il est déjà utilisé sur ce domaine, par un lien existant ou supprimé, ou en quarantaine.\n", code)
+		default:
+			fmt.Printf("Erreur: %v\n", err)
+		}
+		os.Exit(1)
+	},
+}
+
+func init() {
+	CodesCmd.AddCommand(KeyspaceCmd)
+	CodesCmd.AddCommand(CheckCodeCmd)
+	cmd2.RootCmd.AddCommand(CodesCmd)
+}
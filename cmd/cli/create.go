@@ -1,12 +1,17 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/url" // Pour valider le format de l'URL
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/axellelanca/urlshortener/internal/repository"
 	"github.com/axellelanca/urlshortener/internal/services"
 	"github.com/spf13/cobra"
@@ -14,52 +19,344 @@ import (
 	"gorm.io/gorm"
 )
 
-// TODO : Faire une variable longURLFlag qui stockera la valeur du flag --url
+// longURLFlag stocke la valeur du flag --url
+var longURLFlag string
+
+// aliasFlag stocke la valeur du flag --alias (code court personnalisé optionnel)
+var aliasFlag string
+
+// shortDomainFlag stocke le domaine court de marque du lien (vide = domaine par défaut)
+var shortDomainFlag string
+
+// expiresAtFlag et maxClicksFlag stockent les limites de durée de vie optionnelles du lien
+var (
+	expiresAtFlag string
+	maxClicksFlag int
+)
+
+// notBeforeFlag et scheduleFlags stockent la date d'activation et les destinations programmées
+// (--schedule DEBUT/FIN=URL, répétable, bornes RFC 3339 optionnelles)
+var (
+	notBeforeFlag string
+	scheduleFlags []string
+)
+
+// reuseFlag demande de réutiliser un lien existant vers une destination équivalente
+var reuseFlag bool
+
+// rulesFileFlag stocke le chemin d'un fichier JSON de règles de redirection
+var rulesFileFlag string
+
+// passwordFlag stocke le mot de passe optionnel protégeant la redirection
+var passwordFlag string
+
+// iosURLFlag, androidURLFlag et desktopURLFlag stockent les destinations optionnelles par plateforme
+var (
+	iosURLFlag     string
+	androidURLFlag string
+	desktopURLFlag string
+)
+
+// countryURLsFlag stocke les destinations optionnelles par pays (--country FR=https://example.fr)
+var countryURLsFlag map[string]string
+
+// variantFlags et stickyVariantsFlag décrivent un test A/B (--variant NOM:POIDS:URL, répétable)
+var (
+	variantFlags       []string
+	stickyVariantsFlag bool
+)
+
+// redirectTypeFlag stocke le type de redirection du lien (301, 302, 307, 308 ou meta-refresh)
+var redirectTypeFlag string
+
+// forwardQueryFlag et les flags UTM contrôlent les paramètres ajoutés à la destination lors de la redirection
+var (
+	forwardQueryFlag bool
+	utmSourceFlag    string
+	utmMediumFlag    string
+	utmCampaignFlag  string
+)
 
 // CreateCmd représente la commande 'create'
 var CreateCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Crée une URL courte à partir d'une URL longue.",
 	Long: `Cette commande raccourcit une URL longue fournie et affiche le code court généré.
+Un alias personnalisé peut être demandé avec --alias à la place du code aléatoire.
+Avec --reuse, un lien existant vers une destination équivalente (même forme canonique : hôte
+en minuscules, port par défaut et slash final retirés, paramètres triés) et au comportement
+identique est retourné au lieu d'en créer un nouveau. Le lien redirige toujours vers l'URL saisie.
+Toutes les destinations du lien sont soumises à la politique de la section destinations
+de la configuration (schémas, domaines, adresses internes) ; un refus indique son code.
 
 Exemple:
-  url-shortener create --url="https://www.google.com/search?q=go+lang"`,
+  url-shortener create --url="https://www.google.com/search?q=go+lang"
+  url-shortener create --url="https://example.com/promo" --alias="spring-sale"
+  url-shortener create --url="https://Example.com:443/promo/" --reuse
+  url-shortener create --url="https://example.com/promo" --alias="spring-sale" --short-domain="go.brand.example"
+  url-shortener create --url="https://example.com/promo" --expires-at="2025-12-31T23:59:00Z" --max-clicks=100
+  url-shortener create --url="https://example.com" --country FR=https://example.fr --country DE=https://example.de
+  url-shortener create --url="https://example.com/launch" \
+    --schedule "/2025-06-01T09:00:00Z=https://example.com/soon" --schedule "2025-07-01T00:00:00Z/=https://example.com/archive"
+  url-shortener create --url="https://example.com" --variant a:70:https://example.com/v1 --variant b:30:https://example.com/v2
+  url-shortener create --url="https://example.com/promo" --forward-query --utm-source=newsletter --utm-medium=email`,
 	Run: func(cmd *cobra.Command, args []string) {
-		// TODO 1: Valider que le flag --url a été fourni.
+		// Valider que le flag --url a été fourni.
+		if longURLFlag == "" {
+			fmt.Println("Erreur: Le flag --url est requis")
+			os.Exit(1)
+		}
+
+		// Validation basique du format des URLs
+		// (les destinations par pays sont validées par le service)
+		for _, u := range []string{longURLFlag, iosURLFlag, androidURLFlag, desktopURLFlag} {
+			if u == "" {
+				continue
+			}
+			if _, err := url.ParseRequestURI(u); err != nil {
+				fmt.Printf("Erreur: URL invalide '%s': %v\n", u, err)
+				os.Exit(1)
+			}
+		}
+
+		// Date d'expiration optionnelle au format RFC 3339
+		var expiresAt *time.Time
+		if expiresAtFlag != "" {
+			t, err := time.Parse(time.RFC3339, expiresAtFlag)
+			if err != nil {
+				fmt.Printf("Erreur: Date d'expiration invalide '%s' (format attendu: RFC 3339, ex: 2025-12-31T23:59:00Z)\n", expiresAtFlag)
+				os.Exit(1)
+			}
+			expiresAt = &t
+		}
 
-		// TODO Validation basique du format de l'URL avec le package url et la fonction ParseRequestURI
-		// si erreur, os.Exit(1)
+		// Date d'activation optionnelle au format RFC 3339
+		var notBefore *time.Time
+		if notBeforeFlag != "" {
+			t, err := time.Parse(time.RFC3339, notBeforeFlag)
+			if err != nil {
+				fmt.Printf("Erreur: Date d'activation invalide '%s' (format attendu: RFC 3339, ex: 2025-06-01T09:00:00Z)\n", notBeforeFlag)
+				os.Exit(1)
+			}
+			notBefore = &t
+		}
+
+		// Destinations programmées au format DEBUT/FIN=URL
+		schedule, err := parseScheduleFlags(scheduleFlags)
+		if err != nil {
+			fmt.Printf("Erreur: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Règles de redirection lues depuis un fichier JSON
+		rules, err := readRulesFile(rulesFileFlag)
+		if err != nil {
+			fmt.Printf("Erreur: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Destinations pondérées au format NOM:POIDS:URL
+		variants, err := parseVariantFlags(variantFlags)
+		if err != nil {
+			fmt.Printf("Erreur: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Charger la configuration chargée globalement via cmd.Cfg
+		cfg := cmd2.Cfg
+		if cfg == nil {
+			log.Fatalf("FATAL: Configuration non chargée")
+		}
 
-		// TODO : Charger la configuration chargée globalement via cmd.cfg
+		// Domaine court de marque, qui doit figurer dans server.domains
+		domain, err := cfg.Server.NormalizeDomain(shortDomainFlag)
+		if err != nil {
+			fmt.Printf("Erreur: %v\n", err)
+			os.Exit(1)
+		}
 
-		// TODO : Initialiser la connexion à la base de données SQLite.
+		// Initialiser la connexion à la base de données SQLite.
+		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		if err != nil {
+			log.Fatalf("FATAL: Échec de la connexion à la base de données: %v", err)
+		}
 
 		sqlDB, err := db.DB()
 		if err != nil {
 			log.Fatalf("FATAL: Échec de l'obtention de la base de données SQL sous-jacente: %v", err)
 		}
 
-		// TODO S'assurer que la connexion est fermée à la fin de l'exécution de la commande
-		
-		// TODO : Initialiser les repositories et services nécessaires NewLinkRepository & NewLinkService
+		// S'assurer que la connexion est fermée à la fin de l'exécution de la commande
+		defer sqlDB.Close()
+
+		// Initialiser les repositories et services nécessaires
+		linkRepo := repository.NewLinkRepository(db)
+		linkService := services.NewLinkService(linkRepo)
+
+		if err := cmd2.SetupCodeGeneration(linkService, db); err != nil {
+			log.Fatalf("FATAL: Échec de la configuration de la génération des codes courts: %v", err)
+		}
+		if _, err := cmd2.SetupDestinationPolicy(linkService); err != nil {
+			log.Fatalf("FATAL: Échec de la configuration de la politique des destinations: %v", err)
+		}
+		if _, err := cmd2.SetupThreatFeeds(linkService); err != nil {
+			log.Fatalf("FATAL: Échec du chargement des flux de menaces: %v", err)
+		}
 
-		// TODO : Appeler le LinkService et la fonction CreateLink pour créer le lien court.
-		// os.Exit(1) si erreur
+		// Appeler le LinkService pour créer le lien court.
+		link, err := linkService.CreateLink(services.CreateLinkInput{
+			LongURL:   longURLFlag,
+			Alias:     aliasFlag,
+			Domain:    domain,
+			ExpiresAt: expiresAt,
+			MaxClicks: maxClicksFlag,
+			Password:  passwordFlag,
+			NotBefore: notBefore,
+
+			Rules:    rules,
+			Schedule: schedule,
+
+			IOSURL:     iosURLFlag,
+			AndroidURL: androidURLFlag,
+			DesktopURL: desktopURLFlag,
+
+			CountryURLs: countryURLsFlag,
+
+			Variants:       variants,
+			StickyVariants: stickyVariantsFlag,
+
+			RedirectType: models.RedirectType(redirectTypeFlag),
+			ForwardQuery: forwardQueryFlag,
+			UTM: models.UTMParams{
+				Source:   utmSourceFlag,
+				Medium:   utmMediumFlag,
+				Campaign: utmCampaignFlag,
+			},
+
+			ReuseExisting: reuseFlag,
+		})
+		if err != nil {
+			fmt.Printf("Erreur lors de la création du lien: %v\n", err)
+			os.Exit(1)
+		}
 
-		fullShortURL := fmt.Sprintf("%s/%s", cfg.Server.BaseURL, link.ShortCode)
-		fmt.Printf("URL courte créée avec succès:\n")
-		fmt.Printf("Code: %s\n", link.ShortCode)
+		fullShortURL := cfg.Server.ShortURL(link.Domain, link.ShortCode)
+		if link.Reused {
+			fmt.Printf("Lien existant réutilisé pour cette destination:\n")
+		} else {
+			fmt.Printf("URL courte créée avec succès:\n")
+		}
+		fmt.Printf("Code: %s\n", link.QualifiedCode())
 		fmt.Printf("URL complète: %s\n", fullShortURL)
+		fmt.Printf("Destination: %s\n", link.LongURL)
+		if link.ExpiresAt != nil {
+			fmt.Printf("Expire le: %s\n", link.ExpiresAt.Format(time.RFC3339))
+		}
+		if link.MaxClicks > 0 {
+			fmt.Printf("Nombre maximum de clics: %d\n", link.MaxClicks)
+		}
+		if link.IsProtected() {
+			fmt.Printf("Protégé par mot de passe: oui\n")
+		}
+		if link.RedirectType != "" {
+			fmt.Printf("Type de redirection: %s\n", link.RedirectType)
+		}
 	},
 }
 
+// parseScheduleFlags convertit les flags --schedule (DEBUT/FIN=URL) en destinations programmées.
+// Une borne vide laisse la plage ouverte de ce côté.
+func parseScheduleFlags(values []string) ([]models.ScheduledDestination, error) {
+	schedule := make([]models.ScheduledDestination, 0, len(values))
+	for _, value := range values {
+		interval, target, ok := strings.Cut(value, "=")
+		from, until, hasSlash := strings.Cut(interval, "/")
+		if !ok || !hasSlash {
+			return nil, fmt.Errorf("destination programmée invalide '%s' (format attendu: DEBUT/FIN=URL)", value)
+		}
+		entry := models.ScheduledDestination{URL: target}
+		for _, bound := range []struct {
+			value string
+			dest  **time.Time
+		}{{from, &entry.From}, {until, &entry.Until}} {
+			if bound.value == "" {
+				continue
+			}
+			t, err := time.Parse(time.RFC3339, bound.value)
+			if err != nil {
+				return nil, fmt.Errorf("date invalide '%s' dans '%s' (format attendu: RFC 3339)", bound.value, value)
+			}
+			*bound.dest = &t
+		}
+		schedule = append(schedule, entry)
+	}
+	return schedule, nil
+}
+
+// parseVariantFlags convertit les flags --variant (NOM:POIDS:URL) en destinations pondérées.
+// L'URL est la fin de la valeur et peut donc contenir des ':'.
+func parseVariantFlags(values []string) ([]models.SplitVariant, error) {
+	variants := make([]models.SplitVariant, 0, len(values))
+	for _, value := range values {
+		parts := strings.SplitN(value, ":", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("variante invalide '%s' (format attendu: NOM:POIDS:URL)", value)
+		}
+		weight, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("poids invalide pour la variante '%s': %s", parts[0], parts[1])
+		}
+		variants = append(variants, models.SplitVariant{Name: parts[0], Weight: weight, URL: parts[2]})
+	}
+	return variants, nil
+}
+
+// readRulesFile lit les règles de redirection d'un fichier JSON contenant une liste de règles
+// (même format que le champ "rules" de l'API). Un chemin vide ne retourne aucune règle.
+func readRulesFile(path string) ([]models.RedirectRule, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("impossible de lire le fichier de règles: %w", err)
+	}
+	var rules []models.RedirectRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("fichier de règles invalide '%s': %w", path, err)
+	}
+	return rules, nil
+}
+
 // init() s'exécute automatiquement lors de l'importation du package.
 // Il est utilisé pour définir les flags que cette commande accepte.
 func init() {
-	// TODO : Définir le flag --url pour la commande create.
-
-	// TODO :  Marquer le flag comme requis
+	// Définir les flags de la commande create.
+	CreateCmd.Flags().StringVar(&longURLFlag, "url", "", "URL longue à raccourcir (requis)")
+	CreateCmd.Flags().StringVar(&aliasFlag, "alias", "", "Code court personnalisé (lettres, chiffres, '-' et '_')")
+	CreateCmd.Flags().StringVar(&shortDomainFlag, "short-domain", "", "Domaine court de marque du lien, parmi server.domains (défaut: base_url)")
+	CreateCmd.Flags().StringVar(&expiresAtFlag, "expires-at", "", "Date d'expiration du lien au format RFC 3339")
+	CreateCmd.Flags().IntVar(&maxClicksFlag, "max-clicks", 0, "Nombre maximum de clics avant expiration (0 = illimité)")
+	CreateCmd.Flags().StringVar(&notBeforeFlag, "not-before", "", "Date d'activation du lien au format RFC 3339")
+	CreateCmd.Flags().StringArrayVar(&scheduleFlags, "schedule", nil, "Destination programmée au format DEBUT/FIN=URL (bornes RFC 3339, l'une peut être vide), répétable")
+	CreateCmd.Flags().StringVar(&rulesFileFlag, "rules-file", "", "Fichier JSON contenant la liste des règles de redirection du lien")
+	CreateCmd.Flags().StringVar(&passwordFlag, "password", "", "Mot de passe exigé avant la redirection")
+	CreateCmd.Flags().StringVar(&iosURLFlag, "ios-url", "", "Destination des visiteurs iOS (App Store par exemple)")
+	CreateCmd.Flags().StringVar(&androidURLFlag, "android-url", "", "Destination des visiteurs Android (Play Store par exemple)")
+	CreateCmd.Flags().StringVar(&desktopURLFlag, "desktop-url", "", "Destination des visiteurs sur ordinateur")
+	CreateCmd.Flags().StringToStringVar(&countryURLsFlag, "country", nil, "Destination par code pays, répétable (ex: --country FR=https://example.fr)")
+	CreateCmd.Flags().StringArrayVar(&variantFlags, "variant", nil, "Destination pondérée d'un test A/B au format NOM:POIDS:URL, répétable")
+	CreateCmd.Flags().BoolVar(&stickyVariantsFlag, "sticky-variants", false, "Conserve la même variante pour un visiteur (cookie)")
+	CreateCmd.Flags().StringVar(&redirectTypeFlag, "redirect-type", "", "Type de redirection : 301, 302, 307, 308 ou meta-refresh (défaut du serveur si vide)")
+	CreateCmd.Flags().BoolVar(&forwardQueryFlag, "forward-query", false, "Transmet la query string de l'URL courte à la destination")
+	CreateCmd.Flags().StringVar(&utmSourceFlag, "utm-source", "", "utm_source ajouté à la destination")
+	CreateCmd.Flags().StringVar(&utmMediumFlag, "utm-medium", "", "utm_medium ajouté à la destination")
+	CreateCmd.Flags().StringVar(&utmCampaignFlag, "utm-campaign", "", "utm_campaign ajouté à la destination")
+	CreateCmd.Flags().BoolVar(&reuseFlag, "reuse", false, "Réutilise un lien existant vers une destination équivalente au lieu d'en créer un nouveau")
 
-	// TODO : Ajouter la commande à RootCmd
+	// Marquer le flag comme requis
+	CreateCmd.MarkFlagRequired("url")
 
+	// Ajouter la commande à RootCmd
+	cmd2.RootCmd.AddCommand(CreateCmd)
 }
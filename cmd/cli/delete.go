@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+	"gorm.io/driver/sqlite" // Driver SQLite pour GORM
+	"gorm.io/gorm"
+)
+
+// deleteCodeFlag stocke la valeur du flag --code de la commande 'delete'
+var deleteCodeFlag string
+
+// DeleteCmd représente la commande 'delete'
+var DeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Place une URL courte à la corbeille.",
+	Long: `Cette commande supprime un lien (soft delete) : la redirection répond 410 Gone
+mais l'historique des clics est conservé. Le lien peut être restauré avec 'restore'
+jusqu'à sa purge définitive, après la durée de rétention configurée (trash.retention_days).
+
+Exemple:
+  url-shortener delete --code="xyz123"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if deleteCodeFlag == "" {
+			fmt.Println("Erreur: Le flag --code est requis")
+			os.Exit(1)
+		}
+
+		cfg := cmd2.Cfg
+		if cfg == nil {
+			log.Fatalf("FATAL: Configuration non chargée")
+		}
+
+		domain, shortCode, err := parseCodeFlag(cfg.Server, deleteCodeFlag)
+		if err != nil {
+			fmt.Printf("Erreur: %v\n", err)
+			os.Exit(1)
+		}
+
+		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		if err != nil {
+			log.Fatalf("FATAL: Échec de la connexion à la base de données: %v", err)
+		}
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			log.Fatalf("FATAL: Échec de l'obtention de la base de données SQL sous-jacente: %v", err)
+		}
+		defer sqlDB.Close()
+
+		linkRepo := repository.NewLinkRepository(db)
+		linkService := services.NewLinkService(linkRepo)
+
+		link, err := linkService.DeleteLink(domain, shortCode)
+		if err != nil {
+			printLinkError(deleteCodeFlag, err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Lien %s placé à la corbeille.\n", link.QualifiedCode())
+	},
+}
+
+func init() {
+	DeleteCmd.Flags().StringVar(&deleteCodeFlag, "code", "", "Code court du lien, ou domaine/code pour un domaine de marque (requis)")
+	DeleteCmd.MarkFlagRequired("code")
+
+	cmd2.RootCmd.AddCommand(DeleteCmd)
+}
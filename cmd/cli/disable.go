@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+	"gorm.io/driver/sqlite" // Driver SQLite pour GORM
+	"gorm.io/gorm"
+)
+
+// disableCodeFlag stocke la valeur du flag --code de la commande 'disable'
+var disableCodeFlag string
+
+// DisableCmd représente la commande 'disable'
+var DisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Désactive une URL courte sans la supprimer.",
+	Long: `Cette commande désactive un lien : la redirection répond 410 Gone, mais le lien
+et ses statistiques restent consultables. Utilisez 'restore' pour le réactiver.
+
+Exemple:
+  url-shortener disable --code="xyz123"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if disableCodeFlag == "" {
+			fmt.Println("Erreur: Le flag --code est requis")
+			os.Exit(1)
+		}
+
+		cfg := cmd2.Cfg
+		if cfg == nil {
+			log.Fatalf("FATAL: Configuration non chargée")
+		}
+
+		domain, shortCode, err := parseCodeFlag(cfg.Server, disableCodeFlag)
+		if err != nil {
+			fmt.Printf("Erreur: %v\n", err)
+			os.Exit(1)
+		}
+
+		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		if err != nil {
+			log.Fatalf("FATAL: Échec de la connexion à la base de données: %v", err)
+		}
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			log.Fatalf("FATAL: Échec de l'obtention de la base de données SQL sous-jacente: %v", err)
+		}
+		defer sqlDB.Close()
+
+		linkRepo := repository.NewLinkRepository(db)
+		linkService := services.NewLinkService(linkRepo)
+
+		link, err := linkService.DisableLink(domain, shortCode)
+		if err != nil {
+			printLinkError(disableCodeFlag, err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Lien %s désactivé.\n", link.QualifiedCode())
+	},
+}
+
+func init() {
+	DisableCmd.Flags().StringVar(&disableCodeFlag, "code", "", "Code court du lien, ou domaine/code pour un domaine de marque (requis)")
+	DisableCmd.MarkFlagRequired("code")
+
+	cmd2.RootCmd.AddCommand(DisableCmd)
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+	"gorm.io/driver/sqlite" // Driver SQLite pour GORM
+	"gorm.io/gorm"
+)
+
+// duplicatesLimitFlag borne le nombre de destinations affichées (0 = toutes)
+var duplicatesLimitFlag int
+
+// DuplicatesCmd représente la commande 'duplicates'
+var DuplicatesCmd = &cobra.Command{
+	Use:   "duplicates",
+	Short: "Liste les liens qui pointent vers une même destination.",
+	Long: `Cette commande regroupe les liens (hors corbeille, tous domaines confondus) dont la destination
+canonique est identique, les destinations les plus partagées d'abord. La comparaison se faisant
+sur la forme canonique des URL, deux saisies qui ne diffèrent que par la casse de l'hôte, le port par défaut,
+le slash final ou l'ordre des paramètres tombent dans le même groupe.
+
+Exemple:
+  url-shortener duplicates
+  url-shortener duplicates --limit 10`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if duplicatesLimitFlag < 0 {
+			fmt.Println("Erreur: --limit doit être positif")
+			os.Exit(1)
+		}
+
+		cfg := cmd2.Cfg
+		if cfg == nil {
+			log.Fatalf("FATAL: Configuration non chargée")
+		}
+
+		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		if err != nil {
+			log.Fatalf("FATAL: Échec de la connexion à la base de données: %v", err)
+		}
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			log.Fatalf("FATAL: Échec de l'obtention de la base de données SQL sous-jacente: %v", err)
+		}
+		defer sqlDB.Close()
+
+		linkRepo := repository.NewLinkRepository(db)
+		linkService := services.NewLinkService(linkRepo)
+
+		groups, err := linkService.FindDuplicates(duplicatesLimitFlag)
+		if err != nil {
+			fmt.Printf("Erreur: %v\n", err)
+			os.Exit(1)
+		}
+		if len(groups) == 0 {
+			fmt.Println("Aucune destination partagée par plusieurs liens.")
+			return
+		}
+
+		extra := 0
+		for i, group := range groups {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("%s (%d liens)\n", group.CanonicalURL, len(group.Links))
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			for _, link := range group.Links {
+				fmt.Fprintf(w, "  %s\t%s\t%s\n", link.QualifiedCode(), link.CreatedAt.Format(time.DateTime), link.LongURL)
+			}
+			w.Flush()
+			extra += len(group.Links) - 1
+		}
+
+		fmt.Printf("\n%d destination(s) partagée(s), %d lien(s) en double.\n", len(groups), extra)
+	},
+}
+
+func init() {
+	DuplicatesCmd.Flags().IntVar(&duplicatesLimitFlag, "limit", 0, "Nombre maximum de destinations affichées (0 = toutes)")
+	cmd2.RootCmd.AddCommand(DuplicatesCmd)
+}
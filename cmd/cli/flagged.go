@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+	"gorm.io/driver/sqlite" // Driver SQLite pour GORM
+	"gorm.io/gorm"
+)
+
+// flaggedRescanFlag revérifie les liens dans les flux configurés avant de lister les signalements
+var flaggedRescanFlag bool
+
+// FlaggedCmd représente la commande 'flagged'
+var FlaggedCmd = &cobra.Command{
+	Use:   "flagged",
+	Short: "Liste les liens signalés par un flux de menaces.",
+	Long: `Cette commande liste les liens dont une destination figure dans un flux de menaces
+(section threats de la configuration), les signalements les plus récents d'abord. Un lien signalé
+affiche une page d'avertissement au lieu de rediriger.
+
+Les signalements sont mis à jour par run-server au démarrage et après chaque rechargement des flux.
+Avec --rescan, les liens sont revérifiés dans les flux configurés avant l'affichage.
+
+Exemple:
+  url-shortener flagged
+  url-shortener flagged --rescan`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := cmd2.Cfg
+		if cfg == nil {
+			log.Fatalf("FATAL: Configuration non chargée")
+		}
+
+		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		if err != nil {
+			log.Fatalf("FATAL: Échec de la connexion à la base de données: %v", err)
+		}
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			log.Fatalf("FATAL: Échec de l'obtention de la base de données SQL sous-jacente: %v", err)
+		}
+		defer sqlDB.Close()
+
+		linkRepo := repository.NewLinkRepository(db)
+		linkService := services.NewLinkService(linkRepo)
+
+		if flaggedRescanFlag {
+			checker, err := cmd2.SetupThreatFeeds(linkService)
+			if err != nil {
+				log.Fatalf("FATAL: Échec du chargement des flux de menaces: %v", err)
+			}
+			if checker == nil {
+				fmt.Println("Erreur: aucun flux de menaces configuré (section threats.feeds)")
+				os.Exit(1)
+			}
+			result, err := linkService.RescanThreats()
+			if err != nil {
+				fmt.Printf("Erreur: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("%d lien(s) vérifié(s) : %d signalé(s), %d signalement(s) levé(s).\n\n", result.Scanned, result.Flagged, result.Cleared)
+		}
+
+		links, err := linkService.ListFlaggedLinks()
+		if err != nil {
+			fmt.Printf("Erreur: %v\n", err)
+			os.Exit(1)
+		}
+		if len(links) == 0 {
+			fmt.Println("Aucun lien signalé.")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "CODE\tSIGNALÉ LE\tFLUX\tENTRÉE\tDESTINATION")
+		for _, link := range links {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", link.QualifiedCode(), link.FlaggedAt.Local().Format(time.DateTime), link.ThreatFeed, link.ThreatEntry, link.LongURL)
+		}
+		w.Flush()
+
+		fmt.Printf("\n%d lien(s) signalé(s).\n", len(links))
+	},
+}
+
+func init() {
+	FlaggedCmd.Flags().BoolVar(&flaggedRescanFlag, "rescan", false, "Revérifie les liens dans les flux configurés avant l'affichage")
+	cmd2.RootCmd.AddCommand(FlaggedCmd)
+}
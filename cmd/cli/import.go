@@ -0,0 +1,310 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+	"gorm.io/driver/sqlite" // Driver SQLite pour GORM
+	"gorm.io/gorm"
+)
+
+// Flags de la commande import
+var (
+	importFileFlag        string
+	importFormatFlag      string
+	importShortDomainFlag string
+	importReuseFlag       bool
+)
+
+// importRecord est une ligne du fichier importé, avant validation.
+type importRecord struct {
+	Line      int        `json:"-"` // Numéro de ligne dans le fichier, pour les messages d'erreur
+	LongURL   string     `json:"long_url"`
+	Code      string     `json:"code"`       // Code court personnalisé optionnel
+	ExpiresAt *time.Time `json:"expires_at"` // Date d'expiration optionnelle (RFC 3339)
+	MaxClicks int        `json:"max_clicks"` // Budget de clics optionnel
+}
+
+// ImportCmd représente la commande 'import'
+var ImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Crée des URLs courtes en masse à partir d'un fichier CSV ou NDJSON.",
+	Long: `Cette commande lit un fichier CSV ou NDJSON et crée un lien court par ligne.
+Les liens sont insérés par paquets transactionnels ; une ligne invalide est signalée
+sans bloquer les autres.
+
+CSV : une ligne d'en-tête optionnelle nomme les colonnes long_url (ou url), code,
+expires_at et max_clicks. Sans en-tête, la première colonne est l'URL et la seconde,
+optionnelle, le code personnalisé.
+NDJSON : un objet JSON par ligne avec les clés long_url, code, expires_at et max_clicks.
+
+Exemple:
+  url-shortener import --file=campagne.csv
+  url-shortener import --file=liens.txt --format=ndjson
+  url-shortener import --file=campagne.csv --short-domain=go.brand.example`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if importFileFlag == "" {
+			fmt.Println("Erreur: Le flag --file est requis")
+			os.Exit(1)
+		}
+
+		format := importFormatFlag
+		if format == "" {
+			format = formatFromExtension(importFileFlag)
+		}
+
+		file, err := os.Open(importFileFlag)
+		if err != nil {
+			fmt.Printf("Erreur: Impossible d'ouvrir le fichier: %v\n", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+
+		var records []importRecord
+		switch format {
+		case "csv":
+			records, err = readCSVRecords(file)
+		case "ndjson":
+			records, err = readNDJSONRecords(file)
+		default:
+			fmt.Printf("Erreur: Format inconnu '%s' (attendu: csv ou ndjson)\n", format)
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Printf("Erreur: Lecture du fichier impossible: %v\n", err)
+			os.Exit(1)
+		}
+
+		cfg := cmd2.Cfg
+		if cfg == nil {
+			log.Fatalf("FATAL: Configuration non chargée")
+		}
+
+		domain, err := cfg.Server.NormalizeDomain(importShortDomainFlag)
+		if err != nil {
+			fmt.Printf("Erreur: %v\n", err)
+			os.Exit(1)
+		}
+
+		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		if err != nil {
+			log.Fatalf("FATAL: Échec de la connexion à la base de données: %v", err)
+		}
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			log.Fatalf("FATAL: Échec de l'obtention de la base de données SQL sous-jacente: %v", err)
+		}
+		defer sqlDB.Close()
+
+		linkRepo := repository.NewLinkRepository(db)
+		linkService := services.NewLinkService(linkRepo)
+
+		if err := cmd2.SetupCodeGeneration(linkService, db); err != nil {
+			log.Fatalf("FATAL: Échec de la configuration de la génération des codes courts: %v", err)
+		}
+		if _, err := cmd2.SetupDestinationPolicy(linkService); err != nil {
+			log.Fatalf("FATAL: Échec de la configuration de la politique des destinations: %v", err)
+		}
+		if _, err := cmd2.SetupThreatFeeds(linkService); err != nil {
+			log.Fatalf("FATAL: Échec du chargement des flux de menaces: %v", err)
+		}
+
+		// Les lignes dont l'URL est invalide sont signalées sans être envoyées au service.
+		var valid []importRecord
+		failed := 0
+		for _, record := range records {
+			if _, err := url.ParseRequestURI(record.LongURL); err != nil {
+				fmt.Printf("Ligne %d: URL invalide '%s'\n", record.Line, record.LongURL)
+				failed++
+				continue
+			}
+			valid = append(valid, record)
+		}
+
+		created, reused := 0, 0
+		for start := 0; start < len(valid); start += services.MaxBatchSize {
+			end := min(start+services.MaxBatchSize, len(valid))
+			batch := valid[start:end]
+
+			inputs := make([]services.CreateLinkInput, len(batch))
+			for i, record := range batch {
+				inputs[i] = services.CreateLinkInput{
+					LongURL:   record.LongURL,
+					Alias:     record.Code,
+					Domain:    domain,
+					ExpiresAt: record.ExpiresAt,
+					MaxClicks: record.MaxClicks,
+
+					ReuseExisting: importReuseFlag,
+				}
+			}
+
+			results, err := linkService.CreateLinks(inputs)
+			if err != nil {
+				log.Fatalf("FATAL: Échec de l'import: %v", err)
+			}
+			for i, result := range results {
+				if result.Err != nil {
+					fmt.Printf("Ligne %d: %v\n", batch[i].Line, result.Err)
+					failed++
+					continue
+				}
+				fmt.Printf("%s\t%s\n", cfg.Server.ShortURL(result.Link.Domain, result.Link.ShortCode), result.Link.LongURL)
+				if result.Link.Reused {
+					reused++
+					continue
+				}
+				created++
+			}
+		}
+
+		fmt.Printf("Import terminé: %d lien(s) créé(s), %d réutilisé(s), %d échec(s).\n", created, reused, failed)
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// formatFromExtension déduit le format d'import de l'extension du fichier (CSV par défaut).
+func formatFromExtension(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		return "ndjson"
+	default:
+		return "csv"
+	}
+}
+
+// readCSVRecords lit un fichier CSV, avec ou sans ligne d'en-tête.
+func readCSVRecords(r io.Reader) ([]importRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // Les colonnes optionnelles peuvent être absentes
+	reader.TrimLeadingSpace = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	// Colonnes par défaut sans en-tête : URL puis code optionnel.
+	columns := map[string]int{"long_url": 0, "code": 1, "expires_at": -1, "max_clicks": -1}
+	first := 0
+	if isCSVHeader(rows[0]) {
+		columns = map[string]int{"long_url": -1, "code": -1, "expires_at": -1, "max_clicks": -1}
+		for i, name := range rows[0] {
+			switch strings.ToLower(strings.TrimSpace(name)) {
+			case "long_url", "url":
+				columns["long_url"] = i
+			case "code", "alias", "short_code":
+				columns["code"] = i
+			case "expires_at":
+				columns["expires_at"] = i
+			case "max_clicks":
+				columns["max_clicks"] = i
+			}
+		}
+		if columns["long_url"] < 0 {
+			return nil, errors.New("missing long_url column in CSV header")
+		}
+		first = 1
+	}
+
+	field := func(row []string, name string) string {
+		i := columns[name]
+		if i < 0 || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	var records []importRecord
+	for n, row := range rows[first:] {
+		record := importRecord{
+			Line:    first + n + 1,
+			LongURL: field(row, "long_url"),
+			Code:    field(row, "code"),
+		}
+		if record.LongURL == "" {
+			continue // Ligne vide
+		}
+		if value := field(row, "expires_at"); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid expires_at '%s'", record.Line, value)
+			}
+			record.ExpiresAt = &t
+		}
+		if value := field(row, "max_clicks"); value != "" {
+			maxClicks, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid max_clicks '%s'", record.Line, value)
+			}
+			record.MaxClicks = maxClicks
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// isCSVHeader indique si la première ligne d'un CSV est un en-tête de colonnes.
+func isCSVHeader(row []string) bool {
+	for _, name := range row {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "long_url", "url":
+			return true
+		}
+	}
+	return false
+}
+
+// readNDJSONRecords lit un fichier NDJSON (un objet JSON par ligne).
+func readNDJSONRecords(r io.Reader) ([]importRecord, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var records []importRecord
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var record importRecord
+		if err := json.Unmarshal([]byte(text), &record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		record.Line = line
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+func init() {
+	ImportCmd.Flags().StringVar(&importFileFlag, "file", "", "Fichier CSV ou NDJSON à importer (requis)")
+	ImportCmd.Flags().StringVar(&importFormatFlag, "format", "", "Format du fichier: csv ou ndjson (déduit de l'extension par défaut)")
+	ImportCmd.Flags().StringVar(&importShortDomainFlag, "short-domain", "", "Domaine court de marque des liens importés, parmi server.domains (défaut: base_url)")
+	ImportCmd.Flags().BoolVar(&importReuseFlag, "reuse", false, "Réutilise les liens existants vers une destination équivalente au lieu d'en créer de nouveaux")
+	ImportCmd.MarkFlagRequired("file")
+
+	cmd2.RootCmd.AddCommand(ImportCmd)
+}
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+	"gorm.io/driver/sqlite" // Driver SQLite pour GORM
+	"gorm.io/gorm"
+)
+
+// Flags de la commande list
+var (
+	listFromFlag   string
+	listToFlag     string
+	listDomainFlag string
+	listShortFlag  string
+	listSearchFlag string
+	listSortFlag   string
+	listAscFlag    bool
+	listLimitFlag  int
+	listCursorFlag string
+)
+
+// ListCmd représente la commande 'list'
+var ListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Liste les URLs courtes avec filtres et pagination.",
+	Long: `Cette commande affiche les liens existants, du plus récent au plus ancien par défaut.
+Les résultats peuvent être filtrés par date de création, domaine de destination ou texte,
+et triés par date de création ou nombre de clics. Quand d'autres résultats existent,
+le curseur à passer à --cursor pour obtenir la page suivante est affiché.
+
+Exemple:
+  url-shortener list --domain=example.com --from=2024-01-01 --to=2024-01-31
+  url-shortener list --sort=clicks --limit=10
+  url-shortener list --short-domain=go.brand.example`,
+	Run: func(cmd *cobra.Command, args []string) {
+		params := services.ListLinksParams{
+			Domain:    listDomainFlag,
+			Search:    listSearchFlag,
+			SortBy:    listSortFlag,
+			Ascending: listAscFlag,
+			Limit:     listLimitFlag,
+			Cursor:    listCursorFlag,
+		}
+
+		var err error
+		if params.CreatedFrom, err = services.ParseDateFilter(listFromFlag, false); err != nil {
+			fmt.Printf("Erreur: %v\n", err)
+			os.Exit(1)
+		}
+		if params.CreatedTo, err = services.ParseDateFilter(listToFlag, true); err != nil {
+			fmt.Printf("Erreur: %v\n", err)
+			os.Exit(1)
+		}
+
+		cfg := cmd2.Cfg
+		if cfg == nil {
+			log.Fatalf("FATAL: Configuration non chargée")
+		}
+
+		if cmd.Flags().Changed("short-domain") {
+			shortDomain, err := cfg.Server.NormalizeDomain(listShortFlag)
+			if err != nil {
+				fmt.Printf("Erreur: %v\n", err)
+				os.Exit(1)
+			}
+			params.ShortDomain = &shortDomain
+		}
+
+		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		if err != nil {
+			log.Fatalf("FATAL: Échec de la connexion à la base de données: %v", err)
+		}
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			log.Fatalf("FATAL: Échec de l'obtention de la base de données SQL sous-jacente: %v", err)
+		}
+		defer sqlDB.Close()
+
+		linkRepo := repository.NewLinkRepository(db)
+		linkService := services.NewLinkService(linkRepo)
+
+		page, err := linkService.ListLinks(params)
+		if err != nil {
+			fmt.Printf("Erreur: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(page.Links) == 0 {
+			fmt.Println("Aucun lien trouvé.")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "CODE\tCRÉÉ LE\tCLICS\tURL LONGUE")
+		for _, link := range page.Links {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", link.QualifiedCode(), link.CreatedAt.Format(time.DateTime), link.ClickCount, link.LongURL)
+		}
+		w.Flush()
+
+		if page.NextCursor != "" {
+			fmt.Printf("\nPage suivante: --cursor=%s\n", page.NextCursor)
+		}
+	},
+}
+
+func init() {
+	ListCmd.Flags().StringVar(&listFromFlag, "from", "", "Date de création minimale (RFC 3339 ou AAAA-MM-JJ)")
+	ListCmd.Flags().StringVar(&listToFlag, "to", "", "Date de création maximale, incluse (RFC 3339 ou AAAA-MM-JJ)")
+	ListCmd.Flags().StringVar(&listDomainFlag, "domain", "", "Domaine de destination (sous-domaines inclus)")
+	ListCmd.Flags().StringVar(&listShortFlag, "short-domain", "", "Domaine court de marque des liens (vide = domaine par défaut)")
+	ListCmd.Flags().StringVar(&listSearchFlag, "search", "", "Texte recherché dans l'URL longue ou le code court")
+	ListCmd.Flags().StringVar(&listSortFlag, "sort", "created_at", "Critère de tri: created_at ou clicks")
+	ListCmd.Flags().BoolVar(&listAscFlag, "asc", false, "Trie par ordre croissant")
+	ListCmd.Flags().IntVar(&listLimitFlag, "limit", services.DefaultListLimit, "Nombre de liens par page (100 max)")
+	ListCmd.Flags().StringVar(&listCursorFlag, "cursor", "", "Curseur de la page suivante")
+
+	cmd2.RootCmd.AddCommand(ListCmd)
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/qr"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+	"gorm.io/driver/sqlite" // Driver SQLite pour GORM
+	"gorm.io/gorm"
+)
+
+// Flags de la commande 'qr'
+var (
+	qrCodeFlag       string
+	qrOutputFlag     string
+	qrFormatFlag     string
+	qrSizeFlag       int
+	qrLevelFlag      string
+	qrMarginFlag     int
+	qrForegroundFlag string
+	qrBackgroundFlag string
+)
+
+// QRCmd représente la commande 'qr'
+var QRCmd = &cobra.Command{
+	Use:   "qr",
+	Short: "Génère le QR code d'une URL courte dans un fichier PNG ou SVG.",
+	Long: `Cette commande écrit dans un fichier le QR code de l'URL courte complète d'un lien
+(URL de base du serveur + code). Le format est déduit de l'extension du fichier
+si --format n'est pas précisé.
+
+Exemples:
+  url-shortener qr --code="xyz123" --output=xyz123.png
+  url-shortener qr --code="xyz123" --output=xyz123.svg --level=H --margin=2 --fg="#1a1a1a"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if qrCodeFlag == "" || qrOutputFlag == "" {
+			fmt.Println("Erreur: Les flags --code et --output sont requis")
+			os.Exit(1)
+		}
+
+		opts := qr.Options{Size: qrSizeFlag, Level: qrLevelFlag, Margin: qrMarginFlag}
+		format := qrFormatFlag
+		if format == "" {
+			format = strings.TrimPrefix(filepath.Ext(qrOutputFlag), ".")
+		}
+		var err error
+		if opts.Format, err = qr.ParseFormat(format); err != nil {
+			fmt.Printf("Erreur: %v\n", err)
+			os.Exit(1)
+		}
+		if opts.Foreground, err = qr.ParseColor(qrForegroundFlag); err != nil {
+			fmt.Printf("Erreur: %v\n", err)
+			os.Exit(1)
+		}
+		if opts.Background, err = qr.ParseColor(qrBackgroundFlag); err != nil {
+			fmt.Printf("Erreur: %v\n", err)
+			os.Exit(1)
+		}
+
+		cfg := cmd2.Cfg
+		if cfg == nil {
+			log.Fatalf("FATAL: Configuration non chargée")
+		}
+
+		domain, shortCode, err := parseCodeFlag(cfg.Server, qrCodeFlag)
+		if err != nil {
+			fmt.Printf("Erreur: %v\n", err)
+			os.Exit(1)
+		}
+
+		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		if err != nil {
+			log.Fatalf("FATAL: Échec de la connexion à la base de données: %v", err)
+		}
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			log.Fatalf("FATAL: Échec de l'obtention de la base de données SQL sous-jacente: %v", err)
+		}
+		defer sqlDB.Close()
+
+		linkRepo := repository.NewLinkRepository(db)
+		linkService := services.NewLinkService(linkRepo)
+
+		link, err := linkService.GetLinkByShortCode(domain, shortCode)
+		if err != nil {
+			printLinkError(qrCodeFlag, err)
+			os.Exit(1)
+		}
+
+		// L'image est générée en mémoire pour ne pas laisser de fichier partiel en cas d'erreur.
+		fullShortURL := cfg.Server.ShortURL(link.Domain, link.ShortCode)
+		var image bytes.Buffer
+		if err := qr.Write(&image, fullShortURL, opts); err != nil {
+			fmt.Printf("Erreur: %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(qrOutputFlag, image.Bytes(), 0o644); err != nil {
+			fmt.Printf("Erreur: impossible d'écrire %s: %v\n", qrOutputFlag, err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("QR code de %s écrit dans %s (%s, %d px)\n", fullShortURL, qrOutputFlag, opts.Format, opts.Size)
+	},
+}
+
+func init() {
+	defaults := qr.DefaultOptions()
+
+	QRCmd.Flags().StringVar(&qrCodeFlag, "code", "", "Code court du lien, ou domaine/code pour un domaine de marque (requis)")
+	QRCmd.Flags().StringVarP(&qrOutputFlag, "output", "o", "", "Fichier de sortie (requis)")
+	QRCmd.Flags().StringVar(&qrFormatFlag, "format", "", "Format de l'image : png ou svg (déduit de l'extension par défaut)")
+	QRCmd.Flags().IntVar(&qrSizeFlag, "size", defaults.Size, "Largeur et hauteur de l'image en pixels")
+	QRCmd.Flags().StringVar(&qrLevelFlag, "level", defaults.Level, "Niveau de correction d'erreur : L, M, Q ou H")
+	QRCmd.Flags().IntVar(&qrMarginFlag, "margin", defaults.Margin, "Marge autour du code, en modules")
+	QRCmd.Flags().StringVar(&qrForegroundFlag, "fg", "#000000", "Couleur des modules (#RGB, #RRGGBB ou #RRGGBBAA)")
+	QRCmd.Flags().StringVar(&qrBackgroundFlag, "bg", "#ffffff", "Couleur du fond (#RGB, #RRGGBB ou #RRGGBBAA)")
+	QRCmd.MarkFlagRequired("code")
+	QRCmd.MarkFlagRequired("output")
+
+	cmd2.RootCmd.AddCommand(QRCmd)
+}
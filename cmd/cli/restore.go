@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+	"gorm.io/driver/sqlite" // Driver SQLite pour GORM
+	"gorm.io/gorm"
+)
+
+// restoreCodeFlag stocke la valeur du flag --code de la commande 'restore'
+var restoreCodeFlag string
+
+// RestoreCmd représente la commande 'restore'
+var RestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restaure une URL courte supprimée ou désactivée.",
+	Long: `Cette commande sort un lien de la corbeille et le réactive s'il était désactivé.
+Un lien déjà purgé définitivement ne peut plus être restauré.
+
+Exemple:
+  url-shortener restore --code="xyz123"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if restoreCodeFlag == "" {
+			fmt.Println("Erreur: Le flag --code est requis")
+			os.Exit(1)
+		}
+
+		cfg := cmd2.Cfg
+		if cfg == nil {
+			log.Fatalf("FATAL: Configuration non chargée")
+		}
+
+		domain, shortCode, err := parseCodeFlag(cfg.Server, restoreCodeFlag)
+		if err != nil {
+			fmt.Printf("Erreur: %v\n", err)
+			os.Exit(1)
+		}
+
+		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		if err != nil {
+			log.Fatalf("FATAL: Échec de la connexion à la base de données: %v", err)
+		}
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			log.Fatalf("FATAL: Échec de l'obtention de la base de données SQL sous-jacente: %v", err)
+		}
+		defer sqlDB.Close()
+
+		linkRepo := repository.NewLinkRepository(db)
+		linkService := services.NewLinkService(linkRepo)
+
+		link, err := linkService.RestoreLink(domain, shortCode)
+		if err != nil {
+			printLinkError(restoreCodeFlag, err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Lien %s restauré.\n", link.QualifiedCode())
+	},
+}
+
+func init() {
+	RestoreCmd.Flags().StringVar(&restoreCodeFlag, "code", "", "Code court du lien, ou domaine/code pour un domaine de marque (requis)")
+	RestoreCmd.MarkFlagRequired("code")
+
+	cmd2.RootCmd.AddCommand(RestoreCmd)
+}
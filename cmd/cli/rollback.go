@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+	"gorm.io/driver/sqlite" // Driver SQLite pour GORM
+	"gorm.io/gorm"
+)
+
+// Flags de la commande rollback
+var (
+	rollbackCodeFlag     string
+	rollbackRevisionFlag uint
+	rollbackListFlag     bool
+)
+
+// RollbackCmd représente la commande 'rollback'
+var RollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Annule une modification de destination d'une URL courte.",
+	Long: `Cette commande restaure la destination qui précédait une révision.
+Sans --revision, la dernière modification est annulée. Le retour arrière est lui-même
+enregistré dans l'historique. --list affiche l'historique sans rien modifier.
+
+Exemple:
+  url-shortener rollback --code="spring-sale" --list
+  url-shortener rollback --code="spring-sale" --revision=3`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if rollbackCodeFlag == "" {
+			fmt.Println("Erreur: Le flag --code est requis")
+			os.Exit(1)
+		}
+
+		cfg := cmd2.Cfg
+		if cfg == nil {
+			log.Fatalf("FATAL: Configuration non chargée")
+		}
+
+		domain, shortCode, err := parseCodeFlag(cfg.Server, rollbackCodeFlag)
+		if err != nil {
+			fmt.Printf("Erreur: %v\n", err)
+			os.Exit(1)
+		}
+
+		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		if err != nil {
+			log.Fatalf("FATAL: Échec de la connexion à la base de données: %v", err)
+		}
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			log.Fatalf("FATAL: Échec de l'obtention de la base de données SQL sous-jacente: %v", err)
+		}
+		defer sqlDB.Close()
+
+		linkRepo := repository.NewLinkRepository(db)
+		linkService := services.NewLinkService(linkRepo)
+		if _, err := cmd2.SetupDestinationPolicy(linkService); err != nil {
+			log.Fatalf("FATAL: Échec de la configuration de la politique des destinations: %v", err)
+		}
+		if _, err := cmd2.SetupThreatFeeds(linkService); err != nil {
+			log.Fatalf("FATAL: Échec du chargement des flux de menaces: %v", err)
+		}
+
+		if rollbackListFlag {
+			link, revisions, err := linkService.GetLinkRevisions(domain, shortCode)
+			if err != nil {
+				printLinkError(rollbackCodeFlag, err)
+				os.Exit(1)
+			}
+			fmt.Printf("Historique de %s (destination actuelle: %s)\n", link.QualifiedCode(), link.LongURL)
+			if len(revisions) == 0 {
+				fmt.Println("Aucune révision.")
+				return
+			}
+			for _, revision := range revisions {
+				fmt.Printf("#%d  %s  par %s\n     %s -> %s\n",
+					revision.ID, revision.CreatedAt.Format(time.RFC3339), revision.ChangedBy, revision.OldURL, revision.NewURL)
+			}
+			return
+		}
+
+		link, err := linkService.RollbackLink(domain, shortCode, rollbackRevisionFlag, actorOrCurrentUser(changedByFlag))
+		if err != nil {
+			printLinkError(rollbackCodeFlag, err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Destination de %s restaurée: %s\n", link.QualifiedCode(), link.LongURL)
+	},
+}
+
+func init() {
+	RollbackCmd.Flags().StringVar(&rollbackCodeFlag, "code", "", "Code court du lien, ou domaine/code pour un domaine de marque (requis)")
+	RollbackCmd.Flags().UintVar(&rollbackRevisionFlag, "revision", 0, "Révision à annuler (par défaut: la plus récente)")
+	RollbackCmd.Flags().BoolVar(&rollbackListFlag, "list", false, "Affiche l'historique des révisions sans rien modifier")
+	RollbackCmd.Flags().StringVar(&changedByFlag, "by", "", "Auteur du retour arrière (par défaut: utilisateur courant)")
+	RollbackCmd.MarkFlagRequired("code")
+
+	cmd2.RootCmd.AddCommand(RollbackCmd)
+}
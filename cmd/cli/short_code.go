@@ -0,0 +1,22 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/axellelanca/urlshortener/internal/config"
+)
+
+// parseCodeFlag découpe la valeur d'un flag --code en domaine court et code court.
+// La valeur est soit un code seul (domaine par défaut), soit un code qualifié "domaine/code"
+// tel qu'affiché par les commandes de la CLI ; le domaine peut aussi être une URL de base.
+func parseCodeFlag(server config.ServerConfig, value string) (domain, shortCode string, err error) {
+	i := strings.LastIndex(value, "/")
+	if i < 0 {
+		return "", value, nil
+	}
+	domain, err = server.NormalizeDomain(value[:i])
+	if err != nil {
+		return "", "", err
+	}
+	return domain, value[i+1:], nil
+}
@@ -1,20 +1,23 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"sort"
+	"time"
 
 	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/axellelanca/urlshortener/internal/repository"
 	"github.com/axellelanca/urlshortener/internal/services"
 	"github.com/spf13/cobra"
-	"github.com/axellelanca/urlshortener/internal/models"
 	"gorm.io/driver/sqlite" // Driver SQLite pour GORM
 	"gorm.io/gorm"
 )
 
-// TODO : variable shortCodeFlag qui stockera la valeur du flag --code
+// shortCodeFlag stocke la valeur du flag --code.
 var shortCodeFlag string
 
 // StatsCmd représente la commande 'stats'
@@ -27,83 +30,142 @@ pour une URL courte spécifique en utilisant son code.
 Exemple:
   url-shortener stats --code="xyz123"`,
 	Run: func(cmd *cobra.Command, args []string) {
-		// TODO : Valider que le flag --code a été fourni.
-		// os.Exit(1) si erreur
+		// Valider que le flag --code a été fourni.
 		if shortCodeFlag == "" {
-            fmt.Println("Erreur: Le flag --code est requis")
-            os.Exit(1)
-        }
-
-		// TODO : Charger la configuration chargée globalement via cmd.cfg
-		cfg := cmd2.GetConfig()
-		
-
-		// TODO 3: Initialiser la connexion à la base de données SQLite avec GORM.
-		// log.Fatalf si erreur
-		db, err := gorm.Open(sqlite.Open(cfg.Database.Path), &gorm.Config{})
-        if err != nil {
-            log.Fatalf("FATAL: Échec de la connexion à la base de données: %v", err)
-        }
+			fmt.Println("Erreur: Le flag --code est requis")
+			os.Exit(1)
+		}
+
+		// Charger la configuration chargée globalement via cmd.Cfg
+		cfg := cmd2.Cfg
+		if cfg == nil {
+			log.Fatalf("FATAL: Configuration non chargée")
+		}
+
+		domain, shortCode, err := parseCodeFlag(cfg.Server, shortCodeFlag)
+		if err != nil {
+			fmt.Printf("Erreur: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Initialiser la connexion à la base de données SQLite avec GORM.
+		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		if err != nil {
+			log.Fatalf("FATAL: Échec de la connexion à la base de données: %v", err)
+		}
 
 		sqlDB, err := db.DB()
-			if err != nil {
-				log.Fatalf("FATAL: Échec de l'obtention de la base de données SQL sous-jacente: %v", err)
-			}
+		if err != nil {
+			log.Fatalf("FATAL: Échec de l'obtention de la base de données SQL sous-jacente: %v", err)
+		}
 
-		// TODO S'assurer que la connexion est fermée à la fin de l'exécution de la commande
 		// fermeture de la connexion après la fin de l'éxecution de la fonction
 		defer sqlDB.Close()
 
-		// TODO : Initialiser les repositories et services nécessaires NewLinkRepository & NewLinkService
-        linkRepo := repository.NewGormLinkRepository(db)
-        linkService := services.NewLinkService(linkRepo)
-
-		clickRepo := repository.NewGormClickRepository(db)
-        clickService := services.NewClickService(clickRepo)
-
-		// TODO 5: Appeler GetLinkStats pour récupérer le lien et ses statistiques.
-		// Attention, la fonction retourne 3 valeurs
-		// Pour l'erreur, utilisez gorm.ErrRecordNotFound
-		// Si erreur, os.Exit(1)
-        link, totalClicks, err := GetLinkStats(linkService, clickService, shortCodeFlag)
-        if err != nil {
-            if errors.Is(err, gorm.ErrRecordNotFound) {
-                fmt.Printf("Erreur: Aucun lien trouvé pour le code: %s\n", shortCodeFlag)
-            } else {
-                fmt.Printf("Erreur: %v\n", err)
-            }
-            os.Exit(1)
-        }
-
-		fmt.Printf("Statistiques pour le code court: %s\n", link.ShortCode)
+		// Initialiser les repositories et services nécessaires.
+		linkRepo := repository.NewLinkRepository(db)
+		linkService := services.NewLinkService(linkRepo)
+
+		clickRepo := repository.NewClickRepository(db)
+		clickService := services.NewClickService(clickRepo)
+
+		// Récupérer le lien et ses statistiques.
+		link, totalClicks, err := GetLinkStats(linkService, clickService, domain, shortCode)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				fmt.Printf("Erreur: Aucun lien trouvé pour le code: %s\n", shortCodeFlag)
+			} else {
+				fmt.Printf("Erreur: %v\n", err)
+			}
+			os.Exit(1)
+		}
+
+		fmt.Printf("Statistiques pour le code court: %s\n", link.QualifiedCode())
 		fmt.Printf("URL longue: %s\n", link.LongURL)
+		if title := link.Metadata.Title; title != "" {
+			fmt.Printf("Titre: %s\n", title)
+		}
+		if description := link.Metadata.Description; description != "" {
+			fmt.Printf("Description: %s\n", description)
+		}
 		fmt.Printf("Total de clics: %d\n", totalClicks)
+		if totalClicks > 0 {
+			byPlatform, err := linkService.GetClicksByPlatform(link)
+			if err != nil {
+				fmt.Printf("Erreur: %v\n", err)
+				os.Exit(1)
+			}
+			printBreakdown("Clics par plateforme", byPlatform)
+
+			byVariant, err := linkService.GetClicksByVariant(link)
+			if err != nil {
+				fmt.Printf("Erreur: %v\n", err)
+				os.Exit(1)
+			}
+			printBreakdown("Clics par variante", byVariant)
+		}
+		if link.ExpiresAt != nil {
+			status := "actif"
+			if link.IsExpired(time.Now()) {
+				status = "expiré"
+			}
+			fmt.Printf("Expiration: %s (%s)\n", link.ExpiresAt.Format(time.RFC3339), status)
+		}
+		if remaining, limited := link.RemainingClicks(); limited {
+			fmt.Printf("Clics restants: %d/%d\n", remaining, link.MaxClicks)
+		}
+		if link.IsProtected() {
+			fmt.Printf("Protégé par mot de passe: oui\n")
+		}
 	},
 }
 
+// printBreakdown affiche une répartition des clics triée par clé ; rien n'est affiché si elle est vide.
+func printBreakdown(title string, counts map[string]int) {
+	if len(counts) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fmt.Printf("%s:\n", title)
+	for i, key := range keys {
+		branch := "├─"
+		if i == len(keys)-1 {
+			branch = "└─"
+		}
+		fmt.Printf("  %s %s: %d\n", branch, key, counts[key])
+	}
+}
+
 // init() s'exécute automatiquement lors de l'importation du package.
 // Il est utilisé pour définir les flags que cette commande accepte.
 
-
-// GetLinkStats récupère un lien et ses statistiques de clics
-func GetLinkStats(linkService *services.LinkService, clickService *services.ClickService, shortCode string) (*models.Link, int, error) {
-    // Récupérer le lien
-    link, err := linkService.GetLinkByShortCode(shortCode)
-    if err != nil {
-        return nil, 0, err
-    }
-
-    // Récupérer le nombre de clics
-    totalClicks, err := clickService.GetClicksCountByLinkID(link.ID)
-    if err != nil {
-        return nil, 0, err
-    }
-
-    return link, totalClicks, nil
+// GetLinkStats récupère un lien d'un domaine court et ses statistiques de clics
+func GetLinkStats(linkService *services.LinkService, clickService *services.ClickService, domain, shortCode string) (*models.Link, int, error) {
+	// Récupérer le lien
+	link, err := linkService.GetLinkByShortCode(domain, shortCode)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Récupérer le nombre de clics
+	totalClicks, err := clickService.GetClicksCountByLinkID(link.ID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return link, totalClicks, nil
 }
 
 func init() {
-    // Ajouter le flag --code à la commande
-    StatsCmd.Flags().StringVar(&shortCodeFlag, "code", "", "Code court du lien à analyser (requis)")
-    StatsCmd.MarkFlagRequired("code")
+	// Ajouter le flag --code à la commande
+	StatsCmd.Flags().StringVar(&shortCodeFlag, "code", "", "Code court du lien à analyser, ou domaine/code pour un domaine de marque (requis)")
+	StatsCmd.MarkFlagRequired("code")
+
+	// Ajouter la commande à RootCmd
+	cmd2.RootCmd.AddCommand(StatsCmd)
 }
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"os/user"
+
+	cmd2 "github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+	"gorm.io/driver/sqlite" // Driver SQLite pour GORM
+	"gorm.io/gorm"
+)
+
+// Flags de la commande update
+var (
+	updateCodeFlag string
+	updateURLFlag  string
+	changedByFlag  string
+)
+
+// UpdateCmd représente la commande 'update'
+var UpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Modifie la destination d'une URL courte existante.",
+	Long: `Cette commande change l'URL longue vers laquelle pointe un code court.
+L'ancienne destination est conservée dans l'historique des révisions et peut être
+restaurée avec la commande 'rollback'.
+
+Exemple:
+  url-shortener update --code="spring-sale" --url="https://example.com/promo-2"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if updateCodeFlag == "" || updateURLFlag == "" {
+			fmt.Println("Erreur: Les flags --code et --url sont requis")
+			os.Exit(1)
+		}
+
+		// Validation basique du format de l'URL
+		if _, err := url.ParseRequestURI(updateURLFlag); err != nil {
+			fmt.Printf("Erreur: URL invalide '%s': %v\n", updateURLFlag, err)
+			os.Exit(1)
+		}
+
+		cfg := cmd2.Cfg
+		if cfg == nil {
+			log.Fatalf("FATAL: Configuration non chargée")
+		}
+
+		domain, shortCode, err := parseCodeFlag(cfg.Server, updateCodeFlag)
+		if err != nil {
+			fmt.Printf("Erreur: %v\n", err)
+			os.Exit(1)
+		}
+
+		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		if err != nil {
+			log.Fatalf("FATAL: Échec de la connexion à la base de données: %v", err)
+		}
+
+		sqlDB, err := db.DB()
+		if err != nil {
+			log.Fatalf("FATAL: Échec de l'obtention de la base de données SQL sous-jacente: %v", err)
+		}
+		defer sqlDB.Close()
+
+		linkRepo := repository.NewLinkRepository(db)
+		linkService := services.NewLinkService(linkRepo)
+		if _, err := cmd2.SetupDestinationPolicy(linkService); err != nil {
+			log.Fatalf("FATAL: Échec de la configuration de la politique des destinations: %v", err)
+		}
+		if _, err := cmd2.SetupThreatFeeds(linkService); err != nil {
+			log.Fatalf("FATAL: Échec du chargement des flux de menaces: %v", err)
+		}
+
+		previous, err := linkService.GetLinkByShortCode(domain, shortCode)
+		if err != nil {
+			printLinkError(updateCodeFlag, err)
+			os.Exit(1)
+		}
+		oldURL := previous.LongURL
+
+		link, err := linkService.UpdateLinkDestination(domain, shortCode, updateURLFlag, actorOrCurrentUser(changedByFlag))
+		if err != nil {
+			printLinkError(updateCodeFlag, err)
+			os.Exit(1)
+		}
+
+		if oldURL == link.LongURL {
+			fmt.Printf("La destination de %s est déjà %s, aucune modification.\n", link.QualifiedCode(), link.LongURL)
+			return
+		}
+		fmt.Printf("Destination de %s mise à jour:\n", link.QualifiedCode())
+		fmt.Printf("Ancienne URL: %s\n", oldURL)
+		fmt.Printf("Nouvelle URL: %s\n", link.LongURL)
+	},
+}
+
+// actorOrCurrentUser retourne l'auteur fourni via --by, ou l'utilisateur système courant.
+func actorOrCurrentUser(actor string) string {
+	if actor != "" {
+		return actor
+	}
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return "cli"
+}
+
+// printLinkError affiche une erreur de récupération de lien de manière lisible.
+func printLinkError(shortCode string, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		fmt.Printf("Erreur: Aucun lien trouvé pour le code: %s\n", shortCode)
+		return
+	}
+	fmt.Printf("Erreur: %v\n", err)
+}
+
+func init() {
+	UpdateCmd.Flags().StringVar(&updateCodeFlag, "code", "", "Code court du lien à modifier, ou domaine/code pour un domaine de marque (requis)")
+	UpdateCmd.Flags().StringVar(&updateURLFlag, "url", "", "Nouvelle URL longue (requis)")
+	UpdateCmd.Flags().StringVar(&changedByFlag, "by", "", "Auteur du changement (par défaut: utilisateur courant)")
+	UpdateCmd.MarkFlagRequired("code")
+	UpdateCmd.MarkFlagRequired("url")
+
+	cmd2.RootCmd.AddCommand(UpdateCmd)
+}
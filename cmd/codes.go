@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/axellelanca/urlshortener/internal/codegen"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"gorm.io/gorm"
+)
+
+// SetupCodeGeneration configure la génération des codes courts du LinkService d'après la section codes
+// de la configuration : stratégie, mots réservés et bloqués, allongement automatique et compteurs
+// persistants des collisions.
+// Le serveur et les commandes de la CLI qui créent des liens partagent ainsi le même comportement.
+func SetupCodeGeneration(linkService *services.LinkService, db *gorm.DB) error {
+	codes := Cfg.Codes
+	counters := repository.NewCounterRepository(db)
+	generator, err := codegen.New(codes.Generator, codegen.Options{
+		Length:   codes.Length,
+		Alphabet: codes.Alphabet,
+		Salt:     codes.Salt,
+	}, repository.NewSequence(counters, codegen.SequenceName))
+	if err != nil {
+		return err
+	}
+	linkService.SetCodeGenerator(generator)
+
+	var blocked []string
+	if codes.BlocklistFile != "" {
+		if blocked, err = codegen.LoadBlocklist(codes.BlocklistFile); err != nil {
+			return fmt.Errorf("liste de blocage illisible: %w", err)
+		}
+	}
+	linkService.SetCodeFilter(codegen.NewFilter(slices.Concat(codegen.DefaultReserved, codes.Reserved), blocked))
+
+	return linkService.SetCodeGrowth(services.CodeGrowthPolicy{
+		MaxLength:        codes.MaxLength,
+		MaxCollisionRate: codes.MaxCollisionRate,
+		Window:           codes.CollisionWindow,
+	}, counters)
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/chain"
+	"github.com/axellelanca/urlshortener/internal/safety"
+	"github.com/axellelanca/urlshortener/internal/services"
+)
+
+// SetupDestinationPolicy construit la politique de sécurité des destinations d'après la section destinations
+// de la configuration et l'applique au LinkService, avec le refus des liens vers le service lui-même (base_url
+// et domaines de marque) et le suivi des raccourcisseurs connus. La politique est retournée pour que les clients
+// HTTP qui visitent les destinations (moniteur, métadonnées) refusent eux aussi les adresses internes.
+func SetupDestinationPolicy(linkService *services.LinkService) (*safety.Policy, error) {
+	destinations := Cfg.Destinations
+	policy, err := safety.New(safety.Options{
+		AllowedSchemes:        destinations.AllowedSchemes,
+		AllowedDomains:        destinations.AllowedDomains,
+		DeniedDomains:         destinations.DeniedDomains,
+		BlockPrivateAddresses: destinations.BlockPrivateAddresses,
+		RejectUnresolvable:    destinations.RejectUnresolvable,
+		ResolveTimeout:        time.Duration(destinations.ResolveTimeoutSeconds) * time.Second,
+	})
+	if err != nil {
+		return nil, err
+	}
+	linkService.SetDestinationPolicy(policy)
+
+	follower, err := chain.New(chain.Options{
+		OwnBaseURLs: append([]string{Cfg.Server.BaseURL}, Cfg.Server.Domains...),
+		Shorteners:  destinations.ShortenerHosts,
+		MaxHops:     destinations.FollowShortenerHops,
+		Client:      policy.HTTPClient(time.Duration(destinations.FollowTimeoutSeconds) * time.Second),
+	})
+	if err != nil {
+		return nil, err
+	}
+	linkService.SetRedirectChain(follower)
+	return policy, nil
+}
@@ -36,7 +36,6 @@ func init() {
 
 	// Configurer l'initialisation de la configuration
 	cobra.OnInitialize(initConfig)
-	// TODO Initialiser la configuration globale avec OnInitialize
 
 	// IMPORTANT : Ici, nous n'appelons PAS RootCmd.AddCommand() directement
 	// pour les commandes 'server', 'create', 'stats', 'migrate'.
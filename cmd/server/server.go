@@ -3,7 +3,6 @@ package server
 import (
 	"context"
 	"fmt"
-	"github.com/axellelanca/urlshortener/internal/config"
 	"github.com/axellelanca/urlshortener/internal/monitor"
 	"log"
 	"net/http"
@@ -14,9 +13,11 @@ import (
 
 	cmd2 "github.com/axellelanca/urlshortener/cmd"
 	"github.com/axellelanca/urlshortener/internal/api"
-	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/geoip"
+	"github.com/axellelanca/urlshortener/internal/metadata"
 	"github.com/axellelanca/urlshortener/internal/repository"
 	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/axellelanca/urlshortener/internal/workers"
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/cobra"
 	"gorm.io/driver/sqlite"
@@ -36,74 +37,103 @@ var RunServerCmd = &cobra.Command{
 			log.Fatalf("ERREUR: Configuration non chargée")
 		}
 
-		// TODO : Charger la configuration chargée globalement via cmd.cfg
-		// Ne pas oublier la gestion d'erreur (si nil ?), si erreur, faire un log.Fataf
-
-		// TODO : Initialiser la connexion à la base de données SQLite avec GORM.
-		// Utilisez le nom de la base de données depuis la configuration (cfg.Database.Name).
+		// Initialiser la connexion à la base de données SQLite avec GORM.
 		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
 		if err != nil {
 			log.Fatalf("Erreur lors de la connexion à la base de données: %v", err)
 		}
 
 		// Auto-migrer les modèles GORM
-		err = db.AutoMigrate(&models.Link{}, &models.Click{})
+		err = repository.Migrate(db)
 		if err != nil {
 			log.Fatalf("Erreur lors de la migration automatique: %v", err)
 		}
 		log.Println("Migration automatique des modèles terminée avec succès.")
 
-		// TODO : Initialiser les repositories.
-		// Créez des instances de GormLinkRepository et GormClickRepository.
+		// Initialiser les repositories.
 		linkRepo := repository.NewLinkRepository(db)
+		clickRepo := repository.NewClickRepository(db)
 
 		// Laissez le log
 		log.Println("Repositories initialisés.")
 
-		// TODO : Initialiser les services métiers.
-		// Créez des instances de LinkService et ClickService, en leur passant les repositories nécessaires.
+		// Initialiser les services métiers.
 		linkService := services.NewLinkService(linkRepo)
-		clickService := services.NewClickService(linkRepo)
-		// Laissez le log
-		log.Println("Services métiers initialisés.")
-
-		// Initialiser la connexion à la base de données SQLite
-		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		if err := cmd2.SetupCodeGeneration(linkService, db); err != nil {
+			log.Fatalf("FATAL: Échec de la configuration de la génération des codes courts: %v", err)
+		}
+		destinationPolicy, err := cmd2.SetupDestinationPolicy(linkService)
 		if err != nil {
-			log.Fatalf("ERREUR: Impossible de se connecter à la base de données: %v", err)
+			log.Fatalf("FATAL: Échec de la configuration de la politique des destinations: %v", err)
 		}
+		if cfg.Metadata.Enabled {
+			// Le client de la politique refuse de se connecter aux adresses internes, redirections comprises.
+			linkService.SetMetadataFetcher(metadata.NewFetcher(
+				destinationPolicy.HTTPClient(time.Duration(cfg.Metadata.TimeoutSeconds)*time.Second),
+				cfg.Metadata.UserAgent,
+				int64(cfg.Metadata.MaxSizeKB)*1024,
+			))
+		}
+		// Les destinations par pays utilisent une base GeoIP locale, rechargeable sans redémarrage (SIGHUP).
+		if cfg.GeoIP.DatabasePath != "" {
+			geoResolver := geoip.NewResolver(cfg.GeoIP.DatabasePath)
+			if err := geoResolver.Reload(); err != nil {
+				log.Printf("Attention: base GeoIP indisponible (%v), les liens redirigeront vers leur URL longue.", err)
+			}
+			defer geoResolver.Close()
+			linkService.SetCountryResolver(geoResolver)
 
-		// Auto-migrer les modèles GORM
-		err = db.AutoMigrate(&models.Link{}, &models.Click{})
+			reload := make(chan os.Signal, 1)
+			signal.Notify(reload, syscall.SIGHUP)
+			go workers.StartGeoIPReload(geoResolver, time.Duration(cfg.GeoIP.ReloadIntervalMinutes)*time.Minute, reload)
+		}
+		// Les flux de menaces sont rechargés sur SIGHUP et quand un fichier change ; les liens existants
+		// sont revérifiés au démarrage puis après chaque rechargement.
+		threatChecker, err := cmd2.SetupThreatFeeds(linkService)
 		if err != nil {
-			log.Fatalf("ERREUR: Échec de la migration automatique: %v", err)
+			log.Fatalf("FATAL: Échec du chargement des flux de menaces: %v", err)
 		}
-		log.Println(" Migration automatique des modèles terminée avec succès")
-		// Initialiser les repositories
-		linkRepo := repository.NewLinkRepository(db)
-		log.Println(" Repositories initialisés")
-
-		// Initialiser les services métiers
-		linkService := services.NewLinkService(linkRepo)
-		log.Println(" Services métiers initialisés")
-
-		// Configurer le routeur Gin et les routes
+		if threatChecker != nil {
+			reload := make(chan os.Signal, 1)
+			signal.Notify(reload, syscall.SIGHUP)
+			go workers.StartThreatFeedReload(threatChecker, linkService, time.Duration(cfg.Threats.ReloadIntervalMinutes)*time.Minute, reload)
+		}
+		// Laissez le log
+		log.Println("Services métiers initialisés.")
 
-		// TODO : Initialiser et lancer le moniteur d'URLs.
-		// Utilisez l'intervalle configuré (cfg.Monitor.IntervalMinutes).
-		// Lancez le moniteur dans sa propre goroutine.
+		// Initialiser et lancer le moniteur d'URLs dans sa propre goroutine.
 		monitorInterval := time.Duration(cfg.Monitor.IntervalMinutes) * time.Minute
 		urlMonitor := monitor.NewUrlMonitor(linkRepo, monitorInterval) // Le moniteur a besoin du linkRepo et de l'interval
+		urlMonitor.SetHTTPClient(destinationPolicy.HTTPClient(monitor.DefaultTimeout))
 		go urlMonitor.Start()
 		log.Printf("Moniteur d'URLs démarré avec un intervalle de %v.", monitorInterval)
 
-		// TODO : Configurer le routeur Gin et les handlers API.
-		// Passez les services nécessaires aux fonctions de configuration des routes.
+		// Lancer la purge périodique de la corbeille dans sa propre goroutine.
+		go workers.StartTrashPurge(
+			linkService,
+			time.Duration(cfg.Trash.PurgeIntervalMinutes)*time.Minute,
+			time.Duration(cfg.Trash.RetentionDays)*24*time.Hour,
+			time.Duration(cfg.Trash.QuarantineDays)*24*time.Hour,
+		)
+		log.Printf("Purge de la corbeille démarrée (rétention: %d jours).", cfg.Trash.RetentionDays)
+
+		// Configurer le routeur Gin et les handlers API.
 		router := gin.Default()
-		api.SetupRoutes(router, linkService)
+		// Sans liste explicite, gin croirait l'en-tête X-Forwarded-For de n'importe quel client.
+		var trustedProxies []string
+		if len(cfg.Server.TrustedProxies) > 0 {
+			trustedProxies = cfg.Server.TrustedProxies
+		}
+		if err := router.SetTrustedProxies(trustedProxies); err != nil {
+			log.Fatalf("FATAL: Proxys de confiance invalides: %v", err)
+		}
+		api.SetupRoutes(router, linkService, urlMonitor)
 		// Pas toucher au log
 		log.Println("Routes API configurées.")
 
+		// Lancer les workers qui persistent les clics envoyés sur le channel créé par SetupRoutes.
+		workers.StartClickWorkers(cfg.Analytics.WorkerCount, api.ClickEventsChannel, clickRepo)
+
 		// Créer le serveur HTTP
 		serverAddr := fmt.Sprintf(":%d", cfg.Server.Port)
 		srv := &http.Server{
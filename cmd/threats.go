@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/axellelanca/urlshortener/internal/threats"
+)
+
+// SetupThreatFeeds charge les flux de menaces de la section threats de la configuration et les applique
+// au LinkService. Sans flux configuré, elle ne fait rien et retourne nil. Le Checker est retourné pour
+// que le serveur puisse le recharger.
+func SetupThreatFeeds(linkService *services.LinkService) (*threats.Checker, error) {
+	if len(Cfg.Threats.Feeds) == 0 {
+		return nil, nil
+	}
+	feeds := make([]threats.Feed, len(Cfg.Threats.Feeds))
+	for i, feed := range Cfg.Threats.Feeds {
+		feeds[i] = threats.Feed{Name: feed.Name, Path: feed.Path, Format: threats.Format(feed.Format)}
+	}
+	checker, err := threats.NewChecker(feeds)
+	if err != nil {
+		return nil, err
+	}
+	if err := checker.Reload(); err != nil {
+		return nil, err
+	}
+	linkService.SetThreatChecker(checker)
+	return checker, nil
+}
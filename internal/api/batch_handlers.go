@@ -0,0 +1,159 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// maxBatchItemBytes est la taille moyenne tolérée par élément d'un lot ; le corps d'une création
+// en masse est limité à MaxBatchSize éléments de cette taille (voir maxBatchBodyBytes).
+const maxBatchItemBytes = 8 << 10
+
+// maxBatchBodyBytes borne le corps d'une création en masse, lu avant que le nombre d'éléments soit connu.
+var maxBatchBodyBytes int64 = services.MaxBatchSize * maxBatchItemBytes
+
+// errBatchNotArray est renvoyée quand le corps d'une création en masse n'est pas un tableau JSON.
+var errBatchNotArray = errors.New("batch must be a JSON array of links")
+
+// BatchItemResult est le résultat d'un élément d'une création en masse.
+type BatchItemResult struct {
+	Index        int    `json:"index"`                    // Position de l'élément dans le tableau envoyé
+	Status       int    `json:"status"`                   // Code HTTP équivalent pour cet élément
+	ShortCode    string `json:"short_code,omitempty"`     // Code court créé
+	Domain       string `json:"domain,omitempty"`         // Domaine court de marque du lien (vide = domaine par défaut)
+	LongURL      string `json:"long_url,omitempty"`       // URL longue du lien créé
+	Reused       bool   `json:"reused,omitempty"`         // Lien existant équivalent retourné (mode "reuse")
+	FullShortURL string `json:"full_short_url,omitempty"` // URL courte complète
+	Error        string `json:"error,omitempty"`          // Raison de l'échec de cet élément
+	Reason       string `json:"reason,omitempty"`         // Code du refus de la destination par la politique de sécurité
+}
+
+// BatchCreateLinksHandler gère la création de plusieurs liens en une seule requête.
+// Le corps est un tableau de CreateLinkRequest ; chaque élément est validé et créé indépendamment,
+// et la réponse contient un résultat par élément, dans le même ordre. Un élément en mode "reuse"
+// satisfait par un lien existant a le statut 200 et compte parmi les liens réutilisés, pas créés.
+func BatchCreateLinksHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Le tableau est décodé sans validation globale : ShouldBindJSON rejetterait tout le lot
+		// au premier élément invalide.
+		reqs, err := decodeBatch(http.MaxBytesReader(c.Writer, c.Request.Body, maxBatchBodyBytes))
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.Is(err, services.ErrBatchTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+			return
+		case errors.As(err, &tooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit)})
+			return
+		case err != nil:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if len(reqs) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "batch must contain at least one link"})
+			return
+		}
+
+		results := make([]BatchItemResult, len(reqs))
+		inputs := make([]services.CreateLinkInput, 0, len(reqs))
+		positions := make([]int, 0, len(reqs)) // Position d'origine de chaque élément transmis au service
+		for i := range reqs {
+			results[i].Index = i
+			// Les tags 'binding' sont vérifiés élément par élément pour ne pas rejeter tout le lot.
+			if err := binding.Validator.ValidateStruct(&reqs[i]); err != nil {
+				results[i].Status = http.StatusBadRequest
+				results[i].Error = err.Error()
+				continue
+			}
+			input, err := reqs[i].toInput(c)
+			if err != nil {
+				results[i].Status = http.StatusBadRequest
+				results[i].Error = err.Error()
+				continue
+			}
+			inputs = append(inputs, input)
+			positions = append(positions, i)
+		}
+
+		created, err := linkService.CreateLinks(inputs)
+		if err != nil {
+			log.Printf("Error creating batch of %d links: %v", len(inputs), err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		succeeded, reused := 0, 0
+		for j, result := range created {
+			item := &results[positions[j]]
+			if result.Err != nil {
+				status, ok := createLinkErrorStatus(result.Err)
+				if !ok {
+					log.Printf("Error creating batch item %d: %v", item.Index, result.Err)
+					item.Status = http.StatusInternalServerError
+					item.Error = "Internal server error"
+					continue
+				}
+				item.Status = status
+				item.Error = result.Err.Error()
+				item.Reason = violationReason(result.Err)
+				continue
+			}
+			item.Status = http.StatusCreated
+			if result.Link.Reused {
+				item.Status = http.StatusOK
+				item.Reused = true
+				reused++
+			}
+			item.ShortCode = result.Link.ShortCode
+			item.LongURL = result.Link.LongURL
+			item.Domain = result.Link.Domain
+			item.FullShortURL = fullShortURL(result.Link)
+			succeeded++
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"created": succeeded - reused,
+			"reused":  reused,
+			"failed":  len(reqs) - succeeded,
+			"results": results,
+		})
+	}
+}
+
+// decodeBatch lit le tableau d'une création en masse élément par élément et s'arrête dès que
+// MaxBatchSize est dépassé, sans décoder la suite du corps.
+func decodeBatch(body io.Reader) ([]CreateLinkRequest, error) {
+	decoder := json.NewDecoder(body)
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return nil, errBatchNotArray
+	}
+
+	var reqs []CreateLinkRequest
+	for decoder.More() {
+		if len(reqs) == services.MaxBatchSize {
+			return nil, services.ErrBatchTooLarge
+		}
+		var req CreateLinkRequest
+		if err := decoder.Decode(&req); err != nil {
+			return nil, err
+		}
+		reqs = append(reqs, req)
+	}
+	// Consomme le ']' final : un tableau tronqué est une erreur.
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	return reqs, nil
+}
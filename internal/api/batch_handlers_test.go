@@ -0,0 +1,69 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+func TestBatchCreateLinksHandlerRejectsBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tooMany := "[" + strings.Repeat(`{"long_url":"https://example.com/"},`, services.MaxBatchSize) + `{"long_url":"https://example.com/"}]`
+	tests := []struct {
+		name       string
+		body       string
+		bodyLimit  int64
+		wantStatus int
+	}{
+		{name: "invalid JSON", body: `[{"long_url":`, wantStatus: http.StatusBadRequest},
+		{name: "object instead of array", body: `{"long_url":"https://example.com/"}`, wantStatus: http.StatusBadRequest},
+		{name: "null", body: `null`, wantStatus: http.StatusBadRequest},
+		{name: "empty array", body: `[]`, wantStatus: http.StatusBadRequest},
+		{name: "unterminated array", body: `[{"long_url":"https://example.com/"}`, wantStatus: http.StatusBadRequest},
+		{name: "more than MaxBatchSize links", body: tooMany, wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "body over the size limit", body: `[{"long_url":"https://example.com/` + strings.Repeat("a", 200) + `"}]`, bodyLimit: 100, wantStatus: http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.bodyLimit > 0 {
+				defaultLimit := maxBatchBodyBytes
+				maxBatchBodyBytes = tt.bodyLimit
+				t.Cleanup(func() { maxBatchBodyBytes = defaultLimit })
+			}
+			// Le corps est refusé avant tout appel au service : aucun repository n'est nécessaire.
+			router := gin.New()
+			router.POST("/api/v1/links/batch", BatchCreateLinksHandler(services.NewLinkService(nil)))
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/links/batch", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body: %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestDecodeBatchStopsAfterMaxBatchSize(t *testing.T) {
+	// Le reste du corps n'est jamais lu : un JSON invalide après la limite ne change pas l'erreur.
+	body := "[" + strings.Repeat(`{},`, services.MaxBatchSize+1) + "not json"
+	if _, err := decodeBatch(strings.NewReader(body)); !errors.Is(err, services.ErrBatchTooLarge) {
+		t.Fatalf("decodeBatch error = %v, want ErrBatchTooLarge", err)
+	}
+
+	reqs, err := decodeBatch(strings.NewReader(`[{"long_url":"https://a.example/"},{"long_url":"https://b.example/","alias":"b-link"}]`))
+	if err != nil {
+		t.Fatalf("decodeBatch: %v", err)
+	}
+	if len(reqs) != 2 || reqs[1].Alias != "b-link" {
+		t.Errorf("decodeBatch = %+v, want the two links in order", reqs)
+	}
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// hostDomain retourne le domaine court désigné par l'en-tête Host de la requête.
+// Les redirections publiques n'utilisent que lui : la query string appartient au visiteur.
+func hostDomain(c *gin.Context) string {
+	return cmd.Cfg.Server.DomainForHost(c.Request.Host)
+}
+
+// apiDomain retourne le domaine court visé par une requête de gestion de l'API : le paramètre
+// ?domain= (hôte ou URL de base) s'il est présent, sinon le domaine de l'en-tête Host.
+// Un domaine inconnu reçoit une réponse 400 et la fonction retourne false.
+func apiDomain(c *gin.Context) (string, bool) {
+	requested := c.Query("domain")
+	if requested == "" {
+		return hostDomain(c), true
+	}
+	domain, err := cmd.Cfg.Server.NormalizeDomain(requested)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return "", false
+	}
+	return domain, true
+}
+
+// fullShortURL retourne l'URL courte complète d'un lien, sur son domaine.
+func fullShortURL(link *models.Link) string {
+	return cmd.Cfg.Server.ShortURL(link.Domain, link.ShortCode)
+}
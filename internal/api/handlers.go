@@ -1,46 +1,84 @@
 package api
 
 import (
+	"crypto/rand"
 	"errors"
 	"github.com/axellelanca/urlshortener/cmd"
 	"log"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
+	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/protection"
+	"github.com/axellelanca/urlshortener/internal/safety"
+
 	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/axellelanca/urlshortener/internal/services"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm" // Pour gérer gorm.ErrRecordNotFound
 )
 
-
-// TODO Créer une variable ClickEventsChannel qui est un chan de type ClickEvent
 // ClickEventsChannel est le channel global (ou injecté) utilisé pour envoyer les événements de clic
 // aux workers asynchrones. Il est bufferisé pour ne pas bloquer les requêtes de redirection.
 var ClickEventsChannel chan models.ClickEvent
 
-
 // SetupRoutes configure toutes les routes de l'API Gin et injecte les dépendances nécessaires
-func SetupRoutes(router *gin.Engine, linkService *services.LinkService) {
+func SetupRoutes(router *gin.Engine, linkService *services.LinkService, reachability ReachabilityReader) {
 	// Le channel est initialisé ici.
 	if ClickEventsChannel == nil {
 		ClickEventsChannel = make(chan models.ClickEvent, cmd.Cfg.Analytics.BufferSize)
 	}
 
+	// Le guard des liens protégés est construit à partir de la configuration.
+	guard := newPasswordGuard(cmd.Cfg.Password)
+
+	// /<code>+ et ?preview=1 affichent l'aperçu du lien au lieu de rediriger.
+	redirect := withPreview(PreviewHandler(linkService, guard, reachability), RedirectHandler(linkService, guard))
 
 	router.GET("/health", HealthCheckHandler)
 
 	// Routes de l'API au format /api/v1/
 	api := router.Group("/api/v1")
 	{
+		// GET /links liste les liens avec filtres et pagination par curseur
+		api.GET("/links", ListLinksHandler(linkService))
+
 		// POST /links
 		api.POST("/links", CreateShortLinkHandler(linkService))
-		
+		// POST /links/batch crée plusieurs liens en une requête
+		api.POST("/links/batch", BatchCreateLinksHandler(linkService))
+
 		// GET /links/:shortCode/stats
 		api.GET("/links/:shortCode/stats", GetLinkStatsHandler(linkService))
+		// GET /links/:shortCode/qr génère le QR code de l'URL courte (PNG ou SVG)
+		api.GET("/links/:shortCode/qr", GetLinkQRHandler(linkService))
+
+		api.GET("/links/:shortCode", redirect)
+		api.POST("/links/:shortCode/unlock", UnlockHandler(linkService, guard))
+
+		// PATCH /links/:shortCode, historique et retour arrière des destinations
+		api.PATCH("/links/:shortCode", UpdateLinkHandler(linkService))
+		api.GET("/links/:shortCode/revisions", GetLinkRevisionsHandler(linkService))
+		api.POST("/links/:shortCode/rollback", RollbackLinkHandler(linkService))
 
-		api.GET("/links/:shortCode", RedirectHandler(linkService))
+		// Règles de redirection : remplacement et simulation sur une requête fictive
+		api.PUT("/links/:shortCode/rules", UpdateLinkRulesHandler(linkService))
+		api.POST("/links/:shortCode/rules/dry-run", DryRunRulesHandler(linkService))
+
+		// DELETE /links/:shortCode place le lien à la corbeille
+		api.DELETE("/links/:shortCode", DeleteLinkHandler(linkService))
 	}
+
+	// Redirection publique : c'est la forme renvoyée dans 'full_short_url' (URL de base du domaine + "/" + code).
+	// Le domaine du lien est déduit de l'en-tête Host de la requête.
+	router.GET("/:shortCode", redirect)
+	// Un client qui poste vers l'URL courte est redirigé comme pour un GET : les types 307 et 308
+	// lui font rejouer sa requête POST sur la destination.
+	router.POST("/:shortCode", RedirectHandler(linkService, guard))
+	// Soumission du formulaire de mot de passe d'un lien protégé.
+	router.POST("/:shortCode/unlock", UnlockHandler(linkService, guard))
 }
 
 // HealthCheckHandler gère la route /health pour vérifier l'état du service.
@@ -51,13 +89,85 @@ func HealthCheckHandler(c *gin.Context) {
 // CreateLinkRequest représente le corps de la requête JSON pour la création d'un lien.
 type CreateLinkRequest struct {
 	LongURL string `json:"long_url" binding:"required,url"` // 'binding:required' pour validation, 'url' pour format URL
+	Alias   string `json:"alias"`                           // Code court personnalisé optionnel (ex: "spring-sale")
+	Domain  string `json:"domain"`                          // Domaine court de marque (par défaut celui de l'en-tête Host)
+
+	ExpiresAt *time.Time `json:"expires_at"`                           // Date d'expiration optionnelle (RFC 3339)
+	MaxClicks int        `json:"max_clicks" binding:"omitempty,min=1"` // Nombre maximum de clics optionnel
+	Password  string     `json:"password"`                             // Mot de passe optionnel protégeant la redirection
+	NotBefore *time.Time `json:"not_before"`                           // Date d'activation optionnelle (RFC 3339)
+
+	Rules    []models.RedirectRule         `json:"rules"`    // Règles de redirection déclaratives (première qui correspond)
+	Schedule []models.ScheduledDestination `json:"schedule"` // Destinations programmées sur des plages horaires (UTC)
+
+	IOSURL     string `json:"ios_url" binding:"omitempty,url"`     // Destination optionnelle des visiteurs iOS
+	AndroidURL string `json:"android_url" binding:"omitempty,url"` // Destination optionnelle des visiteurs Android
+	DesktopURL string `json:"desktop_url" binding:"omitempty,url"` // Destination optionnelle des visiteurs sur ordinateur
+
+	CountryURLs map[string]string `json:"country_urls"` // Destinations optionnelles par code pays (ex: {"FR": "https://example.fr"})
+
+	Variants       []models.SplitVariant `json:"variants"`        // Destinations pondérées d'un test A/B (ex: 70/30)
+	StickyVariants bool                  `json:"sticky_variants"` // Mémorise la variante de chaque visiteur dans un cookie
+
+	RedirectType models.RedirectType `json:"redirect_type"` // 301, 302, 307, 308 ou meta-refresh (vide = défaut du serveur)
+
+	ForwardQuery bool   `json:"forward_query"` // Transmet la query string de l'URL courte à la destination
+	UTMSource    string `json:"utm_source"`    // Paramètres UTM ajoutés par défaut à la destination
+	UTMMedium    string `json:"utm_medium"`
+	UTMCampaign  string `json:"utm_campaign"`
+
+	Reuse bool `json:"reuse"` // Retourne un lien existant équivalent plutôt que d'en créer un nouveau
+}
+
+// toInput convertit la requête en paramètres de création pour le LinkService.
+// Sans domaine explicite, le lien est créé sur le domaine de l'en-tête Host ; un domaine
+// inconnu retourne config.ErrUnknownDomain.
+func (r *CreateLinkRequest) toInput(c *gin.Context) (services.CreateLinkInput, error) {
+	domain := hostDomain(c)
+	if r.Domain != "" {
+		var err error
+		if domain, err = cmd.Cfg.Server.NormalizeDomain(r.Domain); err != nil {
+			return services.CreateLinkInput{}, err
+		}
+	}
+
+	return services.CreateLinkInput{
+		LongURL:   r.LongURL,
+		Alias:     r.Alias,
+		Domain:    domain,
+		ExpiresAt: r.ExpiresAt,
+		MaxClicks: r.MaxClicks,
+		Password:  r.Password,
+		NotBefore: r.NotBefore,
+
+		Rules:    r.Rules,
+		Schedule: r.Schedule,
+
+		IOSURL:      r.IOSURL,
+		AndroidURL:  r.AndroidURL,
+		DesktopURL:  r.DesktopURL,
+		CountryURLs: r.CountryURLs,
+
+		Variants:       r.Variants,
+		StickyVariants: r.StickyVariants,
+
+		RedirectType: r.RedirectType,
+		ForwardQuery: r.ForwardQuery,
+		UTM: models.UTMParams{
+			Source:   r.UTMSource,
+			Medium:   r.UTMMedium,
+			Campaign: r.UTMCampaign,
+		},
+
+		ReuseExisting: r.Reuse,
+	}, nil
 }
 
 // CreateShortLinkHandler gère la création d'une URL courte.
 func CreateShortLinkHandler(linkService *services.LinkService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req CreateLinkRequest
-		
+
 		// Tente de lier le JSON de la requête à la structure CreateLinkRequest.
 		// Gin gère la validation 'binding'.
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -67,9 +177,19 @@ func CreateShortLinkHandler(linkService *services.LinkService) gin.HandlerFunc {
 			return
 		}
 
+		input, err := req.toInput(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
 		// Appeler le LinkService (CreateLink) pour créer le nouveau lien.
-		link, err := linkService.CreateLink(req.LongURL)
+		link, err := linkService.CreateLink(input)
 		if err != nil {
+			if status, ok := createLinkErrorStatus(err); ok {
+				c.JSON(status, errorBody(err))
+				return
+			}
 			log.Printf("Error creating link for URL %s: %v", req.LongURL, err)
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Internal server error",
@@ -78,21 +198,75 @@ func CreateShortLinkHandler(linkService *services.LinkService) gin.HandlerFunc {
 		}
 
 		// Retourne le code court et l'URL longue dans la réponse JSON.
-		// Code HTTP 201 Created (nouvelle ressource)
-		c.JSON(http.StatusCreated, gin.H{
+		// Code HTTP 201 Created (nouvelle ressource), 200 OK si un lien existant a été réutilisé.
+		status := http.StatusCreated
+		if link.Reused {
+			status = http.StatusOK
+		}
+		c.JSON(status, gin.H{
 			"short_code":     link.ShortCode,
+			"domain":         link.Domain,
 			"long_url":       link.LongURL,
-			"full_short_url": cmd.Cfg.Server.BaseURL + "/" + link.ShortCode, // Utiliser cfg.Server.BaseURL
+			"full_short_url": fullShortURL(link), // BaseURL ou URL de base du domaine de marque
+			"reused":         link.Reused,
 		})
 	}
 }
+
+// createLinkErrorStatus associe une erreur de création de lien connue (le plus souvent imputable
+// au client) à son code HTTP. Elle retourne false pour les erreurs internes.
+func createLinkErrorStatus(err error) (int, bool) {
+	switch {
+	case errors.Is(err, services.ErrInvalidAlias),
+		errors.Is(err, services.ErrInvalidExpiration),
+		errors.Is(err, services.ErrInvalidMaxClicks),
+		errors.Is(err, services.ErrInvalidPassword),
+		errors.Is(err, services.ErrInvalidUTM),
+		errors.Is(err, services.ErrInvalidCountryRule),
+		errors.Is(err, services.ErrInvalidVariants),
+		errors.Is(err, services.ErrInvalidSchedule),
+		errors.Is(err, services.ErrInvalidRules),
+		errors.Is(err, services.ErrInvalidRedirectType),
+		errors.Is(err, services.ErrCodeNotAllowed),
+		errors.Is(err, services.ErrInvalidURL),
+		errors.Is(err, services.ErrUnsafeDestination),
+		errors.Is(err, config.ErrUnknownDomain):
+		return http.StatusBadRequest, true
+	case errors.Is(err, services.ErrAliasTaken):
+		return http.StatusConflict, true
+	case errors.Is(err, services.ErrNoShortCodeAvailable):
+		return http.StatusServiceUnavailable, true
+	}
+	return 0, false
+}
+
+// errorBody construit le corps JSON d'une erreur imputable au client. Le refus d'une destination
+// par la politique de sécurité y ajoute son code machine dans "reason" (ex: "private_address").
+func errorBody(err error) gin.H {
+	body := gin.H{"error": err.Error()}
+	if reason := violationReason(err); reason != "" {
+		body["reason"] = reason
+	}
+	return body
+}
+
+// violationReason retourne le code du refus d'une destination par la politique de sécurité,
+// ou "" si l'erreur n'en provient pas.
+func violationReason(err error) string {
+	var violation *safety.Violation
+	if errors.As(err, &violation) {
+		return string(violation.Reason)
+	}
+	return ""
+}
+
 // RedirectHandler gère la redirection d'une URL courte vers l'URL longue et l'enregistrement asynchrone des clics.
-func RedirectHandler(linkService *services.LinkService) gin.HandlerFunc {
+func RedirectHandler(linkService *services.LinkService, guard *protection.Guard) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Récupère le shortCode de l'URL avec c.Param
-		shortCode :=c.Param("shortCode")
+		shortCode := c.Param("shortCode")
 
-		link, err := linkService.GetLinkByShortCode(shortCode)
+		link, err := linkService.GetLinkForRedirect(hostDomain(c), shortCode)
 		if err != nil {
 			// Si le lien n'est pas trouvé, retourner HTTP 404 Not Found.
 			if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -104,11 +278,66 @@ func RedirectHandler(linkService *services.LinkService) gin.HandlerFunc {
 			return
 		}
 
+		// Un lien supprimé, désactivé, expiré ou dont le budget de clics est épuisé répond 410 Gone,
+		// un lien dont la date d'activation n'est pas atteinte répond 404.
+		if err := linkService.CheckAvailability(link); err != nil && respondLinkUnavailable(c, err) {
+			return
+		}
+
+		// Un lien signalé par un flux de menaces affiche un avertissement au lieu de rediriger, sans consommer de clic.
+		if link.IsFlagged() {
+			renderThreatWarning(c, link)
+			return
+		}
+
+		// Un lien protégé affiche le formulaire tant qu'aucun cookie de déverrouillage valide n'est présenté.
+		if link.IsProtected() && !hasUnlockCookie(c, guard, link) {
+			renderUnlockPage(c, http.StatusUnauthorized, "")
+			return
+		}
+
+		if err := linkService.ConsumeClick(link); err != nil {
+			if respondLinkUnavailable(c, err) {
+				return
+			}
+			log.Printf("Error consuming click for %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		visit := services.Visit{
+			UserAgent: c.GetHeader("User-Agent"),
+			Header:    c.Request.Header,
+			IP:        c.ClientIP(),
+			Query:     c.Request.URL.Query(),
+			At:        time.Now().UTC(),
+		}
+		if link.StickyVariants {
+			visit.Variant, _ = c.Cookie(variantCookieName(link))
+		}
+		destination := linkService.ResolveDestination(link, visit)
+
+		// La variante servie est mémorisée pour que le visiteur la retrouve à sa prochaine visite.
+		if link.StickyVariants && destination.Variant != "" && destination.Variant != visit.Variant {
+			c.SetSameSite(http.SameSiteLaxMode)
+			c.SetCookie(
+				variantCookieName(link),
+				destination.Variant,
+				variantCookieMaxAge,
+				"/",
+				"",
+				strings.HasPrefix(fullShortURL(link), "https://"),
+				true,
+			)
+		}
+
 		clickEvent := models.ClickEvent{
 			LinkID:    link.ID,
 			TimesTamp: time.Now(),
 			UserAgent: c.GetHeader("User-Agent"),
-			IPAddress:  c.ClientIP(),
+			IPAddress: c.ClientIP(),
+			Platform:  string(destination.Platform),
+			Variant:   destination.Variant,
 		}
 
 		select {
@@ -117,42 +346,247 @@ func RedirectHandler(linkService *services.LinkService) gin.HandlerFunc {
 			log.Printf("Warning: ClickEventsChannel is full, dropping click event for %s.", shortCode)
 		}
 
-		c.Redirect(http.StatusFound, link.LongURL)
+		respondRedirect(c, link, destination.URL)
+	}
+}
 
+// UnlockHandler vérifie le mot de passe soumis pour un lien protégé.
+// En cas de succès, un cookie signé mémorise le déverrouillage et le client est renvoyé
+// vers l'URL courte (ou son aperçu), qui effectue alors la redirection habituelle.
+func UnlockHandler(linkService *services.LinkService, guard *protection.Guard) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shortCode := strings.TrimSuffix(c.Param("shortCode"), previewSuffix)
+
+		link, err := linkService.GetLinkForRedirect(hostDomain(c), shortCode)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Short link not found"})
+				return
+			}
+			log.Printf("Error retrieving link for %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		if err := linkService.CheckAvailability(link); err != nil && respondLinkUnavailable(c, err) {
+			return
+		}
+		if !link.IsProtected() {
+			c.Redirect(http.StatusSeeOther, unlockReturnURL(c.Request.URL))
+			return
+		}
+
+		ip := c.ClientIP()
+		now := time.Now()
+		if !guard.Allow(ip, now) {
+			renderUnlockPage(c, http.StatusTooManyRequests, "Too many failed attempts. Please try again later.")
+			return
+		}
+
+		if !linkService.VerifyPassword(link, c.PostForm("password")) {
+			guard.RecordFailure(ip, now)
+			log.Printf("Failed password attempt for %s from %s", shortCode, ip)
+			renderUnlockPage(c, http.StatusUnauthorized, "Incorrect password.")
+			return
+		}
+
+		guard.Reset(ip)
+		c.SetSameSite(http.SameSiteLaxMode)
+		c.SetCookie(
+			unlockCookieName(link),
+			guard.IssueToken(link.ID, link.PasswordHash, now),
+			int(guard.UnlockTTL().Seconds()),
+			"/",
+			"",
+			strings.HasPrefix(fullShortURL(link), "https://"),
+			true,
+		)
+		c.Redirect(http.StatusSeeOther, unlockReturnURL(c.Request.URL))
+	}
+}
+
+// unlockSuffix termine le chemin de soumission du formulaire de mot de passe (POST /<code>/unlock).
+const unlockSuffix = "/unlock"
+
+// unlockAction retourne l'URL de soumission du formulaire de mot de passe de la page demandée, query string comprise.
+func unlockAction(u *url.URL) string {
+	action := *u
+	action.RawPath = ""
+	if !strings.HasSuffix(u.Path, unlockSuffix) {
+		action.Path = u.Path + unlockSuffix
+	}
+	return action.RequestURI()
+}
+
+// unlockReturnURL retourne la page d'origine d'une soumission du formulaire de mot de passe, query string comprise.
+func unlockReturnURL(u *url.URL) string {
+	back := *u
+	back.RawPath = ""
+	back.Path = strings.TrimSuffix(u.Path, unlockSuffix)
+	return back.RequestURI()
+}
+
+// variantCookieMaxAge est la durée (en secondes) pendant laquelle un visiteur conserve sa variante A/B.
+const variantCookieMaxAge = 90 * 24 * 60 * 60
+
+// variantCookieName retourne le nom du cookie mémorisant la variante A/B attribuée pour un lien.
+func variantCookieName(link *models.Link) string {
+	return "variant_" + link.ShortCode
+}
+
+// unlockCookieName retourne le nom du cookie de déverrouillage propre à un lien.
+func unlockCookieName(link *models.Link) string {
+	return "unlock_" + link.ShortCode
+}
+
+// hasUnlockCookie indique si la requête présente un cookie de déverrouillage valide pour ce lien.
+func hasUnlockCookie(c *gin.Context, guard *protection.Guard, link *models.Link) bool {
+	token, err := c.Cookie(unlockCookieName(link))
+	if err != nil {
+		return false
+	}
+	return guard.VerifyToken(token, link.ID, link.PasswordHash, time.Now())
+}
+
+// newPasswordGuard construit le guard des liens protégés à partir de la configuration.
+// Sans secret configuré, une clé aléatoire est générée : les cookies ne survivent alors pas à un redémarrage.
+func newPasswordGuard(cfg config.PasswordConfig) *protection.Guard {
+	secret := []byte(cfg.CookieSecret)
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			log.Fatalf("ERREUR: Impossible de générer la clé des cookies de déverrouillage: %v", err)
+		}
+		log.Println("Attention: password.cookie_secret non configuré, utilisation d'une clé aléatoire.")
+	}
+	return protection.NewGuard(
+		secret,
+		time.Duration(cfg.UnlockTTLMinutes)*time.Minute,
+		cfg.MaxAttempts,
+		time.Duration(cfg.AttemptWindowMinutes)*time.Minute,
+	)
+}
+
+// respondLinkUnavailable répond si l'erreur indique que le lien ne peut pas être suivi : 404 pour un lien
+// pas encore actif, 410 Gone pour un lien supprimé, désactivé, expiré ou dont le budget est épuisé.
+// Elle retourne false si l'erreur est d'une autre nature et doit être gérée par l'appelant.
+func respondLinkUnavailable(c *gin.Context, err error) bool {
+	status, reason, message, unavailable := linkUnavailability(err)
+	if !unavailable {
+		return false
+	}
+	c.JSON(status, gin.H{"error": message, "reason": reason})
+	return true
+}
+
+// linkUnavailability traduit une erreur de disponibilité en code HTTP, raison et message exposés aux clients.
+// Elle retourne false si l'erreur n'indique pas un lien inutilisable.
+func linkUnavailability(err error) (status int, reason, message string, unavailable bool) {
+	switch {
+	case errors.Is(err, services.ErrLinkDeleted):
+		return http.StatusGone, "deleted", "This short link has been deleted", true
+	case errors.Is(err, services.ErrLinkDisabled):
+		return http.StatusGone, "disabled", "This short link has been disabled", true
+	case errors.Is(err, services.ErrLinkNotYetActive):
+		return http.StatusNotFound, "not_yet_active", "This short link is not active yet", true
+	case errors.Is(err, services.ErrLinkExpired):
+		return http.StatusGone, "expired", "This short link has expired", true
+	case errors.Is(err, services.ErrClickLimitReached):
+		return http.StatusGone, "click_limit_reached", "This short link has reached its click limit", true
+	default:
+		return 0, "", "", false
+	}
+}
+
+// DeleteLinkHandler place un lien à la corbeille. Il pourra être restauré jusqu'à sa purge définitive.
+func DeleteLinkHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shortCode := c.Param("shortCode")
+		domain, ok := apiDomain(c)
+		if !ok {
+			return
+		}
+
+		if _, err := linkService.DeleteLink(domain, shortCode); err != nil {
+			respondLinkLookupError(c, shortCode, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
 	}
 }
 
 // GetLinkStatsHandler gère la récupération des statistiques pour un lien spécifique.
-func GetLinkStatsHandler(linkService *services.LinkService, clickService *services.ClickService) gin.HandlerFunc {
+func GetLinkStatsHandler(linkService *services.LinkService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		shortCode := c.Param("shortcode")
-		// TODO 6: Appeler le LinkService pour obtenir le lien et le nombre total de clics.
+		shortCode := c.Param("shortCode")
+		domain, ok := apiDomain(c)
+		if !ok {
+			return
+		}
+
+		// Appeler le LinkService pour obtenir le lien et le nombre total de clics.
+		link, totalClicks, err := linkService.GetLinkStats(domain, shortCode)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Short link not found"})
+				return
+			}
+			log.Printf("Error retrieving stats for %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
 
-		link, err := linkService.GetLinkByShortCode(shortCode)
-        if err != nil {
-            if errors.Is(err, gorm.ErrRecordNotFound) {
-                c.JSON(http.StatusNotFound, gin.H{"error":  err.Error()})
-                return
-            }
-            // Gérer d'autres erreurs
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-            return
-        }
+		clicksByPlatform, err := linkService.GetClicksByPlatform(link)
+		if err != nil {
+			log.Printf("Error retrieving stats for %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
 
-		// Récupération du total click de notre link
-		totalClicks, err = clickService.GetClicksCountByLinkID(shortCode)
-        if err != nil {
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve click count"})
-            return
-        }
+		clicksByVariant, err := linkService.GetClicksByVariant(link)
+		if err != nil {
+			log.Printf("Error retrieving stats for %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
 
+		// Le budget restant vaut null pour un lien sans limite de clics.
+		var remainingClicks *int
+		if remaining, limited := link.RemainingClicks(); limited {
+			remainingClicks = &remaining
 		}
 
 		// Retourne les statistiques dans la réponse JSON.
 		c.JSON(http.StatusOK, gin.H{
-			"short_code":   link.ShortCode,
-			"long_url":     link.LongURL,
-			"total_clicks": totalClicks,
+			"short_code":         link.ShortCode,
+			"domain":             link.Domain,
+			"full_short_url":     fullShortURL(link),
+			"long_url":           link.LongURL,
+			"flagged_at":         link.FlaggedAt,
+			"threat_feed":        link.ThreatFeed,
+			"total_clicks":       totalClicks,
+			"clicks_by_platform": clicksByPlatform,
+			"clicks_by_variant":  clicksByVariant,
+			"expires_at":         link.ExpiresAt,
+			"expired":            link.IsExpired(time.Now()),
+			"not_before":         link.NotBefore,
+			"schedule":           link.Schedule,
+			"rules":              link.Rules,
+			"max_clicks":         link.MaxClicks,
+			"remaining_clicks":   remainingClicks,
+			"password_protected": link.IsProtected(),
+			"disabled":           link.Disabled,
+			"ios_url":            link.IOSURL,
+			"android_url":        link.AndroidURL,
+			"desktop_url":        link.DesktopURL,
+			"country_urls":       link.CountryURLs,
+			"variants":           link.Variants,
+			"sticky_variants":    link.StickyVariants,
+			"redirect_type":      effectiveRedirectType(link),
+			"forward_query":      link.ForwardQuery,
+			"utm":                link.UTM,
+			"metadata":           link.Metadata,
 		})
 	}
 }
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// ListLinksHandler gère la liste paginée des liens.
+// Paramètres de requête : created_from, created_to (RFC 3339 ou AAAA-MM-JJ), domain (domaine de destination),
+// short_domain (domaine court de marque), q, sort (created_at ou clicks), order (asc ou desc), limit et cursor (next_cursor de la page précédente).
+func ListLinksHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		params := services.ListLinksParams{
+			Domain: c.Query("domain"),
+			Search: c.Query("q"),
+			SortBy: c.Query("sort"),
+			Cursor: c.Query("cursor"),
+		}
+
+		var err error
+		if shortDomain, ok := c.GetQuery("short_domain"); ok {
+			normalized, err := cmd.Cfg.Server.NormalizeDomain(shortDomain)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			params.ShortDomain = &normalized
+		}
+		if params.CreatedFrom, err = services.ParseDateFilter(c.Query("created_from"), false); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if params.CreatedTo, err = services.ParseDateFilter(c.Query("created_to"), true); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		switch c.DefaultQuery("order", "desc") {
+		case "asc":
+			params.Ascending = true
+		case "desc":
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "order must be 'asc' or 'desc'"})
+			return
+		}
+
+		if limit := c.Query("limit"); limit != "" {
+			params.Limit, err = strconv.Atoi(limit)
+			if err != nil || params.Limit <= 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+				return
+			}
+		}
+
+		page, err := linkService.ListLinks(params)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidCursor) || errors.Is(err, services.ErrInvalidListFilter) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			log.Printf("Error listing links: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		links := make([]gin.H, len(page.Links))
+		for i := range page.Links {
+			links[i] = linkSummary(&page.Links[i].Link, page.Links[i].ClickCount)
+		}
+
+		var nextCursor *string
+		if page.NextCursor != "" {
+			nextCursor = &page.NextCursor
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"links":       links,
+			"next_cursor": nextCursor,
+		})
+	}
+}
+
+// linkSummary construit la représentation JSON d'un lien utilisée dans les listes.
+func linkSummary(link *models.Link, totalClicks int) gin.H {
+	return gin.H{
+		"short_code":         link.ShortCode,
+		"domain":             link.Domain,
+		"long_url":           link.LongURL,
+		"full_short_url":     fullShortURL(link),
+		"created_at":         link.CreatedAt,
+		"total_clicks":       totalClicks,
+		"expires_at":         link.ExpiresAt,
+		"not_before":         link.NotBefore,
+		"max_clicks":         link.MaxClicks,
+		"password_protected": link.IsProtected(),
+		"disabled":           link.Disabled,
+		"flagged":            link.IsFlagged(),
+		"ios_url":            link.IOSURL,
+		"android_url":        link.AndroidURL,
+		"desktop_url":        link.DesktopURL,
+		"country_urls":       link.CountryURLs,
+		"variants":           link.Variants,
+		"sticky_variants":    link.StickyVariants,
+		"forward_query":      link.ForwardQuery,
+		"utm":                link.UTM,
+		"metadata":           link.Metadata,
+	}
+}
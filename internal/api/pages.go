@@ -0,0 +1,174 @@
+package api
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unlockPageTemplate est la page intermédiaire affichée avant la redirection d'un lien protégé.
+var unlockPageTemplate = template.Must(template.New("unlock").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Protected link</title>
+<style>
+body { font-family: sans-serif; max-width: 24rem; margin: 4rem auto; padding: 0 1rem; }
+input, button { font-size: 1rem; padding: .5rem; width: 100%; box-sizing: border-box; margin-top: .5rem; }
+.error { color: #b00020; }
+</style>
+</head>
+<body>
+<h1>Protected link</h1>
+<p>This short link is protected. Enter the password to continue.</p>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+<form method="post" action="{{.Action}}">
+<input type="password" name="password" placeholder="Password" autocomplete="current-password" required autofocus>
+<button type="submit">Unlock</button>
+</form>
+</body>
+</html>
+`))
+
+// previewPageTemplate est la page d'aperçu affichée pour /<code>+ : elle montre la destination sans y rediriger.
+var previewPageTemplate = template.Must(template.New("preview").Funcs(template.FuncMap{
+	"deref": func(b *bool) bool { return *b },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Link preview</title>
+<style>
+body { font-family: sans-serif; max-width: 36rem; margin: 4rem auto; padding: 0 1rem; }
+dt { font-weight: bold; margin-top: .75rem; }
+dd { margin: .25rem 0 0; overflow-wrap: anywhere; }
+.warning { color: #b00020; }
+</style>
+</head>
+<body>
+<h1>Link preview</h1>
+<p>{{.ShortURL}} leads to:</p>
+<dl>
+{{if .Title}}<dt>Page title</dt><dd>{{.Title}}</dd>{{end}}
+<dt>Destination</dt><dd>{{.LongURL}}</dd>
+<dt>Destination domain</dt><dd>{{.DestinationDomain}}</dd>
+<dt>Created</dt><dd>{{.CreatedAt}}</dd>
+<dt>Total clicks</dt><dd>{{.TotalClicks}}</dd>
+<dt>Last check</dt><dd>{{if not .Reachable}}Not checked yet{{else if deref .Reachable}}Reachable{{else}}<span class="warning">Unreachable</span>{{end}}</dd>
+</dl>
+{{if eq .Status "active"}}<p><a href="{{.ShortURL}}">Continue to the destination</a></p>
+{{else if eq .Status "flagged"}}<p class="warning">This destination has been reported as a phishing or malware site.</p>
+{{else}}<p class="warning">This short link is no longer active ({{.Status}}).</p>{{end}}
+</body>
+</html>
+`))
+
+// threatWarningPageTemplate est la page affichée à la place de la redirection d'un lien dont une destination
+// figure dans un flux de menaces. La destination est montrée en texte seul, sans lien cliquable.
+var threatWarningPageTemplate = template.Must(template.New("threat-warning").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Dangerous link</title>
+<style>
+body { font-family: sans-serif; max-width: 36rem; margin: 4rem auto; padding: 0 1rem; }
+h1 { color: #b00020; }
+code { overflow-wrap: anywhere; }
+</style>
+</head>
+<body>
+<h1>Warning: dangerous link</h1>
+<p>The destination of {{.ShortURL}} has been reported as a phishing or malware site, so we stopped redirecting to it.</p>
+<p>Destination: <code>{{.LongURL}}</code></p>
+<p>Reported on {{.FlaggedAt}}. If you trust this site, you can copy the address above at your own risk.</p>
+</body>
+</html>
+`))
+
+// threatWarningPageData contient les valeurs injectées dans threatWarningPageTemplate.
+type threatWarningPageData struct {
+	ShortURL  string
+	LongURL   string
+	FlaggedAt string
+}
+
+// metaRefreshPageTemplate redirige le visiteur côté navigateur, pour les liens en mode meta-refresh.
+var metaRefreshPageTemplate = template.Must(template.New("meta-refresh").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<meta http-equiv="refresh" content="0; url={{.URL}}">
+<title>Redirecting…</title>
+<style>
+body { font-family: sans-serif; max-width: 36rem; margin: 4rem auto; padding: 0 1rem; overflow-wrap: anywhere; }
+</style>
+</head>
+<body>
+<p>Redirecting to <a href="{{.URL}}">{{.URL}}</a>…</p>
+</body>
+</html>
+`))
+
+// metaRefreshPageData contient les valeurs injectées dans metaRefreshPageTemplate.
+type metaRefreshPageData struct {
+	URL string // Destination du visiteur
+}
+
+// previewPageData contient les valeurs injectées dans previewPageTemplate.
+type previewPageData struct {
+	ShortURL          string
+	LongURL           string
+	DestinationDomain string
+	CreatedAt         string
+	TotalClicks       int
+	Reachable         *bool  // nil si le moniteur n'a pas encore vérifié la destination
+	Status            string // "active", "flagged" ou la raison pour laquelle le lien ne redirige plus
+	Title             string
+}
+
+// unlockPageData contient les valeurs injectées dans unlockPageTemplate.
+type unlockPageData struct {
+	Action string // URL vers laquelle le formulaire est soumis
+	Error  string // Message d'erreur affiché après un échec
+}
+
+// renderHTML exécute un template HTML et l'écrit dans la réponse avec le code HTTP donné.
+func renderHTML(c *gin.Context, status int, tmpl *template.Template, data any) {
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Header("Cache-Control", "no-store")
+	c.Status(status)
+	if err := tmpl.Execute(c.Writer, data); err != nil {
+		log.Printf("Error rendering %s page: %v", tmpl.Name(), err)
+	}
+}
+
+// renderThreatWarning affiche l'avertissement d'un lien signalé par un flux de menaces.
+func renderThreatWarning(c *gin.Context, link *models.Link) {
+	c.Header("X-Robots-Tag", "noindex")
+	renderHTML(c, http.StatusOK, threatWarningPageTemplate, threatWarningPageData{
+		ShortURL:  fullShortURL(link),
+		LongURL:   link.LongURL,
+		FlaggedAt: link.FlaggedAt.UTC().Format(time.RFC1123),
+	})
+}
+
+// renderUnlockPage affiche le formulaire de mot de passe d'un lien protégé.
+func renderUnlockPage(c *gin.Context, status int, errorMessage string) {
+	renderHTML(c, status, unlockPageTemplate, unlockPageData{
+		Action: unlockAction(c.Request.URL),
+		Error:  errorMessage,
+	})
+}
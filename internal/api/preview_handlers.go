@@ -0,0 +1,128 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/protection"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// previewSuffix est le suffixe ajouté au code court pour afficher l'aperçu au lieu de rediriger.
+const previewSuffix = "+"
+
+// ReachabilityReader donne le dernier état d'accessibilité connu de la destination d'un lien.
+// Implémentée par monitor.UrlMonitor.
+type ReachabilityReader interface {
+	State(linkID uint) (accessible bool, known bool)
+}
+
+// isPreviewRequest indique si la requête demande l'aperçu d'un lien : /<code>+ ou ?preview=1.
+func isPreviewRequest(c *gin.Context) bool {
+	return strings.HasSuffix(c.Param("shortCode"), previewSuffix) || c.Query("preview") == "1"
+}
+
+// withPreview aiguille les demandes d'aperçu vers 'preview' et les autres vers 'next'.
+func withPreview(preview, next gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if isPreviewRequest(c) {
+			preview(c)
+			return
+		}
+		next(c)
+	}
+}
+
+// PreviewHandler affiche où mène un lien sans rediriger, en HTML ou en JSON selon l'en-tête Accept
+// (ou ?format=json). Un aperçu n'est jamais compté comme un clic. La destination d'un lien protégé
+// reste cachée tant que le mot de passe n'a pas été saisi.
+func PreviewHandler(linkService *services.LinkService, guard *protection.Guard, reachability ReachabilityReader) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shortCode := strings.TrimSuffix(c.Param("shortCode"), previewSuffix)
+
+		link, err := linkService.GetLinkForRedirect(hostDomain(c), shortCode)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Short link not found"})
+				return
+			}
+			log.Printf("Error retrieving link for %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		// Un lien supprimé n'a plus rien à montrer ; les autres états sont affichés dans l'aperçu.
+		availability := linkService.CheckAvailability(link)
+		if errors.Is(availability, services.ErrLinkDeleted) {
+			respondLinkUnavailable(c, availability)
+			return
+		}
+
+		if link.IsProtected() && !hasUnlockCookie(c, guard, link) {
+			renderUnlockPage(c, http.StatusUnauthorized, "")
+			return
+		}
+
+		totalClicks, err := linkService.CountLinkClicks(link)
+		if err != nil {
+			log.Printf("Error counting clicks for %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		status := "active"
+		if _, reason, _, unavailable := linkUnavailability(availability); unavailable {
+			status = reason
+		} else if link.IsFlagged() {
+			status = "flagged"
+		}
+
+		// nil tant que le moniteur n'a pas encore vérifié la destination.
+		var reachable *bool
+		if accessible, known := reachability.State(link.ID); known {
+			reachable = &accessible
+		}
+
+		var destinationDomain string
+		if u, err := url.Parse(link.LongURL); err == nil {
+			destinationDomain = u.Hostname()
+		}
+
+		shortURL := fullShortURL(link)
+
+		c.Header("X-Robots-Tag", "noindex")
+		if c.Query("format") == "json" || c.NegotiateFormat(gin.MIMEHTML, gin.MIMEJSON) == gin.MIMEJSON {
+			c.Header("Cache-Control", "no-store")
+			c.JSON(http.StatusOK, gin.H{
+				"short_code":         link.ShortCode,
+				"domain":             link.Domain,
+				"full_short_url":     shortURL,
+				"long_url":           link.LongURL,
+				"destination_domain": destinationDomain,
+				"created_at":         link.CreatedAt,
+				"total_clicks":       totalClicks,
+				"reachable":          reachable,
+				"status":             status,
+				"metadata":           link.Metadata,
+			})
+			return
+		}
+
+		renderHTML(c, http.StatusOK, previewPageTemplate, previewPageData{
+			ShortURL:          shortURL,
+			LongURL:           link.LongURL,
+			DestinationDomain: destinationDomain,
+			CreatedAt:         link.CreatedAt.UTC().Format(time.RFC1123),
+			TotalClicks:       totalClicks,
+			Reachable:         reachable,
+			Status:            status,
+			Title:             link.Metadata.Title,
+		})
+	}
+}
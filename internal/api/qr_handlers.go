@@ -0,0 +1,92 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/axellelanca/urlshortener/internal/qr"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// qrCacheMaxAge est la durée (en secondes) pendant laquelle un QR code peut être mis en cache :
+// il ne dépend que du code court et de l'URL de base.
+const qrCacheMaxAge = 24 * 60 * 60
+
+// GetLinkQRHandler génère le QR code de l'URL courte complète d'un lien (la même que 'full_short_url').
+// Paramètres optionnels : format (png ou svg), size (pixels), level (L, M, Q ou H), margin (modules),
+// fg et bg (couleurs hexadécimales, ex: 1a1a1a ou %23ffffff00 pour un fond transparent).
+func GetLinkQRHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shortCode := c.Param("shortCode")
+		domain, ok := apiDomain(c)
+		if !ok {
+			return
+		}
+
+		opts, err := qrOptionsFromQuery(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		link, err := linkService.GetLinkByShortCode(domain, shortCode)
+		if err != nil {
+			respondLinkLookupError(c, shortCode, err)
+			return
+		}
+
+		var image bytes.Buffer
+		if err := qr.Write(&image, fullShortURL(link), opts); err != nil {
+			if errors.Is(err, qr.ErrInvalidOptions) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			log.Printf("Error generating QR code for %s: %v", shortCode, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", qrCacheMaxAge))
+		c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s.%s"`, link.ShortCode, opts.Format))
+		c.Data(http.StatusOK, opts.Format.ContentType(), image.Bytes())
+	}
+}
+
+// qrOptionsFromQuery lit les options de rendu du QR code dans la query string.
+// Les paramètres absents conservent les valeurs de qr.DefaultOptions.
+func qrOptionsFromQuery(c *gin.Context) (qr.Options, error) {
+	opts := qr.DefaultOptions()
+	var err error
+
+	if value := c.Query("format"); value != "" {
+		if opts.Format, err = qr.ParseFormat(value); err != nil {
+			return opts, err
+		}
+	}
+	for name, dest := range map[string]*int{"size": &opts.Size, "margin": &opts.Margin} {
+		if value := c.Query(name); value != "" {
+			if *dest, err = strconv.Atoi(value); err != nil {
+				return opts, fmt.Errorf("%w: %s must be an integer", qr.ErrInvalidOptions, name)
+			}
+		}
+	}
+	if value := c.Query("level"); value != "" {
+		opts.Level = value
+	}
+	if value := c.Query("fg"); value != "" {
+		if opts.Foreground, err = qr.ParseColor(value); err != nil {
+			return opts, err
+		}
+	}
+	if value := c.Query("bg"); value != "" {
+		if opts.Background, err = qr.ParseColor(value); err != nil {
+			return opts, err
+		}
+	}
+	return opts, nil
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// effectiveRedirectType retourne le type de redirection du lien, ou le type par défaut configuré sur le serveur.
+func effectiveRedirectType(link *models.Link) models.RedirectType {
+	if link.RedirectType != "" {
+		return link.RedirectType
+	}
+	return models.RedirectType(cmd.Cfg.Server.DefaultRedirectType)
+}
+
+// respondRedirect envoie le visiteur vers 'target' selon le type de redirection du lien.
+//
+// Les redirections temporaires (302, 307) et les pages meta refresh ne sont jamais mises en cache,
+// afin que chaque visite repasse par le service et soit comptée. Les redirections permanentes (301, 308)
+// sont mises en cache pendant server.permanent_redirect_max_age secondes au plus, sans dépasser
+// l'expiration du lien : un cache sans limite empêcherait de corriger ou de désactiver le lien.
+// Elles ne sont autorisées que dans le cache du navigateur si la destination dépend du visiteur,
+// et jamais mises en cache pour un lien dont les limites sont vérifiées à chaque visite (voir HasServerSideLimits).
+func respondRedirect(c *gin.Context, link *models.Link, target string) {
+	redirectType := effectiveRedirectType(link)
+
+	if !redirectType.IsPermanent() {
+		if redirectType == models.RedirectMetaRefresh {
+			// renderHTML interdit déjà toute mise en cache de la page.
+			renderHTML(c, redirectType.StatusCode(), metaRefreshPageTemplate, metaRefreshPageData{URL: target})
+			return
+		}
+		c.Header("Cache-Control", "private, no-cache")
+		c.Redirect(redirectType.StatusCode(), target)
+		return
+	}
+
+	if link.HasServerSideLimits() {
+		c.Header("Cache-Control", "no-store")
+		c.Redirect(redirectType.StatusCode(), target)
+		return
+	}
+
+	maxAge := cmd.Cfg.Server.PermanentRedirectMaxAge
+	if link.ExpiresAt != nil {
+		if untilExpiry := int(time.Until(*link.ExpiresAt).Seconds()); untilExpiry < maxAge {
+			maxAge = max(untilExpiry, 0)
+		}
+	}
+	scope := "public"
+	if link.VariesPerVisitor() {
+		scope = "private"
+	}
+	c.Header("Cache-Control", fmt.Sprintf("%s, max-age=%d", scope, maxAge))
+	c.Redirect(redirectType.StatusCode(), target)
+}
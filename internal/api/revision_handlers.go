@@ -0,0 +1,134 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// defaultAPIActor est l'auteur enregistré dans l'historique quand le client API ne s'identifie pas.
+const defaultAPIActor = "api"
+
+// UpdateLinkRequest représente le corps de la requête JSON pour modifier la destination d'un lien.
+type UpdateLinkRequest struct {
+	LongURL   string `json:"long_url" binding:"required,url"` // Nouvelle destination
+	ChangedBy string `json:"changed_by" binding:"max=100"`    // Auteur du changement, conservé dans l'historique
+}
+
+// RollbackLinkRequest représente le corps de la requête JSON pour annuler une modification.
+type RollbackLinkRequest struct {
+	RevisionID uint   `json:"revision_id"`                  // Révision à annuler (0 = la plus récente)
+	ChangedBy  string `json:"changed_by" binding:"max=100"` // Auteur du retour arrière
+}
+
+// UpdateLinkHandler gère la modification de la destination d'un lien existant.
+func UpdateLinkHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shortCode := c.Param("shortCode")
+		domain, ok := apiDomain(c)
+		if !ok {
+			return
+		}
+
+		var req UpdateLinkRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		link, err := linkService.UpdateLinkDestination(domain, shortCode, req.LongURL, actorOrDefault(req.ChangedBy))
+		if err != nil {
+			respondLinkLookupError(c, shortCode, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"short_code": link.ShortCode,
+			"long_url":   link.LongURL,
+		})
+	}
+}
+
+// GetLinkRevisionsHandler retourne l'historique des destinations d'un lien.
+func GetLinkRevisionsHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shortCode := c.Param("shortCode")
+		domain, ok := apiDomain(c)
+		if !ok {
+			return
+		}
+
+		link, revisions, err := linkService.GetLinkRevisions(domain, shortCode)
+		if err != nil {
+			respondLinkLookupError(c, shortCode, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"short_code": link.ShortCode,
+			"long_url":   link.LongURL,
+			"revisions":  revisions,
+		})
+	}
+}
+
+// RollbackLinkHandler restaure la destination qui précédait une révision donnée.
+func RollbackLinkHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shortCode := c.Param("shortCode")
+		domain, ok := apiDomain(c)
+		if !ok {
+			return
+		}
+
+		// Le corps est optionnel : sans corps, la dernière modification est annulée.
+		var req RollbackLinkRequest
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		link, err := linkService.RollbackLink(domain, shortCode, req.RevisionID, actorOrDefault(req.ChangedBy))
+		if err != nil {
+			if errors.Is(err, services.ErrNoRevision) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			respondLinkLookupError(c, shortCode, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"short_code": link.ShortCode,
+			"long_url":   link.LongURL,
+		})
+	}
+}
+
+// respondLinkLookupError traduit une erreur de récupération ou de mise à jour d'un lien en réponse HTTP.
+func respondLinkLookupError(c *gin.Context, shortCode string, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Short link not found"})
+		return
+	}
+	if errors.Is(err, services.ErrInvalidURL) || errors.Is(err, services.ErrUnsafeDestination) {
+		c.JSON(http.StatusBadRequest, errorBody(err))
+		return
+	}
+	log.Printf("Error handling link %s: %v", shortCode, err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+}
+
+// actorOrDefault retourne l'auteur fourni par le client, ou l'auteur API par défaut.
+func actorOrDefault(actor string) string {
+	if actor == "" {
+		return defaultAPIActor
+	}
+	return actor
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// UpdateLinkRulesRequest représente le corps de la requête JSON remplaçant les règles d'un lien.
+type UpdateLinkRulesRequest struct {
+	Rules []models.RedirectRule `json:"rules"` // Liste complète des règles ; vide pour toutes les supprimer
+}
+
+// DryRunRequest décrit une requête fictive sur laquelle évaluer la destination d'un lien.
+// Tous les champs sont optionnels.
+type DryRunRequest struct {
+	UserAgent string            `json:"user_agent"` // Repris dans les en-têtes s'il n'y figure pas déjà
+	IP        string            `json:"ip"`         // Adresse IP du visiteur
+	Headers   map[string]string `json:"headers"`    // En-têtes de la requête (ex: {"Accept-Language": "fr-FR"})
+	Query     string            `json:"query"`      // Query string de l'URL courte, sans '?' (ex: "ref=newsletter")
+	At        *time.Time        `json:"at"`         // Instant de la visite (RFC 3339), maintenant par défaut
+	Variant   string            `json:"variant"`    // Variante A/B déjà attribuée (cookie)
+}
+
+// UpdateLinkRulesHandler remplace les règles de redirection d'un lien, validées avant enregistrement.
+func UpdateLinkRulesHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shortCode := c.Param("shortCode")
+		domain, ok := apiDomain(c)
+		if !ok {
+			return
+		}
+
+		var req UpdateLinkRulesRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		link, err := linkService.SetLinkRules(domain, shortCode, req.Rules)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidRules) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			respondLinkLookupError(c, shortCode, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"short_code": link.ShortCode,
+			"rules":      link.Rules,
+		})
+	}
+}
+
+// DryRunRulesHandler évalue la destination d'un lien pour une requête fictive, sans redirection
+// ni enregistrement de clic. La disponibilité du lien (expiration, budget...) n'est pas vérifiée.
+func DryRunRulesHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shortCode := c.Param("shortCode")
+		domain, ok := apiDomain(c)
+		if !ok {
+			return
+		}
+
+		var req DryRunRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		query, err := url.ParseQuery(req.Query)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query: " + err.Error()})
+			return
+		}
+
+		link, err := linkService.GetLinkByShortCode(domain, shortCode)
+		if err != nil {
+			respondLinkLookupError(c, shortCode, err)
+			return
+		}
+
+		header := make(http.Header, len(req.Headers)+1)
+		for name, value := range req.Headers {
+			header.Set(name, value)
+		}
+		if req.UserAgent != "" && header.Get("User-Agent") == "" {
+			header.Set("User-Agent", req.UserAgent)
+		}
+		visit := services.Visit{
+			UserAgent: header.Get("User-Agent"),
+			Header:    header,
+			IP:        req.IP,
+			Query:     query,
+			Variant:   req.Variant,
+			At:        time.Now().UTC(),
+		}
+		if req.At != nil {
+			visit.At = req.At.UTC()
+		}
+
+		destination := linkService.ResolveDestination(link, visit)
+
+		// La règle appliquée vaut null si la destination ne provient pas d'une règle.
+		var rule gin.H
+		if destination.Rule > 0 {
+			rule = gin.H{
+				"position": destination.Rule,
+				"name":     link.Rules[destination.Rule-1].Name,
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"short_code":  link.ShortCode,
+			"destination": destination.URL,
+			"rule":        rule,
+			"platform":    destination.Platform,
+			"country":     destination.Country,
+			"variant":     destination.Variant,
+			"at":          visit.At,
+		})
+	}
+}
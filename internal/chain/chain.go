@@ -0,0 +1,185 @@
+// Package chain empêche qu'un lien renvoie vers le service lui-même : directement (une URL courte
+// raccourcie à nouveau) ou au bout d'une chaîne de raccourcisseurs d'URL qui finit par boucler.
+// Les redirections des raccourcisseurs connus peuvent être suivies à la création d'un lien, pour
+// enregistrer la destination finale plutôt qu'un intermédiaire.
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/safety"
+	"github.com/axellelanca/urlshortener/internal/urlnorm"
+)
+
+// DefaultTimeout borne chaque requête vers un raccourcisseur quand Options.Client est nil.
+const DefaultTimeout = 5 * time.Second
+
+// ErrInvalidOptions est retournée par New pour une configuration invalide.
+var ErrInvalidOptions = errors.New("invalid redirect chain options")
+
+// Options configure un Follower.
+// OwnBaseURLs contient les URL de base du service (domaine par défaut et domaines de marque) : une destination
+// sur l'un de ces hôtes, port compris, est refusée. Shorteners liste les hôtes des raccourcisseurs dont
+// les redirections sont suivies ("bit.ly" couvre aussi ses sous-domaines, "127.0.0.1:8081" ne désigne que ce port),
+// au plus MaxHops fois (0 = aucune requête, seule la vérification des hôtes du service a lieu).
+type Options struct {
+	OwnBaseURLs []string
+	Shorteners  []string
+	MaxHops     int
+	Client      *http.Client // nil = client standard avec DefaultTimeout ; ses redirections automatiques sont ignorées
+}
+
+// Follower vérifie et suit les chaînes de redirection. Un Follower nil accepte tout sans rien suivre.
+type Follower struct {
+	own        map[string]bool
+	shorteners []string
+	maxHops    int
+	client     *http.Client
+}
+
+// New valide les options et construit le Follower correspondant.
+func New(opts Options) (*Follower, error) {
+	if opts.MaxHops < 0 {
+		return nil, fmt.Errorf("%w: max hops must be positive", ErrInvalidOptions)
+	}
+	f := &Follower{own: make(map[string]bool, len(opts.OwnBaseURLs)), maxHops: opts.MaxHops}
+	for _, base := range opts.OwnBaseURLs {
+		host := hostOf(base)
+		if host == "" {
+			return nil, fmt.Errorf("%w: invalid base URL %q", ErrInvalidOptions, base)
+		}
+		f.own[host] = true
+	}
+	for _, pattern := range opts.Shorteners {
+		pattern = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(pattern)), ".")
+		if pattern == "" || strings.ContainsAny(pattern, "/* ") {
+			return nil, fmt.Errorf("%w: invalid shortener host %q", ErrInvalidOptions, pattern)
+		}
+		f.shorteners = append(f.shorteners, pattern)
+	}
+
+	// Chaque redirection est lue et vérifiée ici plutôt que suivie par le client.
+	client := &http.Client{Timeout: DefaultTimeout}
+	if opts.Client != nil {
+		copied := *opts.Client
+		client = &copied
+	}
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	f.client = client
+	return f, nil
+}
+
+// hostOf retourne l'hôte d'une URL sous sa forme canonique (minuscules, sans port par défaut), ou "".
+func hostOf(rawURL string) string {
+	canonical, err := urlnorm.Canonicalize(rawURL)
+	if err != nil {
+		return ""
+	}
+	u, err := url.Parse(canonical)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// IsOwnURL indique si l'URL désigne l'un des hôtes du service.
+func (f *Follower) IsOwnURL(rawURL string) bool {
+	if f == nil {
+		return false
+	}
+	host := hostOf(rawURL)
+	return host != "" && f.own[host]
+}
+
+// isShortener indique si les redirections de l'URL doivent être suivies.
+func (f *Follower) isShortener(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Host), ".")
+	hostname := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	for _, pattern := range f.shorteners {
+		if pattern == host || pattern == hostname || strings.HasSuffix(hostname, "."+pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check refuse une URL qui désigne le service lui-même, sans aucune requête réseau.
+func (f *Follower) Check(rawURL string) *safety.Violation {
+	if f.IsOwnURL(rawURL) {
+		return &safety.Violation{Reason: safety.ReasonSelfReference, URL: rawURL, Detail: fmt.Sprintf("%s points back at this service", rawURL)}
+	}
+	return nil
+}
+
+// Resolve suit les redirections des raccourcisseurs connus à partir de rawURL et retourne la destination finale,
+// c'est-à-dire la première URL qui n'est pas sur un raccourcisseur connu. La chaîne est refusée si elle revient
+// vers le service ou repasse par une URL déjà visitée (redirect_loop), ou si elle compte plus de MaxHops
+// redirections (too_many_redirects). Un raccourcisseur injoignable, ou qui ne redirige pas, termine la chaîne.
+func (f *Follower) Resolve(ctx context.Context, rawURL string) (string, *safety.Violation) {
+	if violation := f.Check(rawURL); violation != nil {
+		return "", violation
+	}
+	if f == nil || f.maxHops == 0 {
+		return rawURL, nil
+	}
+
+	current := rawURL
+	seen := map[string]bool{}
+	for hops := 0; f.isShortener(current); hops++ {
+		key := current
+		if canonical, err := urlnorm.Canonicalize(current); err == nil {
+			key = canonical
+		}
+		if seen[key] {
+			return "", &safety.Violation{Reason: safety.ReasonRedirectLoop, URL: rawURL, Detail: fmt.Sprintf("redirect chain loops back to %s", current)}
+		}
+		seen[key] = true
+		if hops == f.maxHops {
+			return "", &safety.Violation{Reason: safety.ReasonTooManyRedirects, URL: rawURL, Detail: fmt.Sprintf("redirect chain is longer than %d hops", f.maxHops)}
+		}
+
+		next, ok := f.next(ctx, current)
+		if !ok {
+			break
+		}
+		if f.IsOwnURL(next) {
+			return "", &safety.Violation{Reason: safety.ReasonRedirectLoop, URL: rawURL, Detail: fmt.Sprintf("redirect chain leads back to this service (%s)", next)}
+		}
+		current = next
+	}
+	return current, nil
+}
+
+// next retourne la cible de la redirection renvoyée par l'URL, ou false si elle ne redirige pas ou ne répond pas.
+func (f *Follower) next(ctx context.Context, rawURL string) (string, bool) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", false
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", false
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 300 || resp.StatusCode > 399 {
+		return "", false
+	}
+	location, err := resp.Location()
+	if err != nil {
+		return "", false
+	}
+	return location.String(), true
+}
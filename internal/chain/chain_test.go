@@ -0,0 +1,179 @@
+package chain
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/axellelanca/urlshortener/internal/safety"
+)
+
+// redirector démarre un raccourcisseur de test qui redirige chaque requête vers *target.
+func redirector(t *testing.T, target *string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, *target, http.StatusMovedPermanently)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// host retourne l'hôte (port compris) d'un serveur de test.
+func host(server *httptest.Server) string {
+	return server.Listener.Addr().String()
+}
+
+func TestResolve(t *testing.T) {
+	var targetA, targetB, targetSelf string
+	shortenerA := redirector(t, &targetA)
+	shortenerB := redirector(t, &targetB)
+	self := redirector(t, &targetSelf)
+	final := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(final.Close)
+
+	tests := []struct {
+		name       string
+		maxHops    int
+		targetA    string
+		targetB    string
+		start      string
+		wantURL    string
+		wantReason safety.Reason
+	}{
+		{
+			name:       "direct link to the service",
+			maxHops:    5,
+			start:      self.URL + "/abc",
+			wantReason: safety.ReasonSelfReference,
+		},
+		{
+			name:    "chain A to B to final destination",
+			maxHops: 5,
+			targetA: shortenerB.URL + "/b",
+			targetB: final.URL + "/end?q=1",
+			start:   shortenerA.URL + "/a",
+			wantURL: final.URL + "/end?q=1",
+		},
+		{
+			name:       "hop back to the service",
+			maxHops:    5,
+			targetA:    shortenerB.URL + "/b",
+			targetB:    self.URL + "/abc",
+			start:      shortenerA.URL + "/a",
+			wantReason: safety.ReasonRedirectLoop,
+		},
+		{
+			name:       "A to B to A loop",
+			maxHops:    5,
+			targetA:    shortenerB.URL + "/b",
+			targetB:    shortenerA.URL + "/a",
+			start:      shortenerA.URL + "/a",
+			wantReason: safety.ReasonRedirectLoop,
+		},
+		{
+			name:       "relative redirect to itself",
+			maxHops:    5,
+			targetA:    "/a",
+			start:      shortenerA.URL + "/a",
+			wantReason: safety.ReasonRedirectLoop,
+		},
+		{
+			name:       "too many hops",
+			maxHops:    1,
+			targetA:    shortenerB.URL + "/b",
+			targetB:    final.URL + "/end",
+			start:      shortenerA.URL + "/a",
+			wantReason: safety.ReasonTooManyRedirects,
+		},
+		{
+			name:    "following disabled",
+			maxHops: 0,
+			targetA: final.URL + "/end",
+			start:   shortenerA.URL + "/a",
+			wantURL: shortenerA.URL + "/a",
+		},
+		{
+			name:    "destination outside the shorteners",
+			maxHops: 5,
+			start:   final.URL + "/page",
+			wantURL: final.URL + "/page",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targetA, targetB = tt.targetA, tt.targetB
+			follower, err := New(Options{
+				OwnBaseURLs: []string{self.URL},
+				Shorteners:  []string{host(shortenerA), host(shortenerB)},
+				MaxHops:     tt.maxHops,
+			})
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+
+			got, violation := follower.Resolve(context.Background(), tt.start)
+			if tt.wantReason != "" {
+				if violation == nil || violation.Reason != tt.wantReason {
+					t.Fatalf("Resolve(%q) = %q, %v; want reason %s", tt.start, got, violation, tt.wantReason)
+				}
+				return
+			}
+			if violation != nil || got != tt.wantURL {
+				t.Fatalf("Resolve(%q) = %q, %v; want %q", tt.start, got, violation, tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestResolveUnreachableShortener(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	follower, err := New(Options{Shorteners: []string{host(down)}, MaxHops: 3})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	start := down.URL + "/x"
+	if got, violation := follower.Resolve(context.Background(), start); violation != nil || got != start {
+		t.Fatalf("Resolve(%q) = %q, %v; want the URL unchanged", start, got, violation)
+	}
+}
+
+func TestIsOwnURL(t *testing.T) {
+	follower, err := New(Options{OwnBaseURLs: []string{"https://sho.rt", "http://localhost:8080", "https://go.brand.example"}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://sho.rt/abc", true},
+		{"https://SHO.RT:443/abc", true},
+		{"http://sho.rt/abc", true},
+		{"http://localhost:8080/abc", true},
+		{"http://localhost:8081/abc", false},
+		{"https://go.brand.example/x", true},
+		{"https://sub.sho.rt/abc", false},
+		{"https://example.com/https://sho.rt", false},
+	}
+	for _, tt := range tests {
+		if got := follower.IsOwnURL(tt.url); got != tt.want {
+			t.Errorf("IsOwnURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestNewRejectsInvalidOptions(t *testing.T) {
+	for _, opts := range []Options{
+		{MaxHops: -1},
+		{OwnBaseURLs: []string{"not a url"}},
+		{Shorteners: []string{"*.bit.ly"}},
+		{Shorteners: []string{""}},
+	} {
+		if _, err := New(opts); err == nil {
+			t.Errorf("New(%+v) succeeded, want an error", opts)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+// Package codegen fournit les stratégies de génération des codes courts : aléatoire sur un alphabet
+// configurable, compteur séquentiel encodé façon hashids, mots prononçables, ou alphabet sans
+// caractères ambigus. La stratégie est choisie dans la configuration (section codes).
+package codegen
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Generator produit des codes courts candidats. L'unicité n'est pas garantie : l'appelant vérifie
+// la disponibilité du code et en redemande un autre en cas de collision.
+type Generator interface {
+	Generate() (string, error)
+}
+
+// Sequence fournit les valeurs successives d'un compteur persistant, partagé entre les processus.
+type Sequence interface {
+	Next() (uint64, error)
+}
+
+// Keyspace est implémenté par les générateurs dont l'espace de codes d'une longueur donnée est connu.
+type Keyspace interface {
+	Length() int                 // Longueur des codes générés actuellement
+	Capacity(length int) float64 // Nombre de codes distincts que le générateur peut produire à cette longueur
+}
+
+// Resizable est implémenté par les générateurs dont la longueur des codes peut être augmentée à chaud,
+// quand l'espace de codes à la longueur actuelle devient trop encombré.
+type Resizable interface {
+	Keyspace
+	SetLength(length int) error
+}
+
+// SequenceName est le nom du compteur persistant de la stratégie sequential.
+const SequenceName = "short_codes"
+
+// Stratégies de génération disponibles.
+const (
+	StrategyRandom      = "random"
+	StrategySequential  = "sequential"
+	StrategyWords       = "words"
+	StrategyUnambiguous = "unambiguous"
+)
+
+// Alphabets prédéfinis. UnambiguousAlphabet exclut les caractères confondus à l'oral ou à la lecture :
+// 0/O/o, 1/l/I/i.
+const (
+	Base62Alphabet      = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	UnambiguousAlphabet = "abcdefghjkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+// Bornes des options de génération. Les codes doivent rester des alias valides (32 caractères au plus).
+const (
+	DefaultLength     = 6
+	MinLength         = 4
+	MaxLength         = 32
+	MinAlphabetLength = 16
+)
+
+// ErrInvalidOptions est retournée quand la stratégie ou ses options sont invalides.
+var ErrInvalidOptions = errors.New("invalid code generator options")
+
+// Options regroupe les paramètres des stratégies. Les options sans objet pour une stratégie sont ignorées.
+type Options struct {
+	Length   int    // Longueur des codes (random, unambiguous) ou longueur minimale (sequential)
+	Alphabet string // Alphabet des codes (random, sequential) ; vide = base62
+	Salt     string // Sel du mélange de l'alphabet (sequential)
+}
+
+// NewDefault retourne le générateur par défaut : codes aléatoires base62 de DefaultLength caractères.
+func NewDefault() Generator {
+	g, _ := NewRandom(Base62Alphabet, DefaultLength)
+	return g
+}
+
+// New construit le générateur de la stratégie demandée. 'seq' n'est utilisé que par la stratégie sequential.
+func New(strategy string, opts Options, seq Sequence) (Generator, error) {
+	switch strategy {
+	case StrategyRandom, "":
+		return NewRandom(opts.Alphabet, opts.Length)
+	case StrategyUnambiguous:
+		return NewRandom(UnambiguousAlphabet, opts.Length)
+	case StrategySequential:
+		if seq == nil {
+			return nil, fmt.Errorf("%w: the sequential strategy needs a counter", ErrInvalidOptions)
+		}
+		return NewSequential(opts.Alphabet, opts.Salt, opts.Length, seq)
+	case StrategyWords:
+		return NewWords(), nil
+	}
+	return nil, fmt.Errorf("%w: unknown strategy '%s' (expected %s, %s, %s or %s)", ErrInvalidOptions,
+		strategy, StrategyRandom, StrategySequential, StrategyWords, StrategyUnambiguous)
+}
+
+// validateAlphabet vérifie qu'un alphabet est assez long, sans doublon, et limité aux caractères
+// autorisés dans les alias (lettres, chiffres, '-' et '_'). Un alphabet vide est remplacé par base62.
+func validateAlphabet(alphabet string) (string, error) {
+	if alphabet == "" {
+		return Base62Alphabet, nil
+	}
+	if len(alphabet) < MinAlphabetLength {
+		return "", fmt.Errorf("%w: alphabet must contain at least %d characters", ErrInvalidOptions, MinAlphabetLength)
+	}
+	for i := 0; i < len(alphabet); i++ {
+		c := alphabet[i]
+		if !isAlnum(c) && c != '-' && c != '_' {
+			return "", fmt.Errorf("%w: alphabet may only contain letters, digits, '-' and '_'", ErrInvalidOptions)
+		}
+		if strings.IndexByte(alphabet[i+1:], c) >= 0 {
+			return "", fmt.Errorf("%w: alphabet contains '%c' more than once", ErrInvalidOptions, c)
+		}
+	}
+	return alphabet, nil
+}
+
+// validateLength vérifie qu'une longueur de code est dans les bornes autorisées.
+func validateLength(length int) error {
+	if length < MinLength || length > MaxLength {
+		return fmt.Errorf("%w: length must be between %d and %d", ErrInvalidOptions, MinLength, MaxLength)
+	}
+	return nil
+}
+
+func isAlnum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
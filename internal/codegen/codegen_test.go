@@ -0,0 +1,143 @@
+package codegen
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+)
+
+// counter est un compteur en mémoire qui remplace le compteur persistant.
+type counter struct{ value uint64 }
+
+func (c *counter) Next() (uint64, error) {
+	c.value++
+	return c.value, nil
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		opts     Options
+		seq      Sequence
+		wantErr  bool
+		pattern  string
+	}{
+		{name: "default strategy", strategy: "", opts: Options{Length: 6}, pattern: `^[a-zA-Z0-9]{6}$`},
+		{name: "random", strategy: StrategyRandom, opts: Options{Length: 8}, pattern: `^[a-zA-Z0-9]{8}$`},
+		{name: "random with alphabet", strategy: StrategyRandom, opts: Options{Length: 10, Alphabet: "0123456789abcdef"}, pattern: `^[0-9a-f]{10}$`},
+		{name: "unambiguous", strategy: StrategyUnambiguous, opts: Options{Length: 12}, pattern: `^[^0Oo1lIi]{12}$`},
+		{name: "sequential", strategy: StrategySequential, opts: Options{Length: 5, Salt: "salt"}, seq: &counter{}, pattern: `^[a-zA-Z0-9]{5}$`},
+		{name: "words", strategy: StrategyWords, pattern: `^[a-z]+-[a-z]+-[1-9][0-9]$`},
+		{name: "sequential without counter", strategy: StrategySequential, opts: Options{Length: 5}, wantErr: true},
+		{name: "unknown strategy", strategy: "uuid", opts: Options{Length: 6}, wantErr: true},
+		{name: "length too short", strategy: StrategyRandom, opts: Options{Length: MinLength - 1}, wantErr: true},
+		{name: "length too long", strategy: StrategyRandom, opts: Options{Length: MaxLength + 1}, wantErr: true},
+		{name: "alphabet too short", strategy: StrategyRandom, opts: Options{Length: 6, Alphabet: "abc"}, wantErr: true},
+		{name: "alphabet with duplicates", strategy: StrategyRandom, opts: Options{Length: 6, Alphabet: "abcdefghijklmnopa"}, wantErr: true},
+		{name: "alphabet with forbidden characters", strategy: StrategySequential, opts: Options{Length: 6, Alphabet: "abcdefghijklmno/"}, seq: &counter{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := New(tt.strategy, tt.opts, tt.seq)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidOptions) {
+					t.Fatalf("New(%q, %+v) error = %v, want ErrInvalidOptions", tt.strategy, tt.opts, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New(%q, %+v): %v", tt.strategy, tt.opts, err)
+			}
+			pattern := regexp.MustCompile(tt.pattern)
+			for range 50 {
+				code, err := g.Generate()
+				if err != nil {
+					t.Fatalf("Generate: %v", err)
+				}
+				if !pattern.MatchString(code) {
+					t.Fatalf("Generate() = %q, want a code matching %s", code, tt.pattern)
+				}
+			}
+		})
+	}
+}
+
+func TestRandomSetLength(t *testing.T) {
+	g, err := NewRandom("", 6)
+	if err != nil {
+		t.Fatalf("NewRandom: %v", err)
+	}
+	if err := g.SetLength(9); err != nil {
+		t.Fatalf("SetLength(9): %v", err)
+	}
+	if code, _ := g.Generate(); len(code) != 9 {
+		t.Errorf("Generate() = %q after SetLength(9), want 9 characters", code)
+	}
+	if err := g.SetLength(MaxLength + 1); err == nil {
+		t.Errorf("SetLength(%d) succeeded, want an error", MaxLength+1)
+	}
+	if g.Length() != 9 {
+		t.Errorf("Length() = %d after a rejected SetLength, want 9", g.Length())
+	}
+	if got, want := g.Capacity(2), float64(len(Base62Alphabet)*len(Base62Alphabet)); got != want {
+		t.Errorf("Capacity(2) = %v, want %v", got, want)
+	}
+}
+
+func TestSequentialEncode(t *testing.T) {
+	g, err := NewSequential("", "secret", 4, &counter{})
+	if err != nil {
+		t.Fatalf("NewSequential: %v", err)
+	}
+
+	// Sur l'espace des codes de longueur minimale et au-delà, deux valeurs ne partagent jamais un code.
+	seen := make(map[string]uint64)
+	limit := uint64(len(Base62Alphabet)*len(Base62Alphabet)*len(Base62Alphabet)) + 1000
+	for n := uint64(0); n < limit; n++ {
+		code := g.Encode(n)
+		if len(code) < 4 {
+			t.Fatalf("Encode(%d) = %q, shorter than the minimum length", n, code)
+		}
+		if previous, dup := seen[code]; dup {
+			t.Fatalf("Encode(%d) = Encode(%d) = %q", n, previous, code)
+		}
+		seen[code] = n
+	}
+	if code := g.Encode(limit); len(code) != 5 {
+		t.Errorf("Encode(%d) = %q, want a 5-character code once 4 no longer fit", limit, code)
+	}
+
+	// Le sel change les codes ; le même sel les reproduit.
+	same, _ := NewSequential("", "secret", 4, &counter{})
+	other, _ := NewSequential("", "other", 4, &counter{})
+	if g.Encode(42) != same.Encode(42) {
+		t.Errorf("Encode(42) differs between two generators with the same salt")
+	}
+	if g.Encode(42) == other.Encode(42) {
+		t.Errorf("Encode(42) = %q for two different salts", g.Encode(42))
+	}
+
+	// Deux valeurs consécutives ne donnent pas des codes proches.
+	if a, b := g.Encode(1000), g.Encode(1001); a[1:] == b[1:] {
+		t.Errorf("Encode(1000) = %q and Encode(1001) = %q share their digits", a, b)
+	}
+}
+
+func TestSequentialGenerateUsesCounter(t *testing.T) {
+	seq := &counter{}
+	g, err := NewSequential("", "salt", 6, seq)
+	if err != nil {
+		t.Fatalf("NewSequential: %v", err)
+	}
+	code, err := g.Generate()
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if want := g.Encode(1); code != want {
+		t.Errorf("Generate() = %q, want Encode(1) = %q", code, want)
+	}
+	if seq.value != 1 {
+		t.Errorf("counter = %d after one Generate, want 1", seq.value)
+	}
+}
@@ -0,0 +1,146 @@
+package codegen
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DefaultReserved liste les mots toujours réservés : chemins utilisés par le service lui-même
+// (ou susceptibles de l'être) qu'un code court masquerait.
+var DefaultReserved = []string{
+	"api", "health", "admin", "login", "logout", "static", "assets",
+	"robots", "favicon", "metrics", "status", "www",
+}
+
+// Motifs de refus d'un code.
+const (
+	ReasonReserved = "reserved" // Le code est un mot réservé
+	ReasonBlocked  = "blocked"  // Le code contient un mot de la liste de blocage
+)
+
+// leetFold ramène chaque caractère à sa forme canonique : les chiffres et symboles utilisés pour
+// contourner un filtre (« leetspeak ») sont remplacés par la lettre qu'ils imitent, et les lettres
+// qui se confondent entre elles (i, l) partagent la même forme. Les caractères absents de la table
+// sont conservés en minuscules ; les séparateurs '-' et '_' sont ignorés.
+var leetFold = map[byte]byte{
+	'0': 'o', '1': 'i', 'l': 'i', '!': 'i', '3': 'e', '4': 'a', '@': 'a',
+	'5': 's', '$': 's', '7': 't', '8': 'b', '9': 'g', '2': 'z',
+}
+
+// Rejection explique le refus d'un code : mot réservé ou mot bloqué, et la partie du code qui correspond.
+type Rejection struct {
+	Reason string // ReasonReserved ou ReasonBlocked
+	Word   string // Mot réservé ou bloqué en cause
+	Match  string // Partie du code correspondant au mot, telle qu'écrite dans le code
+}
+
+// Error retourne l'explication du refus, en anglais comme les autres messages d'erreur de l'API.
+func (r *Rejection) Error() string {
+	if r.Reason == ReasonReserved {
+		return fmt.Sprintf("'%s' is a reserved word", r.Match)
+	}
+	return fmt.Sprintf("contains the blocked word '%s' (as '%s')", r.Word, r.Match)
+}
+
+// Filter refuse les codes réservés (comparaison exacte, insensible à la casse) et ceux contenant
+// un mot bloqué (recherche de sous-chaîne après normalisation du leetspeak et des séparateurs).
+// Un Filter est en lecture seule après sa création et peut être partagé entre goroutines.
+type Filter struct {
+	reserved map[string]bool
+	blocked  []blockedWord
+}
+
+// blockedWord est un mot bloqué et sa forme normalisée.
+type blockedWord struct {
+	word   string
+	folded string
+}
+
+// NewFilter crée un filtre. Les mots vides sont ignorés.
+func NewFilter(reserved, blocked []string) *Filter {
+	f := &Filter{reserved: make(map[string]bool, len(reserved))}
+	for _, word := range reserved {
+		if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+			f.reserved[word] = true
+		}
+	}
+	for _, word := range blocked {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if folded, _ := fold(word); folded != "" {
+			f.blocked = append(f.blocked, blockedWord{word: word, folded: folded})
+		}
+	}
+	return f
+}
+
+// LoadBlocklist lit une liste de mots bloqués : un mot par ligne, les lignes vides et celles
+// commençant par '#' sont ignorées.
+func LoadBlocklist(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	return words, scanner.Err()
+}
+
+// Check retourne la raison du refus de 'code', ou nil si le code est acceptable.
+func (f *Filter) Check(code string) *Rejection {
+	if f == nil {
+		return nil
+	}
+	if f.reserved[strings.ToLower(code)] {
+		return &Rejection{Reason: ReasonReserved, Word: strings.ToLower(code), Match: code}
+	}
+
+	folded, positions := fold(code)
+	for _, blocked := range f.blocked {
+		if i := strings.Index(folded, blocked.folded); i >= 0 {
+			start, end := positions[i], positions[i+len(blocked.folded)-1]+1
+			return &Rejection{Reason: ReasonBlocked, Word: blocked.word, Match: code[start:end]}
+		}
+	}
+	return nil
+}
+
+// Len retourne le nombre de mots réservés et de mots bloqués du filtre.
+func (f *Filter) Len() (reserved, blocked int) {
+	if f == nil {
+		return 0, 0
+	}
+	return len(f.reserved), len(f.blocked)
+}
+
+// fold normalise 's' pour la recherche des mots bloqués (voir leetFold) et retourne, pour chaque
+// caractère de la forme normalisée, sa position dans 's'.
+func fold(s string) (string, []int) {
+	folded := make([]byte, 0, len(s))
+	positions := make([]int, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '-' || c == '_' || c == ' ' {
+			continue
+		}
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		if canonical, ok := leetFold[c]; ok {
+			c = canonical
+		}
+		folded = append(folded, c)
+		positions = append(positions, i)
+	}
+	return string(folded), positions
+}
@@ -0,0 +1,99 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFilterCheck(t *testing.T) {
+	filter := NewFilter([]string{"api", " Admin ", ""}, []string{"bad", "Evil", " ", "hell"})
+
+	tests := []struct {
+		code       string
+		wantReason string
+		wantWord   string
+		wantMatch  string
+	}{
+		{code: "abc123"},
+		{code: "apis"},
+		{code: "api", wantReason: ReasonReserved, wantWord: "api", wantMatch: "api"},
+		{code: "API", wantReason: ReasonReserved, wantWord: "api", wantMatch: "API"},
+		{code: "admin", wantReason: ReasonReserved, wantWord: "admin", wantMatch: "admin"},
+		{code: "xbadx", wantReason: ReasonBlocked, wantWord: "bad", wantMatch: "bad"},
+		{code: "XBADX", wantReason: ReasonBlocked, wantWord: "bad", wantMatch: "BAD"},
+		{code: "3v1l-twin", wantReason: ReasonBlocked, wantWord: "evil", wantMatch: "3v1l"},
+		{code: "e-v_i-l", wantReason: ReasonBlocked, wantWord: "evil", wantMatch: "e-v_i-l"},
+		{code: "ev!L", wantReason: ReasonBlocked, wantWord: "evil", wantMatch: "ev!L"},
+		{code: "he11o", wantReason: ReasonBlocked, wantWord: "hell", wantMatch: "he11"},
+		{code: "8ad", wantReason: ReasonBlocked, wantWord: "bad", wantMatch: "8ad"},
+		{code: "b-a-d", wantReason: ReasonBlocked, wantWord: "bad", wantMatch: "b-a-d"},
+		{code: "ba_"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			got := filter.Check(tt.code)
+			if tt.wantReason == "" {
+				if got != nil {
+					t.Fatalf("Check(%q) = %+v, want nil", tt.code, got)
+				}
+				return
+			}
+			want := &Rejection{Reason: tt.wantReason, Word: tt.wantWord, Match: tt.wantMatch}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("Check(%q) = %+v, want %+v", tt.code, got, want)
+			}
+		})
+	}
+}
+
+func TestFilterLen(t *testing.T) {
+	filter := NewFilter([]string{"api", "", " "}, []string{"bad", "--"})
+	if reserved, blocked := filter.Len(); reserved != 1 || blocked != 1 {
+		t.Errorf("Len() = %d, %d; want 1, 1", reserved, blocked)
+	}
+
+	var none *Filter
+	if got := none.Check("api"); got != nil {
+		t.Errorf("nil Filter Check = %+v, want nil", got)
+	}
+	if reserved, blocked := none.Len(); reserved != 0 || blocked != 0 {
+		t.Errorf("nil Filter Len() = %d, %d; want 0, 0", reserved, blocked)
+	}
+}
+
+func TestRejectionError(t *testing.T) {
+	tests := []struct {
+		rejection Rejection
+		want      string
+	}{
+		{Rejection{Reason: ReasonReserved, Word: "api", Match: "API"}, "'API' is a reserved word"},
+		{Rejection{Reason: ReasonBlocked, Word: "bad", Match: "8ad"}, "contains the blocked word 'bad' (as '8ad')"},
+	}
+	for _, tt := range tests {
+		if got := tt.rejection.Error(); got != tt.want {
+			t.Errorf("Error() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestLoadBlocklist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	content := "# Mots bloqués\nbad\n\n  evil  \n#ignored\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	words, err := LoadBlocklist(path)
+	if err != nil {
+		t.Fatalf("LoadBlocklist: %v", err)
+	}
+	if want := []string{"bad", "evil"}; !reflect.DeepEqual(words, want) {
+		t.Errorf("LoadBlocklist = %q, want %q", words, want)
+	}
+
+	if _, err := LoadBlocklist(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("LoadBlocklist of a missing file succeeded, want an error")
+	}
+}
@@ -0,0 +1,59 @@
+package codegen
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// Random génère des codes de longueur fixe dont chaque caractère est tiré au hasard dans un alphabet.
+// La longueur peut être augmentée pendant que des codes sont générés (voir Resizable).
+type Random struct {
+	alphabet string
+	length   atomic.Int32
+}
+
+// NewRandom crée un générateur aléatoire. Un alphabet vide est remplacé par base62.
+func NewRandom(alphabet string, length int) (*Random, error) {
+	alphabet, err := validateAlphabet(alphabet)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateLength(length); err != nil {
+		return nil, err
+	}
+	g := &Random{alphabet: alphabet}
+	g.length.Store(int32(length))
+	return g, nil
+}
+
+// Generate retourne un nouveau code aléatoire.
+func (g *Random) Generate() (string, error) {
+	result := make([]byte, g.Length())
+	for i := range result {
+		index, err := randomIndex(len(g.alphabet))
+		if err != nil {
+			return "", err
+		}
+		result[i] = g.alphabet[index]
+	}
+	return string(result), nil
+}
+
+// Length retourne la longueur des codes générés.
+func (g *Random) Length() int {
+	return int(g.length.Load())
+}
+
+// SetLength change la longueur des codes générés.
+func (g *Random) SetLength(length int) error {
+	if err := validateLength(length); err != nil {
+		return err
+	}
+	g.length.Store(int32(length))
+	return nil
+}
+
+// Capacity retourne le nombre de codes distincts de 'length' caractères : taille de l'alphabet ^ length.
+func (g *Random) Capacity(length int) float64 {
+	return math.Pow(float64(len(g.alphabet)), float64(length))
+}
@@ -0,0 +1,114 @@
+package codegen
+
+import (
+	"hash/fnv"
+	"math"
+	"math/big"
+)
+
+// Sequential encode les valeurs successives d'un compteur persistant, à la manière de hashids :
+// l'alphabet est mélangé par un sel secret, puis à nouveau pour chaque valeur à partir d'un premier
+// caractère dit « loterie ». La valeur est en outre brassée par une bijection affine avant l'encodage :
+// deux valeurs consécutives donnent ainsi des codes sans ressemblance apparente, tout en restant
+// distincts. L'encodage est injectif, aucune collision n'est possible entre deux codes générés.
+type Sequential struct {
+	alphabet  []byte // Alphabet mélangé par le sel
+	salt      []byte
+	offset    *big.Int // Décalage de la bijection, dérivé du sel
+	minLength int
+	seq       Sequence
+}
+
+// sequentialMultiplier est le multiplicateur de la bijection affine. Premier et bien plus grand que
+// les alphabets autorisés, il est premier avec toute puissance de la taille de l'alphabet.
+var sequentialMultiplier = big.NewInt(1_000_000_007)
+
+// NewSequential crée un générateur séquentiel. Un alphabet vide est remplacé par base62 ;
+// les codes font au moins 'minLength' caractères.
+func NewSequential(alphabet, salt string, minLength int, seq Sequence) (*Sequential, error) {
+	alphabet, err := validateAlphabet(alphabet)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateLength(minLength); err != nil {
+		return nil, err
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(salt))
+	return &Sequential{
+		alphabet:  shuffle([]byte(alphabet), []byte(salt)),
+		salt:      []byte(salt),
+		offset:    new(big.Int).SetUint64(hash.Sum64()),
+		minLength: minLength,
+		seq:       seq,
+	}, nil
+}
+
+// Generate encode la valeur suivante du compteur.
+func (g *Sequential) Generate() (string, error) {
+	n, err := g.seq.Next()
+	if err != nil {
+		return "", err
+	}
+	return g.Encode(n), nil
+}
+
+// Length retourne la longueur minimale des codes générés.
+func (g *Sequential) Length() int {
+	return g.minLength
+}
+
+// Capacity retourne le nombre de codes de 'length' caractères : un par valeur du compteur pouvant
+// s'écrire en length-1 chiffres (le premier caractère est la loterie). Les valeurs suivantes
+// produisent automatiquement des codes plus longs.
+func (g *Sequential) Capacity(length int) float64 {
+	if length < 2 {
+		return 0
+	}
+	return math.Pow(float64(len(g.alphabet)), float64(length-1))
+}
+
+// Encode retourne le code de la valeur 'n' : un caractère de loterie, qui choisit le mélange de
+// l'alphabet des chiffres suivants, puis les chiffres de (n × multiplicateur + décalage) modulo
+// base^L, où L est le plus petit nombre de chiffres représentant n (au moins minLength-1).
+// À L fixé, cette transformation est une bijection : deux valeurs distinctes restent distinctes.
+func (g *Sequential) Encode(n uint64) string {
+	base := big.NewInt(int64(len(g.alphabet)))
+	lottery := g.alphabet[n%uint64(len(g.alphabet))]
+	digits := shuffle(append([]byte(nil), g.alphabet...), append([]byte{lottery}, g.salt...))
+
+	value := new(big.Int).SetUint64(n)
+	length := g.minLength - 1
+	modulus := new(big.Int).Exp(base, big.NewInt(int64(length)), nil)
+	for value.Cmp(modulus) >= 0 {
+		length++
+		modulus.Mul(modulus, base)
+	}
+	value.Mul(value, sequentialMultiplier).Add(value, g.offset).Mod(value, modulus)
+
+	code := make([]byte, length+1)
+	code[0] = lottery
+	digit := new(big.Int)
+	for i := length; i > 0; i-- {
+		value.DivMod(value, base, digit)
+		code[i] = digits[digit.Int64()]
+	}
+	return string(code)
+}
+
+// shuffle mélange 'alphabet' en place de façon déterministe à partir de 'salt'
+// (même algorithme que le « consistent shuffle » de hashids) et le retourne.
+func shuffle(alphabet, salt []byte) []byte {
+	if len(salt) == 0 {
+		return alphabet
+	}
+	for i, v, p := len(alphabet)-1, 0, 0; i > 0; i-- {
+		v %= len(salt)
+		n := int(salt[v])
+		p += n
+		j := (n + v + p) % i
+		alphabet[i], alphabet[j] = alphabet[j], alphabet[i]
+		v++
+	}
+	return alphabet
+}
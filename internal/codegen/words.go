@@ -0,0 +1,67 @@
+package codegen
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+)
+
+// Listes de mots des codes prononçables : courts, courants, faciles à épeler au téléphone.
+var (
+	adjectives = []string{
+		"amber", "bold", "brave", "brisk", "blue", "calm", "clever", "cool",
+		"cosy", "crisp", "eager", "early", "fair", "fancy", "fast", "fine",
+		"fresh", "gentle", "giant", "glad", "golden", "grand", "green", "happy",
+		"jolly", "keen", "kind", "lively", "lucky", "merry", "mighty", "misty",
+		"modern", "noble", "orange", "pink", "plain", "polite", "proud", "purple",
+		"quick", "quiet", "rapid", "red", "royal", "rustic", "shiny", "silent",
+		"silver", "simple", "smart", "snowy", "solid", "sunny", "super", "swift",
+		"tidy", "tiny", "urban", "vivid", "warm", "wild", "witty", "young",
+	}
+	nouns = []string{
+		"apple", "badger", "banana", "beach", "bear", "bird", "breeze", "brook",
+		"cactus", "canyon", "castle", "cloud", "comet", "coral", "daisy", "desert",
+		"dolphin", "dragon", "eagle", "falcon", "forest", "fox", "garden", "glacier",
+		"harbor", "hawk", "island", "jungle", "koala", "lagoon", "lemon", "lion",
+		"lotus", "maple", "meadow", "meteor", "moon", "mountain", "ocean", "otter",
+		"owl", "panda", "parrot", "peach", "pepper", "planet", "pony", "rabbit",
+		"river", "robin", "rocket", "salmon", "shark", "sky", "star", "storm",
+		"summit", "tiger", "tulip", "valley", "violet", "whale", "willow", "zebra",
+	}
+)
+
+// Words génère des codes prononçables de la forme adjectif-nom-nombre (ex: blue-tiger-42).
+// L'espace de codes (environ 370 000 combinaisons) convient aux liens partagés oralement
+// plutôt qu'aux volumes importants.
+type Words struct{}
+
+// NewWords crée un générateur de codes prononçables.
+func NewWords() *Words {
+	return &Words{}
+}
+
+// Generate retourne un nouveau code prononçable.
+func (g *Words) Generate() (string, error) {
+	adjective, err := randomIndex(len(adjectives))
+	if err != nil {
+		return "", err
+	}
+	noun, err := randomIndex(len(nouns))
+	if err != nil {
+		return "", err
+	}
+	number, err := randomIndex(90)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s-%s-%d", adjectives[adjective], nouns[noun], number+10), nil
+}
+
+// randomIndex tire un entier dans [0, n) avec crypto/rand, pour que les codes ne soient pas prévisibles.
+func randomIndex(n int) (int, error) {
+	index, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(index.Int64()), nil
+}
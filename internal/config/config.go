@@ -3,14 +3,23 @@ package config
 import (
 	"fmt"
 	"log" // Pour logger les informations ou erreurs de chargement de config
+	"strings"
 
+	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/spf13/viper" // La bibliothèque pour la gestion de configuration
 )
 
 // ServerConfig contient la configuration du serveur HTTP
 type ServerConfig struct {
 	Port    int    `mapstructure:"port"`     // Port d'écoute du serveur HTTP
-	BaseURL string `mapstructure:"base_url"` // URL de base pour construire les URLs courtes complètes
+	BaseURL string `mapstructure:"base_url"` // URL de base pour construire les URLs courtes complètes (domaine par défaut)
+
+	Domains []string `mapstructure:"domains"` // Domaines de marque supplémentaires, chacun avec ses propres codes courts
+
+	DefaultRedirectType     string `mapstructure:"default_redirect_type"`      // Type de redirection des liens qui n'en précisent pas (301, 302, 307, 308 ou meta-refresh)
+	PermanentRedirectMaxAge int    `mapstructure:"permanent_redirect_max_age"` // Durée de cache (en secondes) annoncée pour les redirections permanentes
+
+	TrustedProxies []string `mapstructure:"trusted_proxies"` // Proxys (IP ou CIDR) dont l'en-tête X-Forwarded-For est cru (vide = aucun)
 }
 
 // DatabaseConfig contient la configuration de la base de données
@@ -29,6 +38,77 @@ type MonitorConfig struct {
 	IntervalMinutes int `mapstructure:"interval_minutes"` // Intervalle en minutes entre chaque vérification
 }
 
+// PasswordConfig contient la configuration des liens protégés par mot de passe
+type PasswordConfig struct {
+	CookieSecret         string `mapstructure:"cookie_secret"`          // Clé HMAC signant les cookies de déverrouillage (aléatoire si vide)
+	UnlockTTLMinutes     int    `mapstructure:"unlock_ttl_minutes"`     // Durée de validité d'un déverrouillage réussi
+	MaxAttempts          int    `mapstructure:"max_attempts"`           // Nombre d'échecs tolérés par IP dans la fenêtre
+	AttemptWindowMinutes int    `mapstructure:"attempt_window_minutes"` // Fenêtre de comptage des échecs par IP
+}
+
+// TrashConfig contient la configuration de la corbeille des liens supprimés
+type TrashConfig struct {
+	RetentionDays        int `mapstructure:"retention_days"`         // Durée de conservation d'un lien supprimé avant purge définitive
+	QuarantineDays       int `mapstructure:"quarantine_days"`        // Durée pendant laquelle le code d'un lien purgé ne peut être réattribué
+	PurgeIntervalMinutes int `mapstructure:"purge_interval_minutes"` // Intervalle entre deux passages de la purge
+}
+
+// MetadataConfig contient la configuration de la récupération des métadonnées des destinations
+type MetadataConfig struct {
+	Enabled        bool   `mapstructure:"enabled"`         // Active la récupération du titre, de la description et du favicon
+	UserAgent      string `mapstructure:"user_agent"`      // User-Agent envoyé aux sites de destination
+	TimeoutSeconds int    `mapstructure:"timeout_seconds"` // Durée maximale d'une récupération
+	MaxSizeKB      int    `mapstructure:"max_size_kb"`     // Taille maximale lue dans la page de destination
+}
+
+// GeoIPConfig contient la configuration des destinations par pays
+type GeoIPConfig struct {
+	DatabasePath          string `mapstructure:"database_path"`           // Fichier .mmdb au format MaxMind (GeoLite2/GeoIP2 Country ou City)
+	ReloadIntervalMinutes int    `mapstructure:"reload_interval_minutes"` // Intervalle de détection d'une nouvelle version du fichier (0 = SIGHUP uniquement)
+}
+
+// CodesConfig contient la configuration de la génération des codes courts
+type CodesConfig struct {
+	Generator string `mapstructure:"generator"` // Stratégie : random, sequential, words ou unambiguous
+	Length    int    `mapstructure:"length"`    // Longueur des codes (longueur minimale pour sequential)
+	Alphabet  string `mapstructure:"alphabet"`  // Alphabet des stratégies random et sequential (vide = base62)
+	Salt      string `mapstructure:"salt"`      // Sel du mélange de l'alphabet de la stratégie sequential
+
+	MaxLength        int     `mapstructure:"max_length"`         // Longueur maximale atteinte par l'allongement automatique
+	MaxCollisionRate float64 `mapstructure:"max_collision_rate"` // Taux de collision (0 à 1) déclenchant l'allongement
+	CollisionWindow  int     `mapstructure:"collision_window"`   // Nombre de codes générés sur lequel le taux est mesuré
+
+	Reserved      []string `mapstructure:"reserved"`       // Mots réservés en plus de ceux du service (api, health...)
+	BlocklistFile string   `mapstructure:"blocklist_file"` // Fichier des mots bloqués, un par ligne (vide = aucun)
+}
+
+// DestinationsConfig contient la politique de sécurité des URL de destination
+type DestinationsConfig struct {
+	AllowedSchemes        []string `mapstructure:"allowed_schemes"`         // Schémas acceptés (javascript:, file:, data:... refusés par défaut)
+	AllowedDomains        []string `mapstructure:"allowed_domains"`         // Domaines autorisés, "*.example.com" pour les sous-domaines (vide = tous)
+	DeniedDomains         []string `mapstructure:"denied_domains"`          // Domaines refusés, prioritaires sur allowed_domains
+	BlockPrivateAddresses bool     `mapstructure:"block_private_addresses"` // Refuse les hôtes privés, loopback ou link-local, y compris après résolution DNS
+	RejectUnresolvable    bool     `mapstructure:"reject_unresolvable"`     // Refuse les hôtes dont la résolution DNS échoue
+	ResolveTimeoutSeconds int      `mapstructure:"resolve_timeout_seconds"` // Durée maximale de la résolution DNS d'un hôte
+
+	ShortenerHosts       []string `mapstructure:"shortener_hosts"`        // Raccourcisseurs connus dont les redirections peuvent être suivies
+	FollowShortenerHops  int      `mapstructure:"follow_shortener_hops"`  // Redirections suivies à la création (0 = aucune)
+	FollowTimeoutSeconds int      `mapstructure:"follow_timeout_seconds"` // Durée maximale de chaque requête vers un raccourcisseur
+}
+
+// ThreatFeedConfig décrit un fichier de flux de menaces
+type ThreatFeedConfig struct {
+	Path   string `mapstructure:"path"`   // Chemin du fichier
+	Format string `mapstructure:"format"` // hosts (un hôte par ligne ou format /etc/hosts) ou hashed (préfixes SHA-256)
+	Name   string `mapstructure:"name"`   // Nom du flux dans les signalements (vide = nom du fichier)
+}
+
+// ThreatsConfig contient la configuration des flux locaux d'hôtes malveillants
+type ThreatsConfig struct {
+	Feeds                 []ThreatFeedConfig `mapstructure:"feeds"`                   // Flux chargés par run-server (vide = désactivé)
+	ReloadIntervalMinutes int                `mapstructure:"reload_interval_minutes"` // Intervalle de détection d'une nouvelle version des fichiers (0 = SIGHUP uniquement)
+}
+
 // Config est la structure principale qui mappe l'intégralité de la configuration de l'application.
 // Les tags `mapstructure` sont utilisés par Viper pour mapper les clés du fichier de config
 // (ou des variables d'environnement) aux champs de la structure Go.
@@ -37,6 +117,14 @@ type Config struct {
 	Database  DatabaseConfig  `mapstructure:"database"`  // Configuration de la base de données
 	Analytics AnalyticsConfig `mapstructure:"analytics"` // Configuration des analytics asynchrones
 	Monitor   MonitorConfig   `mapstructure:"monitor"`   // Configuration du moniteur d'URLs
+	Password  PasswordConfig  `mapstructure:"password"`  // Configuration des liens protégés par mot de passe
+	Trash     TrashConfig     `mapstructure:"trash"`     // Configuration de la corbeille des liens supprimés
+	Metadata  MetadataConfig  `mapstructure:"metadata"`  // Configuration de la récupération des métadonnées
+	GeoIP     GeoIPConfig     `mapstructure:"geoip"`     // Configuration des destinations par pays
+	Codes     CodesConfig     `mapstructure:"codes"`     // Configuration de la génération des codes courts
+
+	Destinations DestinationsConfig `mapstructure:"destinations"` // Politique de sécurité des URL de destination
+	Threats      ThreatsConfig      `mapstructure:"threats"`      // Flux locaux d'hôtes malveillants
 }
 
 // LoadConfig charge la configuration de l'application en utilisant Viper.
@@ -58,10 +146,47 @@ func LoadConfig() (*Config, error) {
 	// ou si le fichier n'existe pas.
 	viper.SetDefault("server.port", 8080)
 	viper.SetDefault("server.base_url", "http://localhost:8080")
+	viper.SetDefault("server.domains", []string{})
+	viper.SetDefault("server.default_redirect_type", "302")
+	viper.SetDefault("server.permanent_redirect_max_age", 3600)
+	viper.SetDefault("server.trusted_proxies", []string{})
 	viper.SetDefault("database.name", "url_shortener.db")
 	viper.SetDefault("analytics.buffer_size", 1000)
 	viper.SetDefault("analytics.worker_count", 5)
 	viper.SetDefault("monitor.interval_minutes", 5)
+	viper.SetDefault("password.cookie_secret", "")
+	viper.SetDefault("password.unlock_ttl_minutes", 60)
+	viper.SetDefault("password.max_attempts", 5)
+	viper.SetDefault("password.attempt_window_minutes", 15)
+	viper.SetDefault("trash.retention_days", 30)
+	viper.SetDefault("trash.quarantine_days", 90)
+	viper.SetDefault("trash.purge_interval_minutes", 60)
+	viper.SetDefault("metadata.enabled", true)
+	viper.SetDefault("metadata.user_agent", "urlshortener-metadata/1.0")
+	viper.SetDefault("metadata.timeout_seconds", 5)
+	viper.SetDefault("metadata.max_size_kb", 512)
+	viper.SetDefault("geoip.database_path", "")
+	viper.SetDefault("geoip.reload_interval_minutes", 60)
+	viper.SetDefault("codes.generator", "random")
+	viper.SetDefault("codes.length", 6)
+	viper.SetDefault("codes.alphabet", "")
+	viper.SetDefault("codes.salt", "")
+	viper.SetDefault("codes.max_length", 12)
+	viper.SetDefault("codes.max_collision_rate", 0.1)
+	viper.SetDefault("codes.collision_window", 200)
+	viper.SetDefault("codes.reserved", []string{})
+	viper.SetDefault("codes.blocklist_file", "")
+	viper.SetDefault("destinations.allowed_schemes", []string{"http", "https"})
+	viper.SetDefault("destinations.allowed_domains", []string{})
+	viper.SetDefault("destinations.denied_domains", []string{})
+	viper.SetDefault("destinations.block_private_addresses", true)
+	viper.SetDefault("destinations.reject_unresolvable", false)
+	viper.SetDefault("destinations.resolve_timeout_seconds", 2)
+	viper.SetDefault("destinations.shortener_hosts", []string{"bit.ly", "tinyurl.com", "t.co", "goo.gl", "ow.ly", "is.gd", "buff.ly", "rebrand.ly", "cutt.ly", "shorturl.at"})
+	viper.SetDefault("destinations.follow_shortener_hops", 0)
+	viper.SetDefault("destinations.follow_timeout_seconds", 5)
+	viper.SetDefault("threats.feeds", []ThreatFeedConfig{})
+	viper.SetDefault("threats.reload_interval_minutes", 30)
 
 	// Lire le fichier de configuration.
 	err := viper.ReadInConfig()
@@ -92,6 +217,21 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf(" ERREUR FATALE: Port serveur invalide (%d). Doit être entre 1 et 65535", cfg.Server.Port)
 	}
 
+	cfg.Server.BaseURL = strings.TrimSuffix(cfg.Server.BaseURL, "/")
+	if cfg.Server.Domains, err = normalizeDomains(cfg.Server.BaseURL, cfg.Server.Domains); err != nil {
+		return nil, fmt.Errorf(" ERREUR FATALE: %w", err)
+	}
+
+	if !models.RedirectType(cfg.Server.DefaultRedirectType).IsValid() {
+		log.Printf("  Type de redirection par défaut invalide (%q), utilisation de la valeur par défaut (302)", cfg.Server.DefaultRedirectType)
+		cfg.Server.DefaultRedirectType = string(models.RedirectFound)
+	}
+
+	if cfg.Server.PermanentRedirectMaxAge < 0 {
+		log.Printf("  Durée de cache des redirections permanentes invalide (%d), utilisation de la valeur par défaut (3600 secondes)", cfg.Server.PermanentRedirectMaxAge)
+		cfg.Server.PermanentRedirectMaxAge = 3600
+	}
+
 	if cfg.Analytics.BufferSize <= 0 {
 		log.Printf("  Taille de buffer analytics invalide (%d), utilisation de la valeur par défaut (1000)", cfg.Analytics.BufferSize)
 		cfg.Analytics.BufferSize = 1000
@@ -107,11 +247,109 @@ func LoadConfig() (*Config, error) {
 		cfg.Monitor.IntervalMinutes = 5
 	}
 
+	if cfg.Password.UnlockTTLMinutes <= 0 {
+		log.Printf("  Durée de déverrouillage invalide (%d), utilisation de la valeur par défaut (60 minutes)", cfg.Password.UnlockTTLMinutes)
+		cfg.Password.UnlockTTLMinutes = 60
+	}
+
+	if cfg.Password.MaxAttempts <= 0 {
+		log.Printf("  Nombre de tentatives de mot de passe invalide (%d), utilisation de la valeur par défaut (5)", cfg.Password.MaxAttempts)
+		cfg.Password.MaxAttempts = 5
+	}
+
+	if cfg.Password.AttemptWindowMinutes <= 0 {
+		log.Printf("  Fenêtre de tentatives invalide (%d), utilisation de la valeur par défaut (15 minutes)", cfg.Password.AttemptWindowMinutes)
+		cfg.Password.AttemptWindowMinutes = 15
+	}
+
+	if cfg.Trash.RetentionDays < 0 {
+		log.Printf("  Durée de rétention de la corbeille invalide (%d), utilisation de la valeur par défaut (30 jours)", cfg.Trash.RetentionDays)
+		cfg.Trash.RetentionDays = 30
+	}
+
+	if cfg.Trash.QuarantineDays < 0 {
+		log.Printf("  Durée de quarantaine invalide (%d), utilisation de la valeur par défaut (90 jours)", cfg.Trash.QuarantineDays)
+		cfg.Trash.QuarantineDays = 90
+	}
+
+	if cfg.Trash.PurgeIntervalMinutes <= 0 {
+		log.Printf("  Intervalle de purge invalide (%d), utilisation de la valeur par défaut (60 minutes)", cfg.Trash.PurgeIntervalMinutes)
+		cfg.Trash.PurgeIntervalMinutes = 60
+	}
+
+	if cfg.Metadata.TimeoutSeconds <= 0 {
+		log.Printf("  Timeout de récupération des métadonnées invalide (%d), utilisation de la valeur par défaut (5 secondes)", cfg.Metadata.TimeoutSeconds)
+		cfg.Metadata.TimeoutSeconds = 5
+	}
+
+	if cfg.Metadata.MaxSizeKB <= 0 {
+		log.Printf("  Taille maximale des pages de destination invalide (%d), utilisation de la valeur par défaut (512 Ko)", cfg.Metadata.MaxSizeKB)
+		cfg.Metadata.MaxSizeKB = 512
+	}
+
+	if cfg.GeoIP.ReloadIntervalMinutes < 0 {
+		log.Printf("  Intervalle de rechargement GeoIP invalide (%d), utilisation de la valeur par défaut (60 minutes)", cfg.GeoIP.ReloadIntervalMinutes)
+		cfg.GeoIP.ReloadIntervalMinutes = 60
+	}
+
+	if cfg.Codes.Length <= 0 {
+		log.Printf("  Longueur des codes courts invalide (%d), utilisation de la valeur par défaut (6)", cfg.Codes.Length)
+		cfg.Codes.Length = 6
+	}
+
+	if cfg.Codes.MaxLength < cfg.Codes.Length {
+		log.Printf("  Longueur maximale des codes courts (%d) inférieure à la longueur des codes, allongement automatique désactivé", cfg.Codes.MaxLength)
+		cfg.Codes.MaxLength = cfg.Codes.Length
+	}
+
+	if cfg.Codes.MaxCollisionRate <= 0 || cfg.Codes.MaxCollisionRate >= 1 {
+		log.Printf("  Taux de collision maximal invalide (%g), utilisation de la valeur par défaut (0.1)", cfg.Codes.MaxCollisionRate)
+		cfg.Codes.MaxCollisionRate = 0.1
+	}
+
+	if cfg.Codes.CollisionWindow <= 0 {
+		log.Printf("  Fenêtre de mesure des collisions invalide (%d), utilisation de la valeur par défaut (200 codes)", cfg.Codes.CollisionWindow)
+		cfg.Codes.CollisionWindow = 200
+	}
+
+	if cfg.Destinations.ResolveTimeoutSeconds <= 0 {
+		log.Printf("  Timeout de résolution DNS des destinations invalide (%d), utilisation de la valeur par défaut (2 secondes)", cfg.Destinations.ResolveTimeoutSeconds)
+		cfg.Destinations.ResolveTimeoutSeconds = 2
+	}
+	if cfg.Destinations.FollowShortenerHops < 0 {
+		log.Printf("  Nombre de redirections suivies invalide (%d), suivi des raccourcisseurs désactivé", cfg.Destinations.FollowShortenerHops)
+		cfg.Destinations.FollowShortenerHops = 0
+	}
+	if cfg.Destinations.FollowTimeoutSeconds <= 0 {
+		log.Printf("  Timeout de suivi des raccourcisseurs invalide (%d), utilisation de la valeur par défaut (5 secondes)", cfg.Destinations.FollowTimeoutSeconds)
+		cfg.Destinations.FollowTimeoutSeconds = 5
+	}
+
+	for i := range cfg.Threats.Feeds {
+		if cfg.Threats.Feeds[i].Format == "" {
+			cfg.Threats.Feeds[i].Format = "hosts"
+		}
+	}
+	if cfg.Threats.ReloadIntervalMinutes < 0 {
+		log.Printf("  Intervalle de rechargement des flux de menaces invalide (%d), utilisation de la valeur par défaut (30 minutes)", cfg.Threats.ReloadIntervalMinutes)
+		cfg.Threats.ReloadIntervalMinutes = 30
+	}
+
 	// Log final informatif pour confirmer la configuration chargée
 	log.Printf(" === CONFIGURATION CHARGÉE AVEC SUCCÈS ===")
 	log.Printf(" SERVEUR:")
 	log.Printf("   ├─ Port d'écoute: %d", cfg.Server.Port)
-	log.Printf("   └─ URL de base: %s", cfg.Server.BaseURL)
+	log.Printf("   ├─ URL de base: %s", cfg.Server.BaseURL)
+	for _, domain := range cfg.Server.Domains {
+		log.Printf("   ├─ Domaine de marque: %s", domain)
+	}
+	log.Printf("   ├─ Redirection par défaut: %s", cfg.Server.DefaultRedirectType)
+	log.Printf("   ├─ Cache des redirections permanentes: %d secondes", cfg.Server.PermanentRedirectMaxAge)
+	if len(cfg.Server.TrustedProxies) > 0 {
+		log.Printf("   └─ Proxys de confiance: %s", strings.Join(cfg.Server.TrustedProxies, ", "))
+	} else {
+		log.Printf("   └─ Proxys de confiance: aucun (X-Forwarded-For ignoré)")
+	}
 	log.Printf("  BASE DE DONNÉES:")
 	log.Printf("   └─ Fichier SQLite: %s", cfg.Database.Name)
 	log.Printf(" ANALYTICS (Workers asynchrones):")
@@ -119,6 +357,64 @@ func LoadConfig() (*Config, error) {
 	log.Printf("   └─ Nombre de workers: %d goroutines", cfg.Analytics.WorkerCount)
 	log.Printf(" MONITEUR D'URLS:")
 	log.Printf("   └─ Intervalle de vérification: %d minutes", cfg.Monitor.IntervalMinutes)
+	log.Printf(" LIENS PROTÉGÉS:")
+	log.Printf("   ├─ Durée de déverrouillage: %d minutes", cfg.Password.UnlockTTLMinutes)
+	log.Printf("   └─ Tentatives max par IP: %d / %d minutes", cfg.Password.MaxAttempts, cfg.Password.AttemptWindowMinutes)
+	log.Printf(" CORBEILLE:")
+	log.Printf("   ├─ Rétention avant purge: %d jours", cfg.Trash.RetentionDays)
+	log.Printf("   ├─ Quarantaine des codes purgés: %d jours", cfg.Trash.QuarantineDays)
+	log.Printf("   └─ Intervalle de purge: %d minutes", cfg.Trash.PurgeIntervalMinutes)
+	log.Printf(" MÉTADONNÉES DES DESTINATIONS:")
+	if cfg.Metadata.Enabled {
+		log.Printf("   ├─ User-Agent: %s", cfg.Metadata.UserAgent)
+		log.Printf("   ├─ Timeout: %d secondes", cfg.Metadata.TimeoutSeconds)
+		log.Printf("   └─ Taille maximale lue: %d Ko", cfg.Metadata.MaxSizeKB)
+	} else {
+		log.Printf("   └─ Désactivée")
+	}
+	log.Printf(" GÉOLOCALISATION:")
+	if cfg.GeoIP.DatabasePath != "" {
+		log.Printf("   ├─ Base GeoIP: %s", cfg.GeoIP.DatabasePath)
+		log.Printf("   └─ Vérification des mises à jour: %d minutes (0 = SIGHUP uniquement)", cfg.GeoIP.ReloadIntervalMinutes)
+	} else {
+		log.Printf("   └─ Désactivée (aucune base configurée)")
+	}
+	log.Printf(" CODES COURTS:")
+	log.Printf("   ├─ Générateur: %s", cfg.Codes.Generator)
+	log.Printf("   ├─ Longueur: %d caractères (maximum: %d)", cfg.Codes.Length, cfg.Codes.MaxLength)
+	log.Printf("   ├─ Allongement au-delà de %.1f%% de collisions sur %d codes", cfg.Codes.MaxCollisionRate*100, cfg.Codes.CollisionWindow)
+	if cfg.Codes.BlocklistFile != "" {
+		log.Printf("   └─ Liste de blocage: %s", cfg.Codes.BlocklistFile)
+	} else {
+		log.Printf("   └─ Liste de blocage: aucune")
+	}
+	log.Printf(" SÉCURITÉ DES DESTINATIONS:")
+	log.Printf("   ├─ Schémas autorisés: %s", strings.Join(cfg.Destinations.AllowedSchemes, ", "))
+	if len(cfg.Destinations.AllowedDomains) > 0 {
+		log.Printf("   ├─ Domaines autorisés: %s", strings.Join(cfg.Destinations.AllowedDomains, ", "))
+	}
+	if len(cfg.Destinations.DeniedDomains) > 0 {
+		log.Printf("   ├─ Domaines refusés: %s", strings.Join(cfg.Destinations.DeniedDomains, ", "))
+	}
+	if cfg.Destinations.BlockPrivateAddresses {
+		log.Printf("   ├─ Adresses internes: refusées (résolution DNS: %d secondes max)", cfg.Destinations.ResolveTimeoutSeconds)
+	} else {
+		log.Printf("   ├─ Adresses internes: autorisées")
+	}
+	if cfg.Destinations.FollowShortenerHops > 0 {
+		log.Printf("   └─ Raccourcisseurs suivis: %d redirections max (%s)", cfg.Destinations.FollowShortenerHops, strings.Join(cfg.Destinations.ShortenerHosts, ", "))
+	} else {
+		log.Printf("   └─ Raccourcisseurs: non suivis (seuls les liens vers le service lui-même sont refusés)")
+	}
+	log.Printf(" FLUX DE MENACES:")
+	if len(cfg.Threats.Feeds) > 0 {
+		for _, feed := range cfg.Threats.Feeds {
+			log.Printf("   ├─ Flux: %s (%s)", feed.Path, feed.Format)
+		}
+		log.Printf("   └─ Vérification des mises à jour: %d minutes (0 = SIGHUP uniquement)", cfg.Threats.ReloadIntervalMinutes)
+	} else {
+		log.Printf("   └─ Désactivés (aucun flux configuré)")
+	}
 	log.Printf(" Configuration prête pour le démarrage du service !")
 
 	return &cfg, nil // Retourne la configuration chargée
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrUnknownDomain est retournée quand un domaine court ne figure pas dans la configuration.
+var ErrUnknownDomain = errors.New("unknown short domain")
+
+// Les liens enregistrent le domaine court sous la forme de son hôte (ex: "go.brand.example"),
+// et par une chaîne vide pour le domaine par défaut (celui de BaseURL) : changer BaseURL,
+// par exemple entre un environnement de test et la production, ne modifie donc pas les liens existants.
+
+// ShortURL retourne l'URL courte complète d'un code sur un domaine : BaseURL + "/" + code
+// pour le domaine par défaut, l'URL de base du domaine de marque sinon. Un domaine retiré de
+// la configuration garde des URLs valides, construites avec le schéma de BaseURL.
+func (s ServerConfig) ShortURL(domain, shortCode string) string {
+	if domain == "" {
+		return s.BaseURL + "/" + shortCode
+	}
+	for _, base := range s.Domains {
+		if hostOf(base) == domain {
+			return base + "/" + shortCode
+		}
+	}
+	scheme, _, _ := strings.Cut(s.BaseURL, "://")
+	return scheme + "://" + domain + "/" + shortCode
+}
+
+// DomainForHost retourne le domaine court correspondant à l'en-tête Host d'une requête.
+// Un hôte inconnu (adresse IP, nom interne...) est rattaché au domaine par défaut.
+func (s ServerConfig) DomainForHost(host string) string {
+	host = strings.ToLower(host)
+	for _, base := range s.Domains {
+		if hostOf(base) == host {
+			return host
+		}
+	}
+	return ""
+}
+
+// NormalizeDomain valide un domaine court fourni par un client (hôte ou URL de base) et retourne
+// la valeur à enregistrer sur les liens : "" pour le domaine par défaut, l'hôte sinon.
+func (s ServerConfig) NormalizeDomain(domain string) (string, error) {
+	if domain == "" {
+		return "", nil
+	}
+	host := strings.ToLower(strings.TrimSuffix(domain, "/"))
+	if strings.Contains(host, "://") {
+		host = hostOf(host)
+	}
+	if host == hostOf(s.BaseURL) {
+		return "", nil
+	}
+	for _, base := range s.Domains {
+		if hostOf(base) == host {
+			return host, nil
+		}
+	}
+	return "", fmt.Errorf("%w: '%s'", ErrUnknownDomain, domain)
+}
+
+// hostOf retourne l'hôte (port compris) d'une URL de base, en minuscules.
+func hostOf(baseURL string) string {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Host)
+}
+
+// normalizeDomains valide les domaines de marque configurés et les met sous la forme d'URLs de base
+// sans '/' final. Un domaine donné sans schéma reprend celui de BaseURL.
+func normalizeDomains(baseURL string, domains []string) ([]string, error) {
+	seen := map[string]bool{hostOf(baseURL): true}
+	scheme, _, _ := strings.Cut(baseURL, "://")
+
+	normalized := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		base := strings.TrimSuffix(strings.TrimSpace(domain), "/")
+		if !strings.Contains(base, "://") {
+			base = scheme + "://" + base
+		}
+		u, err := url.Parse(base)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" || u.RawQuery != "" {
+			return nil, fmt.Errorf("domaine court invalide '%s' (attendu: un hôte ou une URL de base comme https://go.example.com)", domain)
+		}
+		host := strings.ToLower(u.Host)
+		if seen[host] {
+			return nil, fmt.Errorf("domaine court '%s' configuré plusieurs fois (ou identique à base_url)", host)
+		}
+		seen[host] = true
+		normalized = append(normalized, u.Scheme+"://"+host)
+	}
+	return normalized, nil
+}
@@ -0,0 +1,119 @@
+package geoip
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// countryRecord est la partie d'un enregistrement GeoIP2/GeoLite2 Country utilisée pour la résolution.
+type countryRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	RegisteredCountry struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+}
+
+// Resolver résout le pays d'une adresse IP à partir d'une base MaxMind locale (.mmdb),
+// sans aucun appel réseau. La base peut être rechargée à chaud : les résolutions en cours
+// terminent sur l'ancienne base avant qu'elle soit fermée.
+type Resolver struct {
+	path string // Chemin du fichier .mmdb
+
+	mu      sync.RWMutex      // Protège reader et modTime pendant un rechargement
+	reader  *maxminddb.Reader // nil tant qu'aucune base n'a pu être ouverte
+	modTime time.Time         // Date de modification du fichier actuellement chargé
+}
+
+// NewResolver crée un Resolver pour la base 'path'. La base n'est ouverte qu'au premier Reload.
+func NewResolver(path string) *Resolver {
+	return &Resolver{path: path}
+}
+
+// Path retourne le chemin de la base utilisée par le Resolver.
+func (r *Resolver) Path() string {
+	return r.path
+}
+
+// Reload ouvre (ou rouvre) la base. En cas d'échec, la base précédente reste utilisée.
+func (r *Resolver) Reload() error {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return err
+	}
+	reader, err := maxminddb.Open(r.path)
+	if err != nil {
+		return fmt.Errorf("failed to open GeoIP database: %w", err)
+	}
+
+	r.mu.Lock()
+	previous := r.reader
+	r.reader = reader
+	r.modTime = info.ModTime()
+	r.mu.Unlock()
+
+	if previous != nil {
+		previous.Close()
+	}
+	return nil
+}
+
+// ReloadIfChanged recharge la base si le fichier a été modifié depuis son dernier chargement.
+// Elle retourne true si une nouvelle base a été chargée.
+func (r *Resolver) ReloadIfChanged() (bool, error) {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return false, err
+	}
+	r.mu.RLock()
+	unchanged := r.reader != nil && info.ModTime().Equal(r.modTime)
+	r.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+	return true, r.Reload()
+}
+
+// Country retourne le code pays ISO 3166-1 alpha-2 (en majuscules) de l'adresse IP,
+// ou une chaîne vide si l'adresse est invalide, inconnue ou si aucune base n'est chargée.
+func (r *Resolver) Country(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.reader == nil {
+		return ""
+	}
+
+	var record countryRecord
+	if err := r.reader.Lookup(parsed, &record); err != nil {
+		return ""
+	}
+	code := record.Country.ISOCode
+	if code == "" {
+		code = record.RegisteredCountry.ISOCode
+	}
+	return strings.ToUpper(code)
+}
+
+// Close ferme la base chargée.
+func (r *Resolver) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.reader == nil {
+		return nil
+	}
+	err := r.reader.Close()
+	r.reader = nil
+	return err
+}
@@ -0,0 +1,226 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// maxFieldLength borne la longueur de chaque champ extrait, pour ne pas stocker de texte démesuré.
+const maxFieldLength = 1024
+
+// ErrNotHTML est renvoyée quand la destination ne sert pas une page HTML.
+var ErrNotHTML = errors.New("destination is not an HTML page")
+
+// Fetcher récupère la page de destination d'un lien et en extrait les métadonnées.
+// Le client HTTP est injecté : un client pointant vers un httptest.Server suffit pour le tester.
+type Fetcher struct {
+	client    *http.Client // Client utilisé pour les requêtes (son Timeout borne la durée d'un fetch)
+	userAgent string       // Valeur de l'en-tête User-Agent envoyée à la destination
+	maxBytes  int64        // Nombre maximum d'octets lus dans le corps de la réponse
+}
+
+// NewFetcher crée et retourne une nouvelle instance de Fetcher.
+func NewFetcher(client *http.Client, userAgent string, maxBytes int64) *Fetcher {
+	return &Fetcher{
+		client:    client,
+		userAgent: userAgent,
+		maxBytes:  maxBytes,
+	}
+}
+
+// Fetch télécharge la page 'rawURL' (au plus maxBytes octets) et retourne ses métadonnées.
+// Les URLs relatives (favicon, image Open Graph) sont résolues par rapport à l'URL finale,
+// après redirections. Sans balise <link rel="icon">, le favicon par défaut /favicon.ico est retenu.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*models.LinkMetadata, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if f.userAgent != "" {
+		req.Header.Set("User-Agent", f.userAgent)
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.1")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil || (mediaType != "text/html" && mediaType != "application/xhtml+xml") {
+			return nil, fmt.Errorf("%w (%s)", ErrNotHTML, contentType)
+		}
+	}
+
+	body, err := charset.NewReader(io.LimitReader(resp.Body, f.maxBytes), contentType)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported charset: %w", err)
+	}
+
+	meta := parse(body, resp.Request.URL)
+	now := time.Now()
+	meta.FetchedAt = &now
+	return meta, nil
+}
+
+// parse parcourt le document jusqu'à la fin du <head> et collecte les balises utiles.
+// Un document tronqué par la limite de taille est analysé jusqu'où il a été lu.
+func parse(r io.Reader, base *url.URL) *models.LinkMetadata {
+	meta := &models.LinkMetadata{}
+	tokenizer := html.NewTokenizer(r)
+	inTitle := false
+	var title strings.Builder
+
+	for {
+		tokenType := tokenizer.Next()
+		switch tokenType {
+		case html.ErrorToken:
+			return finish(meta, title.String(), base)
+
+		case html.TextToken:
+			if inTitle {
+				title.Write(tokenizer.Text())
+			}
+
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			switch string(name) {
+			case "title":
+				inTitle = false
+			case "head":
+				return finish(meta, title.String(), base)
+			}
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := tokenizer.TagName()
+			switch string(name) {
+			case "title":
+				inTitle = tokenType == html.StartTagToken && title.Len() == 0
+			case "body":
+				return finish(meta, title.String(), base)
+			case "meta":
+				if hasAttr {
+					readMeta(meta, attributes(tokenizer))
+				}
+			case "link":
+				if hasAttr {
+					readLink(meta, attributes(tokenizer), base)
+				}
+			}
+		}
+	}
+}
+
+// attributes retourne les attributs de la balise courante, noms en minuscules.
+func attributes(tokenizer *html.Tokenizer) map[string]string {
+	attrs := make(map[string]string)
+	for {
+		key, value, more := tokenizer.TagAttr()
+		attrs[strings.ToLower(string(key))] = string(value)
+		if !more {
+			return attrs
+		}
+	}
+}
+
+// readMeta renseigne la description et les balises Open Graph (la première occurrence l'emporte).
+func readMeta(meta *models.LinkMetadata, attrs map[string]string) {
+	content := attrs["content"]
+	if content == "" {
+		return
+	}
+
+	// Open Graph utilise 'property', mais beaucoup de sites utilisent 'name' à la place.
+	key := strings.ToLower(attrs["property"])
+	if key == "" {
+		key = strings.ToLower(attrs["name"])
+	}
+
+	var field *string
+	switch key {
+	case "description":
+		field = &meta.Description
+	case "og:title":
+		field = &meta.OGTitle
+	case "og:description":
+		field = &meta.OGDescription
+	case "og:image", "og:image:url":
+		field = &meta.OGImage
+	case "og:site_name":
+		field = &meta.OGSiteName
+	default:
+		return
+	}
+	if *field == "" {
+		*field = content
+	}
+}
+
+// readLink retient le premier favicon déclaré par une balise <link rel="icon"> (ou "shortcut icon").
+func readLink(meta *models.LinkMetadata, attrs map[string]string, base *url.URL) {
+	if meta.FaviconURL != "" || attrs["href"] == "" {
+		return
+	}
+	for _, rel := range strings.Fields(strings.ToLower(attrs["rel"])) {
+		if rel == "icon" {
+			meta.FaviconURL = resolve(base, attrs["href"])
+			return
+		}
+	}
+}
+
+// finish normalise les champs extraits et complète les valeurs par défaut.
+func finish(meta *models.LinkMetadata, title string, base *url.URL) *models.LinkMetadata {
+	meta.Title = clean(title)
+	meta.Description = clean(meta.Description)
+	meta.OGTitle = clean(meta.OGTitle)
+	meta.OGDescription = clean(meta.OGDescription)
+	meta.OGSiteName = clean(meta.OGSiteName)
+	meta.OGImage = clean(resolve(base, meta.OGImage))
+	if meta.FaviconURL == "" {
+		meta.FaviconURL = resolve(base, "/favicon.ico")
+	}
+	meta.FaviconURL = clean(meta.FaviconURL)
+	return meta
+}
+
+// resolve rend 'ref' absolue par rapport à 'base' ; une référence invalide est ignorée.
+func resolve(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+	u, err := base.Parse(ref)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return u.String()
+}
+
+// clean compacte les espaces d'un texte extrait et le tronque à maxFieldLength caractères.
+func clean(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if runes := []rune(s); len(runes) > maxFieldLength {
+		s = string(runes[:maxFieldLength])
+	}
+	return s
+}
@@ -0,0 +1,166 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serve démarre un serveur de test qui répond 'body' avec le Content-Type donné.
+func serve(t *testing.T, contentType, body string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetchExtractsMetadata(t *testing.T) {
+	server := serve(t, "text/html; charset=utf-8", `<!DOCTYPE html>
+<html><head>
+  <title>
+    Example   page
+  </title>
+  <meta name="description" content="A short description">
+  <meta property="og:title" content="OG title">
+  <meta name="og:description" content="OG description">
+  <meta property="og:image" content="/img/cover.png">
+  <meta property="og:site_name" content="Example">
+  <link rel="shortcut icon" href="/static/icon.png">
+</head><body><title>Ignored</title></body></html>`)
+
+	meta, err := NewFetcher(server.Client(), "test-agent", 64*1024).Fetch(context.Background(), server.URL+"/page")
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+
+	tests := []struct {
+		field string
+		got   string
+		want  string
+	}{
+		{"Title", meta.Title, "Example page"},
+		{"Description", meta.Description, "A short description"},
+		{"OGTitle", meta.OGTitle, "OG title"},
+		{"OGDescription", meta.OGDescription, "OG description"},
+		{"OGImage", meta.OGImage, server.URL + "/img/cover.png"},
+		{"OGSiteName", meta.OGSiteName, "Example"},
+		{"FaviconURL", meta.FaviconURL, server.URL + "/static/icon.png"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.field, tt.got, tt.want)
+		}
+	}
+	if meta.FetchedAt == nil {
+		t.Error("FetchedAt is nil")
+	}
+}
+
+func TestFetchDefaultFavicon(t *testing.T) {
+	server := serve(t, "text/html", `<html><head><title>No icon</title></head></html>`)
+
+	meta, err := NewFetcher(server.Client(), "", 64*1024).Fetch(context.Background(), server.URL+"/a/b")
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if want := server.URL + "/favicon.ico"; meta.FaviconURL != want {
+		t.Errorf("FaviconURL = %q, want %q", meta.FaviconURL, want)
+	}
+}
+
+func TestFetchSizeLimit(t *testing.T) {
+	// Le titre commence avant la limite et se termine bien après : seule la partie lue est retenue.
+	page := `<html><head><title>` + strings.Repeat("a", 100) + strings.Repeat("b", 10000) + `</title></head></html>`
+	server := serve(t, "text/html", page)
+
+	meta, err := NewFetcher(server.Client(), "", 64).Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if strings.Contains(meta.Title, "b") {
+		t.Errorf("Title = %q, read past the size limit", meta.Title)
+	}
+	if len(meta.Title) == 0 || len(meta.Title) > 64 {
+		t.Errorf("len(Title) = %d, want between 1 and 64", len(meta.Title))
+	}
+}
+
+func TestFetchTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	client := server.Client()
+	client.Timeout = 50 * time.Millisecond
+	start := time.Now()
+	if _, err := NewFetcher(client, "", 1024).Fetch(context.Background(), server.URL); err == nil {
+		t.Fatal("Fetch succeeded, want a timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Fetch took %v, want it bounded by the client timeout", elapsed)
+	}
+}
+
+func TestFetchRejects(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		status      int
+		wantNotHTML bool
+	}{
+		{name: "JSON document", contentType: "application/json", status: http.StatusOK, wantNotHTML: true},
+		{name: "image", contentType: "image/png", status: http.StatusOK, wantNotHTML: true},
+		{name: "invalid content type", contentType: "text/", status: http.StatusOK, wantNotHTML: true},
+		{name: "error status", contentType: "text/html", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(`<html><head><title>x</title></head></html>`))
+			}))
+			defer server.Close()
+
+			meta, err := NewFetcher(server.Client(), "", 1024).Fetch(context.Background(), server.URL)
+			if err == nil {
+				t.Fatalf("Fetch = %+v, want an error", meta)
+			}
+			if got := errors.Is(err, ErrNotHTML); got != tt.wantNotHTML {
+				t.Errorf("errors.Is(%v, ErrNotHTML) = %v, want %v", err, got, tt.wantNotHTML)
+			}
+		})
+	}
+}
+
+func TestFetchSendsUserAgent(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("User-Agent")
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head></head></html>`))
+	}))
+	defer server.Close()
+
+	if _, err := NewFetcher(server.Client(), "urlshortener-test/1.0", 1024).Fetch(context.Background(), server.URL); err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if got != "urlshortener-test/1.0" {
+		t.Errorf("User-Agent = %q, want %q", got, "urlshortener-test/1.0")
+	}
+}
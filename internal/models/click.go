@@ -9,11 +9,12 @@ type Click struct {
 	LinkID    uint      `gorm:"index"`             // Clé étrangère vers la table 'links', indexée pour des requêtes efficaces
 	Link      Link      `gorm:"foreignKey:LinkID"` // Relation GORM: indique que LinkID est une FK vers le champ ID de Link
 	Timestamp time.Time // Horodatage précis du clic
-	UserAgent string    `gorm:"size:255"` // User-Agent de l'utilisateur qui a cliqué (informations sur le navigateur/OS)
-	IPAddress string    `gorm:"size:50"`  // Adresse IP de l'utilisateur
+	UserAgent string    `gorm:"size:255"`      // User-Agent de l'utilisateur qui a cliqué (informations sur le navigateur/OS)
+	IPAddress string    `gorm:"size:50"`       // Adresse IP de l'utilisateur
+	Platform  string    `gorm:"size:20;index"` // Plateforme détectée (ios, android, desktop, other) qui a déterminé la destination servie
+	Variant   string    `gorm:"size:32;index"` // Variante A/B servie, vide si le lien n'a pas de destinations pondérées
 }
 
-// TODO créer la struct pour ClickEvent
 // ClickEvent représente un événement de clic brut, destiné à être passé via un channel
 // Ce n'est pas un modèle GORM direct.
 // Un Click event a un LinkID(uint), un Timestamp (Time.Time), un UserAgent (string) et un IP (stringà
@@ -22,4 +23,6 @@ type ClickEvent struct {
 	TimesTamp time.Time
 	UserAgent string
 	IPAddress string
+	Platform  string
+	Variant   string
 }
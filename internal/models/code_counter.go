@@ -0,0 +1,8 @@
+package models
+
+// CodeCounter est un compteur persistant utilisé par la génération séquentielle des codes courts.
+// Sa valeur est la dernière valeur distribuée.
+type CodeCounter struct {
+	Name  string `gorm:"primaryKey"` // Nom du compteur
+	Value uint64 `gorm:"not null"`   // Dernière valeur distribuée (0 = aucune)
+}
@@ -1,17 +1,206 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"maps"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
 
-// TODO : Créer la struct Link
 // Link représente un lien raccourci dans la base de données.
 // Les tags `gorm:"..."` définissent comment GORM doit mapper cette structure à une table SQL.
 // ID qui est une primaryKey
-// Shortcode : doit être unique, indexé pour des recherches rapide (voir doc), taille max 10 caractères
-// LongURL : doit pas être null
+// Shortcode : doit être unique sur son domaine, indexé pour des recherches rapide (voir doc), taille max 10 caractères
+// Domain : hôte du domaine court de marque du lien, vide pour le domaine par défaut (BaseURL)
+// LongURL : doit pas être null, destination telle que saisie, vers laquelle le lien redirige
+// CanonicalURL : forme canonique de LongURL (voir urlnorm), indexée pour repérer les doublons, jamais utilisée pour rediriger
+// Reused : non persisté, indique que CreateLink a retourné un lien existant équivalent (mode réutilisation)
 // CreateAt : Horodatage de la créatino du lien
+// ExpiresAt : date d'expiration optionnelle (nil = jamais), NotBefore : date d'activation optionnelle
+// Schedule : destinations programmées sur des plages horaires (UTC), prioritaires sur toutes les autres
+// MaxClicks : budget de clics optionnel (0 = illimité), ClicksUsed compte les clics consommés
+// PasswordHash : hash bcrypt du mot de passe optionnel, jamais exposé en JSON
+// Disabled : lien désactivé manuellement, la suppression passe par le DeletedAt de gorm.Model
+// IOSURL, AndroidURL, DesktopURL : destinations optionnelles par plateforme, LongURL sert de repli
+// CountryURLs : destinations optionnelles par code pays ISO 3166-1 alpha-2, stockées en JSON
+// Rules : règles de redirection déclaratives, évaluées avant toute autre destination (première qui correspond)
+// Variants : destinations pondérées d'un test A/B, StickyVariants les mémorise par visiteur (cookie)
+// RedirectType : code de redirection (301, 302, 307, 308) ou page meta refresh, vide = défaut du serveur
+// ForwardQuery : transmet la query string de l'URL courte à la destination ; UTM : paramètres UTM ajoutés par défaut
+// FlaggedAt : date à laquelle une destination a été trouvée dans un flux de menaces (nil = non signalé),
+// ThreatFeed et ThreatEntry indiquent le flux et l'entrée correspondante ; un lien signalé affiche un avertissement
+// Metadata : titre, description, Open Graph et favicon de la destination, récupérés en arrière-plan
 
 type Link struct {
 	gorm.Model
-	ShortCode string `json:"short_code" gorm:"unique;not null"`
-	LongURL   string `json:"long_url" gorm:"not null"`
+	ShortCode    string `json:"short_code" gorm:"uniqueIndex:idx_links_domain_short_code,priority:2;not null"`
+	Domain       string `json:"domain,omitempty" gorm:"uniqueIndex:idx_links_domain_short_code,priority:1;not null;default:''"`
+	LongURL      string `json:"long_url" gorm:"not null"`
+	CanonicalURL string `json:"-" gorm:"not null;default:'';index"`
+	Reused       bool   `json:"-" gorm:"-"`
+
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	NotBefore  *time.Time `json:"not_before,omitempty"`
+	MaxClicks  int        `json:"max_clicks,omitempty" gorm:"not null;default:0"`
+	ClicksUsed int        `json:"clicks_used" gorm:"not null;default:0"`
+
+	PasswordHash string `json:"-"`
+
+	Disabled bool `json:"disabled" gorm:"not null;default:false"`
+
+	IOSURL     string `json:"ios_url,omitempty" gorm:"column:ios_url"`
+	AndroidURL string `json:"android_url,omitempty" gorm:"column:android_url"`
+	DesktopURL string `json:"desktop_url,omitempty" gorm:"column:desktop_url"`
+
+	CountryURLs map[string]string `json:"country_urls,omitempty" gorm:"column:country_urls;serializer:json"`
+
+	Schedule []ScheduledDestination `json:"schedule,omitempty" gorm:"serializer:json"`
+	Rules    []RedirectRule         `json:"rules,omitempty" gorm:"serializer:json"`
+
+	Variants       []SplitVariant `json:"variants,omitempty" gorm:"serializer:json"`
+	StickyVariants bool           `json:"sticky_variants" gorm:"not null;default:false"`
+
+	RedirectType RedirectType `json:"redirect_type,omitempty" gorm:"size:16"`
+
+	ForwardQuery bool      `json:"forward_query" gorm:"not null;default:false"`
+	UTM          UTMParams `json:"utm" gorm:"embedded;embeddedPrefix:utm_"`
+
+	FlaggedAt   *time.Time `json:"flagged_at,omitempty" gorm:"index"`
+	ThreatFeed  string     `json:"threat_feed,omitempty"`
+	ThreatEntry string     `json:"threat_entry,omitempty"`
+
+	Metadata LinkMetadata `json:"metadata" gorm:"embedded;embeddedPrefix:meta_"`
+}
+
+// QualifiedCode identifie le lien parmi tous les domaines : "domaine/code" pour un domaine de marque,
+// le code seul pour le domaine par défaut.
+func (l *Link) QualifiedCode() string {
+	if l.Domain == "" {
+		return l.ShortCode
+	}
+	return l.Domain + "/" + l.ShortCode
+}
+
+// IsFlagged indique si une destination du lien figure dans un flux de menaces.
+func (l *Link) IsFlagged() bool {
+	return l.FlaggedAt != nil
+}
+
+// IsProtected indique si le lien exige un mot de passe avant la redirection.
+func (l *Link) IsProtected() bool {
+	return l.PasswordHash != ""
+}
+
+// IsExpired indique si la date d'expiration du lien est dépassée à l'instant 'now'.
+func (l *Link) IsExpired(now time.Time) bool {
+	return l.ExpiresAt != nil && !now.Before(*l.ExpiresAt)
+}
+
+// IsNotYetActive indique si la date d'activation du lien n'est pas encore atteinte à l'instant 'now'.
+func (l *Link) IsNotYetActive(now time.Time) bool {
+	return l.NotBefore != nil && now.Before(*l.NotBefore)
+}
+
+// ScheduledDestination retourne la première destination programmée active à l'instant 'now'.
+func (l *Link) ScheduledDestination(now time.Time) (string, bool) {
+	for _, entry := range l.Schedule {
+		if entry.ActiveAt(now) {
+			return entry.URL, true
+		}
+	}
+	return "", false
+}
+
+// VariesPerVisitor indique si la destination du lien peut dépendre du visiteur ou de l'instant de la visite
+// (règles, plateformes, pays, tests A/B, programmation, query string transmise ou mot de passe).
+// Une redirection permanente d'un tel lien ne doit pas être conservée par les caches partagés.
+func (l *Link) VariesPerVisitor() bool {
+	return len(l.Rules) > 0 || len(l.Schedule) > 0 || len(l.CountryURLs) > 0 || len(l.Variants) > 0 ||
+		l.IOSURL != "" || l.AndroidURL != "" || l.DesktopURL != "" || l.ForwardQuery || l.IsProtected()
+}
+
+// HasServerSideLimits indique si chaque visite doit repasser par le service pour que les limites du lien
+// s'appliquent (budget de clics, date d'activation, désactivation ou mot de passe). La redirection
+// d'un tel lien ne doit être conservée par aucun cache.
+func (l *Link) HasServerSideLimits() bool {
+	return l.MaxClicks > 0 || l.NotBefore != nil || l.Disabled || l.IsProtected()
+}
+
+// Destinations retourne toutes les URL vers lesquelles le lien peut rediriger : LongURL puis les destinations
+// par plateforme, par pays (dans l'ordre des codes pays), programmées, de test A/B et des règles.
+func (l *Link) Destinations() []string {
+	destinations := []string{l.LongURL}
+	for _, target := range []string{l.IOSURL, l.AndroidURL, l.DesktopURL} {
+		if target != "" {
+			destinations = append(destinations, target)
+		}
+	}
+	for _, country := range slices.Sorted(maps.Keys(l.CountryURLs)) {
+		destinations = append(destinations, l.CountryURLs[country])
+	}
+	for _, entry := range l.Schedule {
+		destinations = append(destinations, entry.URL)
+	}
+	for _, variant := range l.Variants {
+		destinations = append(destinations, variant.URL)
+	}
+	for _, rule := range l.Rules {
+		destinations = append(destinations, rule.URL)
+	}
+	return destinations
+}
+
+// RemainingClicks retourne le nombre de clics restants et false si le lien n'a pas de budget.
+func (l *Link) RemainingClicks() (int, bool) {
+	if l.MaxClicks <= 0 {
+		return 0, false
+	}
+	remaining := l.MaxClicks - l.ClicksUsed
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining, true
+}
+
+// ScheduledDestination est une destination utilisée pendant une plage horaire [From, Until[.
+// Une borne nil laisse la plage ouverte de ce côté.
+type ScheduledDestination struct {
+	From  *time.Time `json:"from,omitempty"`
+	Until *time.Time `json:"until,omitempty"`
+	URL   string     `json:"url"`
+}
+
+// ActiveAt indique si l'instant 'now' se trouve dans la plage horaire de la destination.
+func (d ScheduledDestination) ActiveAt(now time.Time) bool {
+	return (d.From == nil || !now.Before(*d.From)) && (d.Until == nil || now.Before(*d.Until))
+}
+
+// SplitVariant est une destination d'un test A/B : elle reçoit une part du trafic proportionnelle à Weight.
+type SplitVariant struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Weight int    `json:"weight"`
+}
+
+// UTMParams regroupe les paramètres UTM attachés à un lien et ajoutés à la destination lors de la redirection.
+type UTMParams struct {
+	Source   string `json:"utm_source,omitempty"`
+	Medium   string `json:"utm_medium,omitempty"`
+	Campaign string `json:"utm_campaign,omitempty"`
+}
+
+// Values retourne les paramètres UTM renseignés, indexés par leur nom dans la query string.
+func (u UTMParams) Values() map[string]string {
+	values := make(map[string]string, 3)
+	for name, value := range map[string]string{
+		"utm_source":   u.Source,
+		"utm_medium":   u.Medium,
+		"utm_campaign": u.Campaign,
+	} {
+		if value != "" {
+			values[name] = value
+		}
+	}
+	return values
 }
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// LinkMetadata regroupe les informations extraites de la page de destination d'un lien :
+// balise <title>, meta description, balises Open Graph et favicon.
+// Elle est stockée dans les colonnes meta_* de la table links (voir Link.Metadata).
+type LinkMetadata struct {
+	Title         string     `json:"title,omitempty"`
+	Description   string     `json:"description,omitempty"`
+	OGTitle       string     `json:"og_title,omitempty"`
+	OGDescription string     `json:"og_description,omitempty"`
+	OGImage       string     `json:"og_image,omitempty"`
+	OGSiteName    string     `json:"og_site_name,omitempty"`
+	FaviconURL    string     `json:"favicon_url,omitempty"`
+	FetchedAt     *time.Time `json:"fetched_at,omitempty"` // nil tant que la page n'a pas été récupérée avec succès
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// LinkRevision conserve l'historique des changements de destination d'un lien.
+// GORM utilisera ces tags pour créer la table 'link_revisions'.
+type LinkRevision struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`                // Clé primaire
+	LinkID    uint      `json:"link_id" gorm:"index"`                // Clé étrangère vers la table 'links'
+	Link      Link      `json:"-" gorm:"foreignKey:LinkID"`          // Relation GORM vers le lien modifié
+	OldURL    string    `json:"old_url" gorm:"not null"`             // Destination avant le changement
+	NewURL    string    `json:"new_url" gorm:"not null"`             // Destination après le changement
+	ChangedBy string    `json:"changed_by" gorm:"size:100;not null"` // Auteur du changement (utilisateur CLI ou client API)
+	CreatedAt time.Time `json:"created_at"`                          // Horodatage du changement
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// PurgedCode mémorise le code court d'un lien définitivement supprimé, sur son domaine.
+// Le code reste en quarantaine (non réattribuable) pendant une durée configurable,
+// pour qu'un ancien QR code ou lien imprimé ne pointe pas soudainement ailleurs.
+type PurgedCode struct {
+	Domain    string    `gorm:"primaryKey;default:''"` // Domaine court du lien purgé (vide = domaine par défaut)
+	ShortCode string    `gorm:"primaryKey"`            // Code court libéré par la purge
+	PurgedAt  time.Time `gorm:"index"`                 // Date de la suppression définitive
+}
@@ -0,0 +1,38 @@
+package models
+
+// RedirectRule est une règle de redirection déclarative attachée à un lien.
+// Les règles d'un lien sont évaluées dans l'ordre : la première dont toutes les conditions
+// sont remplies fournit la destination. Elles sont stockées en JSON dans la colonne rules.
+type RedirectRule struct {
+	Name string         `json:"name,omitempty"` // Nom libre, repris dans les réponses de dry-run
+	When RuleConditions `json:"when"`
+	URL  string         `json:"url"`
+}
+
+// RuleConditions regroupe les conditions d'une règle. Toutes les conditions renseignées doivent
+// être remplies ; au sein d'une liste, une seule valeur suffit. Une règle sans condition s'applique toujours.
+type RuleConditions struct {
+	Headers   map[string]string `json:"headers,omitempty"`   // Nom d'en-tête -> motif glob insensible à la casse ("*" = présent)
+	Query     map[string]string `json:"query,omitempty"`     // Paramètre de l'URL courte -> motif glob ("*" = présent)
+	Languages []string          `json:"languages,omitempty"` // Langues acceptées (Accept-Language), "fr" couvre "fr-CA"
+	CIDRs     []string          `json:"cidrs,omitempty"`     // Plages d'adresses IP du visiteur
+	Countries []string          `json:"countries,omitempty"` // Codes pays ISO 3166-1 alpha-2 (base GeoIP requise)
+	Platforms []string          `json:"platforms,omitempty"` // ios, android, desktop ou other
+	Weekdays  []string          `json:"weekdays,omitempty"`  // Jours de la semaine en UTC : mon, tue, wed, thu, fri, sat, sun
+	Hours     *HourRange        `json:"hours,omitempty"`     // Plage horaire en UTC
+}
+
+// HourRange est une plage horaire [From, To[ en heures UTC (0 à 24).
+// Une plage dont From est supérieur à To passe minuit (ex: 22 -> 6).
+type HourRange struct {
+	From int `json:"from"`
+	To   int `json:"to"`
+}
+
+// Contains indique si l'heure 'hour' (0 à 23) appartient à la plage.
+func (h HourRange) Contains(hour int) bool {
+	if h.From <= h.To {
+		return hour >= h.From && hour < h.To
+	}
+	return hour >= h.From || hour < h.To
+}
@@ -0,0 +1,46 @@
+package models
+
+import "net/http"
+
+// RedirectType est la manière dont un lien redirige ses visiteurs : un code HTTP 3xx
+// ou une page HTML à rafraîchissement automatique (meta refresh).
+type RedirectType string
+
+// Types de redirection acceptés. Un type vide désigne le type par défaut configuré sur le serveur.
+const (
+	RedirectMovedPermanently  RedirectType = "301"
+	RedirectFound             RedirectType = "302"
+	RedirectTemporaryRedirect RedirectType = "307"
+	RedirectPermanentRedirect RedirectType = "308"
+	RedirectMetaRefresh       RedirectType = "meta-refresh"
+)
+
+// IsValid indique si le type de redirection est reconnu.
+func (t RedirectType) IsValid() bool {
+	switch t {
+	case RedirectMovedPermanently, RedirectFound, RedirectTemporaryRedirect, RedirectPermanentRedirect, RedirectMetaRefresh:
+		return true
+	}
+	return false
+}
+
+// IsPermanent indique si la redirection est permanente (301 ou 308) et peut donc être mise en cache.
+func (t RedirectType) IsPermanent() bool {
+	return t == RedirectMovedPermanently || t == RedirectPermanentRedirect
+}
+
+// StatusCode retourne le code HTTP de la redirection, ou 200 pour une page meta refresh.
+func (t RedirectType) StatusCode() int {
+	switch t {
+	case RedirectMovedPermanently:
+		return http.StatusMovedPermanently
+	case RedirectTemporaryRedirect:
+		return http.StatusTemporaryRedirect
+	case RedirectPermanentRedirect:
+		return http.StatusPermanentRedirect
+	case RedirectMetaRefresh:
+		return http.StatusOK
+	default:
+		return http.StatusFound
+	}
+}
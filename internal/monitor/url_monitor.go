@@ -10,26 +10,36 @@ import (
 	"github.com/axellelanca/urlshortener/internal/repository" // Importe le repository de liens
 )
 
+// DefaultTimeout borne la durée d'une vérification d'accessibilité.
+const DefaultTimeout = 5 * time.Second
+
 // UrlMonitor gère la surveillance périodique des URLs longues.
 type UrlMonitor struct {
 	linkRepo    repository.LinkRepository // Pour récupérer les URLs à surveiller
 	interval    time.Duration             // Intervalle entre chaque vérification (ex: 5 minutes)
+	client      *http.Client              // Client des requêtes HEAD, voir SetHTTPClient
 	knownStates map[uint]bool             // État connu de chaque URL: map[LinkID]estAccessible (true/false)
 	mu          sync.Mutex                // Mutex pour protéger l'accès concurrentiel à knownStates
 }
 
-// TODO finir cette fonction
 // NewUrlMonitor crée et retourne une nouvelle instance de UrlMonitor.
 // Attention: retourne un pointeur
 func NewUrlMonitor(linkRepo repository.LinkRepository, interval time.Duration) *UrlMonitor {
 	return &UrlMonitor{
 		linkRepo:    linkRepo,
 		interval:    interval,
+		client:      &http.Client{Timeout: DefaultTimeout},
 		knownStates: make(map[uint]bool),
 		mu:          sync.Mutex{},
 	}
 }
 
+// SetHTTPClient remplace le client utilisé pour vérifier les URLs, par exemple un client qui refuse
+// de se connecter aux adresses internes (voir safety.Policy.HTTPClient).
+func (m *UrlMonitor) SetHTTPClient(client *http.Client) {
+	m.client = client
+}
+
 // Start lance la boucle de surveillance périodique des URLs.
 // Cette fonction est conçue pour être lancée dans une goroutine séparée.
 func (m *UrlMonitor) Start() {
@@ -50,9 +60,7 @@ func (m *UrlMonitor) Start() {
 func (m *UrlMonitor) checkUrls() {
 	log.Println("[MONITOR] Lancement de la vérification de l'état des URLs...")
 
-	// TODO : Récupérer toutes les URLs longues actives depuis le linkRepo (GetAllLinks).
-	// Gérer l'erreur si la récupération échoue.
-	// Si erreur : log.Printf("[MONITOR] ERREUR lors de la récupération des liens pour la surveillance : %v", err)
+	// Récupérer toutes les URLs longues actives depuis le linkRepo.
 	links, err := m.linkRepo.GetAllLinks()
 	if err != nil {
 		log.Printf("[MONITOR] ERREUR lors de la récupération des liens pour la surveillance : %v", err)
@@ -60,7 +68,6 @@ func (m *UrlMonitor) checkUrls() {
 	}
 
 	for _, link := range links {
-		// TODO : Pour chaque lien, vérifier son accessibilité (isUrlAccessible).
 		currentState := m.isUrlAccessible(link.LongURL)
 
 		// Protéger l'accès à la map 'knownStates' car 'checkUrls' peut être exécuté concurremment
@@ -76,9 +83,7 @@ func (m *UrlMonitor) checkUrls() {
 			continue
 		}
 
-		// TODO : Comparer l'état actuel avec l'état précédent.
 		// Si l'état a changé, générer une fausse notification dans les logs.
-		// log.Printf("[NOTIFICATION] Le lien %s (%s) est passé de %s à %s !"
 		if currentState != previousState {
 			log.Printf("[NOTIFICATION] Le lien %s (%s) est passé de %s à %s !",
 				link.ShortCode, link.LongURL, formatState(previousState), formatState(currentState))
@@ -87,22 +92,27 @@ func (m *UrlMonitor) checkUrls() {
 	log.Println("[MONITOR] Vérification de l'état des URLs terminée.")
 }
 
+// State retourne le dernier état d'accessibilité enregistré pour un lien.
+// 'known' vaut false si le lien n'a pas encore été vérifié depuis le démarrage du moniteur.
+func (m *UrlMonitor) State(linkID uint) (accessible bool, known bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	accessible, known = m.knownStates[linkID]
+	return accessible, known
+}
+
 // isUrlAccessible effectue une requête HTTP HEAD pour vérifier l'accessibilité d'une URL.
 func (m *UrlMonitor) isUrlAccessible(url string) bool {
-	// TODO Définir un timeout pour éviter de bloquer trop longtemps (5 secondes c'est bien)
-	client := &http.Client{
-		Timeout: 5 * time.Second,
-	}
-	// TODO: Effectuer une requête HEAD (plus légère que GET) sur l'URL.
+	// Le client est créé par NewUrlMonitor avec DefaultTimeout, ou fourni par SetHTTPClient.
+	client := m.client
+	// Effectuer une requête HEAD (plus légère que GET) sur l'URL.
 	// Un code de statut 2xx ou 3xx indique que l'URL est accessible.
-	// Si err : log.Printf("[MONITOR] Erreur d'accès à l'URL '%s': %v", url, err)
 	resp, err := client.Head(url)
 	if err != nil {
 		log.Printf("[MONITOR] Erreur d'accès à l'URL '%s': %v", url, err)
 		return false
 	}
 
-	// TODO Assurez-vous de fermer le corps de la réponse pour libérer les ressources
 	defer resp.Body.Close()
 
 	// Déterminer l'accessibilité basée sur le code de statut HTTP.
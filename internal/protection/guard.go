@@ -0,0 +1,129 @@
+package protection
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxTrackedIPs est le nombre d'IPs suivies au-delà duquel les fenêtres expirées sont purgées.
+const maxTrackedIPs = 1024
+
+// Guard regroupe ce dont les liens protégés par mot de passe ont besoin côté HTTP :
+// la signature des cookies de déverrouillage et la limitation des tentatives par IP.
+type Guard struct {
+	secret      []byte        // Clé HMAC utilisée pour signer les jetons de déverrouillage
+	unlockTTL   time.Duration // Durée de validité d'un déverrouillage réussi
+	maxAttempts int           // Nombre d'échecs tolérés par IP dans la fenêtre
+	window      time.Duration // Fenêtre de comptage des échecs
+
+	mu       sync.Mutex               // Protège l'accès concurrentiel à attempts
+	attempts map[string]*attemptState // Échecs récents par adresse IP
+}
+
+// attemptState mémorise les échecs d'une IP depuis le début de sa fenêtre courante.
+type attemptState struct {
+	failures    int
+	windowStart time.Time
+}
+
+// NewGuard crée et retourne une nouvelle instance de Guard.
+func NewGuard(secret []byte, unlockTTL time.Duration, maxAttempts int, window time.Duration) *Guard {
+	return &Guard{
+		secret:      secret,
+		unlockTTL:   unlockTTL,
+		maxAttempts: maxAttempts,
+		window:      window,
+		attempts:    make(map[string]*attemptState),
+	}
+}
+
+// UnlockTTL retourne la durée de validité d'un déverrouillage.
+func (g *Guard) UnlockTTL() time.Duration {
+	return g.unlockTTL
+}
+
+// IssueToken génère un jeton signé prouvant que le lien 'linkID' a été déverrouillé.
+// 'fingerprint' lie le jeton au mot de passe courant : changer le mot de passe invalide les jetons existants.
+func (g *Guard) IssueToken(linkID uint, fingerprint string, now time.Time) string {
+	expiresAt := now.Add(g.unlockTTL).Unix()
+	payload := fmt.Sprintf("%d.%d", linkID, expiresAt)
+	return payload + "." + g.sign(payload, fingerprint)
+}
+
+// VerifyToken vérifie la signature et l'expiration d'un jeton émis par IssueToken.
+func (g *Guard) VerifyToken(token string, linkID uint, fingerprint string, now time.Time) bool {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return false
+	}
+	if parts[0] != strconv.FormatUint(uint64(linkID), 10) {
+		return false
+	}
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || now.Unix() >= expiresAt {
+		return false
+	}
+	expected := g.sign(parts[0]+"."+parts[1], fingerprint)
+	return hmac.Equal([]byte(parts[2]), []byte(expected))
+}
+
+// sign calcule la signature HMAC-SHA256 (base64 URL) du payload associé à l'empreinte du mot de passe.
+func (g *Guard) sign(payload, fingerprint string) string {
+	mac := hmac.New(sha256.New, g.secret)
+	mac.Write([]byte(payload))
+	mac.Write([]byte{0})
+	mac.Write([]byte(fingerprint))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Allow indique si l'IP peut encore tenter un mot de passe dans la fenêtre courante.
+func (g *Guard) Allow(ip string, now time.Time) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	state, exists := g.attempts[ip]
+	if !exists {
+		return true
+	}
+	if now.Sub(state.windowStart) >= g.window {
+		delete(g.attempts, ip)
+		return true
+	}
+	return state.failures < g.maxAttempts
+}
+
+// RecordFailure comptabilise un échec de mot de passe pour l'IP.
+func (g *Guard) RecordFailure(ip string, now time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	state, exists := g.attempts[ip]
+	if !exists || now.Sub(state.windowStart) >= g.window {
+		state = &attemptState{windowStart: now}
+		g.attempts[ip] = state
+	}
+	state.failures++
+
+	// Purge les fenêtres expirées pour que la map ne grossisse pas indéfiniment.
+	if len(g.attempts) < maxTrackedIPs {
+		return
+	}
+	for key, other := range g.attempts {
+		if now.Sub(other.windowStart) >= g.window {
+			delete(g.attempts, key)
+		}
+	}
+}
+
+// Reset efface les échecs d'une IP après un déverrouillage réussi.
+func (g *Guard) Reset(ip string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.attempts, ip)
+}
@@ -0,0 +1,110 @@
+package protection
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifyToken(t *testing.T) {
+	guard := NewGuard([]byte("secret"), time.Hour, 5, time.Minute)
+	now := time.Unix(1_700_000_000, 0)
+	token := guard.IssueToken(42, "fingerprint", now)
+	parts := strings.Split(token, ".")
+
+	tests := []struct {
+		name        string
+		guard       *Guard
+		token       string
+		linkID      uint
+		fingerprint string
+		at          time.Time
+		want        bool
+	}{
+		{"valid token", guard, token, 42, "fingerprint", now, true},
+		{"just before expiry", guard, token, 42, "fingerprint", now.Add(time.Hour - time.Second), true},
+		{"expired", guard, token, 42, "fingerprint", now.Add(time.Hour), false},
+		{"other link", guard, token, 43, "fingerprint", now, false},
+		{"password changed", guard, token, 42, "new fingerprint", now, false},
+		{"other secret", NewGuard([]byte("other"), time.Hour, 5, time.Minute), token, 42, "fingerprint", now, false},
+		{"extended expiry", guard, fmt.Sprintf("%s.%d.%s", parts[0], now.Add(48*time.Hour).Unix(), parts[2]), 42, "fingerprint", now.Add(2 * time.Hour), false},
+		{"tampered signature", guard, parts[0] + "." + parts[1] + ".x" + parts[2][1:], 42, "fingerprint", now, false},
+		{"malformed expiry", guard, parts[0] + ".soon." + parts[2], 42, "fingerprint", now, false},
+		{"missing part", guard, parts[0] + "." + parts[1], 42, "fingerprint", now, false},
+		{"empty token", guard, "", 42, "fingerprint", now, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.guard.VerifyToken(tt.token, tt.linkID, tt.fingerprint, tt.at); got != tt.want {
+				t.Errorf("VerifyToken(%q, %d, %q) = %v, want %v", tt.token, tt.linkID, tt.fingerprint, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttemptLimit(t *testing.T) {
+	const ip = "203.0.113.7"
+	start := time.Unix(1_700_000_000, 0)
+
+	tests := []struct {
+		name      string
+		failures  int
+		reset     bool
+		checkedAt time.Duration // Délai après le premier échec
+		want      bool
+	}{
+		{name: "no failure", failures: 0, want: true},
+		{name: "below the limit", failures: 2, checkedAt: time.Second, want: true},
+		{name: "limit reached", failures: 3, checkedAt: time.Second, want: false},
+		{name: "limit reached at the end of the window", failures: 3, checkedAt: time.Minute - time.Second, want: false},
+		{name: "window expired", failures: 3, checkedAt: time.Minute, want: true},
+		{name: "reset after a successful unlock", failures: 3, reset: true, checkedAt: time.Second, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			guard := NewGuard([]byte("secret"), time.Hour, 3, time.Minute)
+			for range tt.failures {
+				guard.RecordFailure(ip, start)
+			}
+			if tt.reset {
+				guard.Reset(ip)
+			}
+			if got := guard.Allow(ip, start.Add(tt.checkedAt)); got != tt.want {
+				t.Errorf("Allow after %d failures = %v, want %v", tt.failures, got, tt.want)
+			}
+			if !guard.Allow("198.51.100.1", start) {
+				t.Error("Allow blocked an IP without failures")
+			}
+		})
+	}
+}
+
+func TestRecordFailureStartsNewWindow(t *testing.T) {
+	const ip = "203.0.113.7"
+	guard := NewGuard([]byte("secret"), time.Hour, 2, time.Minute)
+	start := time.Unix(1_700_000_000, 0)
+
+	guard.RecordFailure(ip, start)
+	guard.RecordFailure(ip, start.Add(time.Minute)) // Ouvre une nouvelle fenêtre : un seul échec y est compté
+	if !guard.Allow(ip, start.Add(time.Minute+time.Second)) {
+		t.Fatal("failures of an expired window were carried over")
+	}
+	guard.RecordFailure(ip, start.Add(time.Minute+time.Second))
+	if guard.Allow(ip, start.Add(time.Minute+2*time.Second)) {
+		t.Fatal("Allow = true after reaching the limit in the new window")
+	}
+}
+
+func TestRecordFailurePurgesExpiredWindows(t *testing.T) {
+	guard := NewGuard([]byte("secret"), time.Hour, 3, time.Minute)
+	start := time.Unix(1_700_000_000, 0)
+	for i := range maxTrackedIPs - 1 {
+		guard.RecordFailure(fmt.Sprintf("ip-%d", i), start)
+	}
+	guard.RecordFailure("late", start.Add(time.Minute))
+
+	if got := len(guard.attempts); got != 1 {
+		t.Errorf("%d IPs tracked after the purge, want 1", got)
+	}
+}
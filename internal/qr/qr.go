@@ -0,0 +1,201 @@
+// Package qr génère les QR codes des liens courts, en PNG ou en SVG, sans service externe.
+// L'encodage est délégué à github.com/skip2/go-qrcode ; le rendu (taille, marge, couleurs)
+// est fait ici afin de maîtriser la zone calme et de produire du SVG.
+package qr
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// Format est le format d'image produit.
+type Format string
+
+// Formats d'image disponibles.
+const (
+	FormatPNG Format = "png"
+	FormatSVG Format = "svg"
+)
+
+// Bornes des options de rendu.
+const (
+	MinSize   = 64
+	MaxSize   = 4096
+	MaxMargin = 16
+)
+
+// ErrInvalidOptions est retournée quand une option de rendu est invalide.
+var ErrInvalidOptions = errors.New("invalid QR code options")
+
+// Options décrit le rendu d'un QR code.
+type Options struct {
+	Format     Format
+	Size       int    // Largeur et hauteur de l'image en pixels
+	Level      string // Niveau de correction d'erreur : L (7%), M (15%), Q (25%) ou H (30%)
+	Margin     int    // Zone calme autour du code, en modules (4 recommandés par la norme)
+	Foreground color.NRGBA
+	Background color.NRGBA
+}
+
+// DefaultOptions retourne les options par défaut : PNG de 256 pixels, niveau M, marge de 4 modules, noir sur blanc.
+func DefaultOptions() Options {
+	return Options{
+		Format:     FormatPNG,
+		Size:       256,
+		Level:      "M",
+		Margin:     4,
+		Foreground: color.NRGBA{A: 0xff},
+		Background: color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+	}
+}
+
+// ContentType retourne le type MIME du format.
+func (f Format) ContentType() string {
+	if f == FormatSVG {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+// ParseFormat convertit "png" ou "svg" (insensible à la casse) en Format.
+func ParseFormat(value string) (Format, error) {
+	switch f := Format(strings.ToLower(value)); f {
+	case FormatPNG, FormatSVG:
+		return f, nil
+	}
+	return "", fmt.Errorf("%w: unknown format '%s' (expected png or svg)", ErrInvalidOptions, value)
+}
+
+// ParseColor convertit une couleur hexadécimale ("#RGB", "#RRGGBB" ou "#RRGGBBAA", '#' optionnel).
+func ParseColor(value string) (color.NRGBA, error) {
+	hex := strings.TrimPrefix(value, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	n, err := strconv.ParseUint(hex, 16, 32)
+	if len(hex) != 8 || err != nil {
+		return color.NRGBA{}, fmt.Errorf("%w: invalid color '%s' (expected #RGB, #RRGGBB or #RRGGBBAA)", ErrInvalidOptions, value)
+	}
+	return color.NRGBA{R: uint8(n >> 24), G: uint8(n >> 16), B: uint8(n >> 8), A: uint8(n)}, nil
+}
+
+// recoveryLevel convertit le niveau de correction d'erreur en constante go-qrcode.
+func recoveryLevel(level string) (qrcode.RecoveryLevel, error) {
+	switch strings.ToUpper(level) {
+	case "L":
+		return qrcode.Low, nil
+	case "M":
+		return qrcode.Medium, nil
+	case "Q":
+		return qrcode.High, nil
+	case "H":
+		return qrcode.Highest, nil
+	}
+	return 0, fmt.Errorf("%w: unknown error correction level '%s' (expected L, M, Q or H)", ErrInvalidOptions, level)
+}
+
+// Write encode 'content' dans un QR code et écrit l'image dans 'w' selon 'opts'.
+func Write(w io.Writer, content string, opts Options) error {
+	if opts.Size < MinSize || opts.Size > MaxSize {
+		return fmt.Errorf("%w: size must be between %d and %d pixels", ErrInvalidOptions, MinSize, MaxSize)
+	}
+	if opts.Margin < 0 || opts.Margin > MaxMargin {
+		return fmt.Errorf("%w: margin must be between 0 and %d modules", ErrInvalidOptions, MaxMargin)
+	}
+	level, err := recoveryLevel(opts.Level)
+	if err != nil {
+		return err
+	}
+
+	code, err := qrcode.New(content, level)
+	if err != nil {
+		return fmt.Errorf("failed to encode QR code: %w", err)
+	}
+	code.DisableBorder = true // La zone calme est ajoutée au rendu, selon opts.Margin.
+	modules := code.Bitmap()
+
+	// Chaque module occupe un nombre entier de pixels ; le reste est réparti autour du code.
+	total := len(modules) + 2*opts.Margin
+	if opts.Size < total {
+		return fmt.Errorf("%w: size must be at least %d pixels for this content and margin", ErrInvalidOptions, total)
+	}
+
+	switch opts.Format {
+	case FormatSVG:
+		return writeSVG(w, modules, opts)
+	case FormatPNG, "":
+		return png.Encode(w, paint(modules, opts))
+	default:
+		return fmt.Errorf("%w: unknown format '%s' (expected png or svg)", ErrInvalidOptions, opts.Format)
+	}
+}
+
+// paint dessine les modules dans une image à deux couleurs de opts.Size pixels de côté.
+func paint(modules [][]bool, opts Options) image.Image {
+	scale := opts.Size / (len(modules) + 2*opts.Margin)
+	offset := (opts.Size - len(modules)*scale) / 2
+
+	img := image.NewPaletted(image.Rect(0, 0, opts.Size, opts.Size), color.Palette{opts.Background, opts.Foreground})
+	for y, row := range modules {
+		for x, dark := range row {
+			if !dark {
+				continue
+			}
+			for py := offset + y*scale; py < offset+(y+1)*scale; py++ {
+				for px := offset + x*scale; px < offset+(x+1)*scale; px++ {
+					img.SetColorIndex(px, py, 1)
+				}
+			}
+		}
+	}
+	return img
+}
+
+// writeSVG écrit les modules sous forme de SVG vectoriel. Le viewBox est exprimé en modules,
+// chaque suite horizontale de modules sombres devenant un rectangle du chemin.
+func writeSVG(w io.Writer, modules [][]bool, opts Options) error {
+	total := len(modules) + 2*opts.Margin
+
+	var path strings.Builder
+	for y, row := range modules {
+		for x := 0; x < len(row); {
+			if !row[x] {
+				x++
+				continue
+			}
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			fmt.Fprintf(&path, "M%d %dh%dv1h-%dz", start+opts.Margin, y+opts.Margin, x-start, x-start)
+		}
+	}
+
+	_, err := fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">
+<rect width="%d" height="%d" %s/>
+<path d="%s" %s/>
+</svg>
+`, opts.Size, opts.Size, total, total, total, total, svgFill(opts.Background), path.String(), svgFill(opts.Foreground))
+	return err
+}
+
+// svgFill retourne les attributs SVG de remplissage d'une couleur, opacité comprise.
+func svgFill(c color.NRGBA) string {
+	fill := fmt.Sprintf(`fill="#%02x%02x%02x"`, c.R, c.G, c.B)
+	if c.A != 0xff {
+		fill += fmt.Sprintf(` fill-opacity="%.3f"`, float64(c.A)/0xff)
+	}
+	return fill
+}
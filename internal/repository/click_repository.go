@@ -34,8 +34,6 @@ func (r *GormClickRepository) CreateClick(click *models.Click) error {
 // Cette méthode est utilisée pour fournir des statistiques pour une URL courte.
 func (r *GormClickRepository) CountClicksByLinkID(linkID uint) (int, error) {
 	var count int64 // GORM retourne un int64 pour les décomptes
-	// TODO : Utiliser GORM pour compter les enregistrements dans la table 'clicks'
-	// où 'LinkID' correspond à l'ID de lien fourni.
 	err := r.db.Model(&models.Click{}).Where("link_id = ?", linkID).Count(&count).Error
 
 	return int(count), err // Convert the int64 count to an int
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Noms des compteurs persistants utilisés par la génération des codes courts.
+const (
+	CounterCodeAttempts   = "short_code_attempts"   // Codes générés proposés à l'insertion
+	CounterCodeCollisions = "short_code_collisions" // Codes générés déjà pris (lien existant ou quarantaine)
+)
+
+// CounterRepository donne accès aux compteurs persistants de la table code_counters.
+type CounterRepository interface {
+	Add(name string, delta uint64) (uint64, error)
+	Get(name string) (uint64, error)
+}
+
+// GormCounterRepository est l'implémentation de CounterRepository utilisant GORM.
+type GormCounterRepository struct {
+	db *gorm.DB
+}
+
+// NewCounterRepository crée et retourne une nouvelle instance de GormCounterRepository.
+func NewCounterRepository(db *gorm.DB) *GormCounterRepository {
+	return &GormCounterRepository{db: db}
+}
+
+// Add ajoute 'delta' au compteur 'name', créé à sa première utilisation, et retourne sa nouvelle valeur.
+// L'incrément et la lecture sont faits dans une même transaction : deux processus partageant
+// la base (serveur et CLI) n'obtiennent jamais la même valeur.
+func (r *GormCounterRepository) Add(name string, delta uint64) (uint64, error) {
+	var counter models.CodeCounter
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "name"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"value": gorm.Expr("value + ?", delta)}),
+		}).Create(&models.CodeCounter{Name: name, Value: delta}).Error
+		if err != nil {
+			return err
+		}
+		return tx.First(&counter, "name = ?", name).Error
+	})
+	return counter.Value, err
+}
+
+// Get retourne la valeur du compteur 'name' (0 s'il n'a jamais été utilisé).
+func (r *GormCounterRepository) Get(name string) (uint64, error) {
+	var counter models.CodeCounter
+	err := r.db.First(&counter, "name = ?", name).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	return counter.Value, err
+}
+
+// CounterSequence présente un compteur persistant comme une séquence (voir codegen.Sequence).
+type CounterSequence struct {
+	counters CounterRepository
+	name     string
+}
+
+// NewSequence retourne la séquence des valeurs du compteur 'name' : 1 au premier appel, puis 2, 3...
+func NewSequence(counters CounterRepository, name string) *CounterSequence {
+	return &CounterSequence{counters: counters, name: name}
+}
+
+// Next incrémente le compteur et retourne sa nouvelle valeur.
+func (s *CounterSequence) Next() (uint64, error) {
+	return s.counters.Add(s.name, 1)
+}
@@ -1,6 +1,11 @@
 package repository
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/axellelanca/urlshortener/internal/models"
 	"gorm.io/gorm"
 )
@@ -9,14 +14,51 @@ import (
 // pour les opérations CRUD sur les liens.
 type LinkRepository interface {
 	CreateLink(link *models.Link) error
-	GetLinkByShortCode(shortCode string) (*models.Link, error)
+	GetLinkByShortCode(domain, shortCode string) (*models.Link, error)
 	GetAllLinks() ([]models.Link, error)
 	CountClicksByLinkID(linkID uint) (int, error)
+	CountClicksByPlatform(linkID uint) (map[string]int, error)
+	CountClicksByVariant(linkID uint) (map[string]int, error)
+	ConsumeClick(linkID uint) (bool, error)
+	UpdateLongURL(link *models.Link, revision *models.LinkRevision) error
+	GetRevisionsByLinkID(linkID uint) ([]models.LinkRevision, error)
+	GetRevision(linkID, revisionID uint) (*models.LinkRevision, error)
+	GetLinkByShortCodeUnscoped(domain, shortCode string) (*models.Link, error)
+	ShortCodeExists(domain, shortCode string) (bool, error)
+	IsShortCodeQuarantined(domain, shortCode string) (bool, error)
+	DeleteLink(link *models.Link) error
+	RestoreLink(link *models.Link) error
+	SetDisabled(link *models.Link, disabled bool) error
+	PurgeDeletedLinks(deletedBefore, quarantineSince time.Time) ([]string, error)
+	UnavailableShortCodes(domain string, shortCodes []string) (map[string]bool, error)
+	CreateLinks(links []*models.Link) error
+	ListLinks(query LinkListQuery) ([]LinkWithClicks, error)
+	UpdateMetadata(linkID uint, longURL string, meta *models.LinkMetadata) error
+	UpdateRules(link *models.Link) error
+	CountShortCodesByLength() ([]ShortCodeCount, error)
+	FindLinksByCanonicalURL(domain, canonicalURL string) ([]models.Link, error)
+	FindDuplicateLinks(limit int) ([]models.Link, error)
+	UpdateThreatFlag(link *models.Link) error
+	ListFlaggedLinks() ([]models.Link, error)
 }
 
-// TODO :  GormLinkRepository est l'implémentation de LinkRepository utilisant GORM.
-type GormLinkRepository struct {
+// ErrShortCodeTaken est retournée à l'insertion d'un lien dont le code court est déjà porté par un autre
+// lien du même domaine. C'est l'index unique (domaine, code) qui en décide, sans fenêtre de concurrence.
+var ErrShortCodeTaken = errors.New("short code already taken")
+
+// ShortCodeCount est le nombre de codes indisponibles (liens, même supprimés, et codes en quarantaine)
+// d'une longueur donnée sur un domaine.
+type ShortCodeCount struct {
+	Domain string
+	Length int
+	Count  int64
+}
 
+// lookupChunkSize limite le nombre de paramètres envoyés dans une même clause IN.
+const lookupChunkSize = 500
+
+// GormLinkRepository est l'implémentation de LinkRepository utilisant GORM.
+type GormLinkRepository struct {
 	db *gorm.DB // GORM DB instance pour interagir avec la base de données
 
 }
@@ -24,25 +66,33 @@ type GormLinkRepository struct {
 // NewLinkRepository crée et retourne une nouvelle instance de GormLinkRepository.
 // Cette fonction retourne *GormLinkRepository, qui implémente l'interface LinkRepository.
 func NewLinkRepository(db *gorm.DB) *GormLinkRepository {
-	// TODO
-
-	return &GormLinkRepository{db: db} // Initialisation de GormLinkRepository avec la connexion DB
-
+	return &GormLinkRepository{db: db}
 }
 
 // CreateLink insère un nouveau lien dans la base de données.
+// Il retourne ErrShortCodeTaken si le code court est déjà utilisé sur le domaine du lien.
 func (r *GormLinkRepository) CreateLink(link *models.Link) error {
-	// TODO 1: Utiliser GORM pour créer un nouvel enregistrement (link) dans la table des liens.
-	return r.db.Create(link).Error
+	return r.translateError(r.db.Create(link).Error)
+}
+
+// translateError convertit une violation de l'index unique (domaine, code) en ErrShortCodeTaken.
+// C'est le seul index unique de la table links en dehors de la clé primaire.
+func (r *GormLinkRepository) translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if translator, ok := r.db.Dialector.(gorm.ErrorTranslator); ok && errors.Is(translator.Translate(err), gorm.ErrDuplicatedKey) {
+		return fmt.Errorf("%w: %v", ErrShortCodeTaken, err)
+	}
+	return err
 }
 
-// GetLinkByShortCode récupère un lien de la base de données en utilisant son shortCode.
-// Il renvoie gorm.ErrRecordNotFound si aucun lien n'est trouvé avec ce shortCode.
-func (r *GormLinkRepository) GetLinkByShortCode(shortCode string) (*models.Link, error) {
+// GetLinkByShortCode récupère un lien de la base de données en utilisant son shortCode sur un domaine
+// ("" = domaine par défaut). Il renvoie gorm.ErrRecordNotFound si aucun lien n'est trouvé avec ce shortCode.
+func (r *GormLinkRepository) GetLinkByShortCode(domain, shortCode string) (*models.Link, error) {
 	var link models.Link
-	// TODO 2: Utiliser GORM pour trouver un lien par son ShortCode.
 	// La méthode First de GORM recherche le premier enregistrement correspondant et le mappe à 'link'.
-	err := r.db.Where("short_code = ?", shortCode).First(&link).Error
+	err := r.db.Where("domain = ? AND short_code = ?", domain, shortCode).First(&link).Error
 	return &link, err
 }
 
@@ -50,7 +100,6 @@ func (r *GormLinkRepository) GetLinkByShortCode(shortCode string) (*models.Link,
 // Cette méthode est utilisée par le moniteur d'URLs.
 func (r *GormLinkRepository) GetAllLinks() ([]models.Link, error) {
 	var links []models.Link
-	// TODO 3: Utiliser GORM pour récupérer tous les liens.
 	result := r.db.Find(&links)
 	if result.Error != nil {
 		return nil, result.Error // Retourne l'erreur SQL
@@ -61,8 +110,371 @@ func (r *GormLinkRepository) GetAllLinks() ([]models.Link, error) {
 // CountClicksByLinkID compte le nombre total de clics pour un ID de lien donné.
 func (r *GormLinkRepository) CountClicksByLinkID(linkID uint) (int, error) {
 	var count int64 // GORM retourne un int64 pour les comptes
-	// TODO 4: Utiliser GORM pour compter les enregistrements dans la table 'clicks'
-	// où 'LinkID' correspond à l'ID du lien donné.
+	err := r.db.Model(&models.Click{}).Where("link_id = ?", linkID).Count(&count).Error
+
+	return int(count), err
+}
+
+// CountClicksByPlatform compte les clics d'un lien regroupés par plateforme.
+func (r *GormLinkRepository) CountClicksByPlatform(linkID uint) (map[string]int, error) {
+	return r.countClicksGroupedBy(linkID, "platform")
+}
+
+// CountClicksByVariant compte les clics d'un lien regroupés par variante A/B.
+func (r *GormLinkRepository) CountClicksByVariant(linkID uint) (map[string]int, error) {
+	return r.countClicksGroupedBy(linkID, "variant")
+}
+
+// countClicksGroupedBy compte les clics d'un lien regroupés par valeur d'une colonne de la table clicks.
+// 'column' est toujours un nom de colonne fixé par l'appelant, jamais une valeur externe.
+func (r *GormLinkRepository) countClicksGroupedBy(linkID uint, column string) (map[string]int, error) {
+	var rows []struct {
+		Value string
+		Count int
+	}
+	err := r.db.Model(&models.Click{}).
+		Select(column+" AS value, COUNT(*) AS count").
+		Where("link_id = ?", linkID).
+		Group(column).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.Value] = row.Count
+	}
+	return counts, nil
+}
+
+// ConsumeClick décompte un clic du budget d'un lien de manière atomique.
+// La mise à jour est conditionnelle : elle retourne false si le budget (max_clicks) est déjà épuisé,
+// ce qui évite de dépasser la limite lorsque plusieurs redirections arrivent en même temps.
+func (r *GormLinkRepository) ConsumeClick(linkID uint) (bool, error) {
+	result := r.db.Model(&models.Link{}).
+		Where("id = ? AND (max_clicks = 0 OR clicks_used < max_clicks)", linkID).
+		UpdateColumn("clicks_used", gorm.Expr("clicks_used + ?", 1))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// UpdateLongURL enregistre la nouvelle destination du lien (avec sa forme canonique et son signalement
+// par les flux de menaces) et la révision associée dans une même transaction,
+// afin que l'historique ne puisse jamais diverger de la valeur réellement stockée.
+func (r *GormLinkRepository) UpdateLongURL(link *models.Link, revision *models.LinkRevision) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(link).Select("long_url", "canonical_url", "flagged_at", "threat_feed", "threat_entry").Updates(link).Error; err != nil {
+			return err
+		}
+		return tx.Create(revision).Error
+	})
+}
+
+// FindLinksByCanonicalURL retourne les liens non supprimés d'un domaine dont la destination canonique
+// est 'canonicalURL', du plus ancien au plus récent.
+func (r *GormLinkRepository) FindLinksByCanonicalURL(domain, canonicalURL string) ([]models.Link, error) {
+	var links []models.Link
+	err := r.db.Where("domain = ? AND canonical_url = ?", domain, canonicalURL).Order("id").Find(&links).Error
+	return links, err
+}
+
+// FindDuplicateLinks retourne les liens non supprimés dont la destination canonique est partagée
+// par au moins un autre lien, tous domaines confondus, triés par destination puis par ancienneté.
+// 'limit' borne le nombre de destinations retenues (les plus partagées d'abord), 0 = sans limite.
+func (r *GormLinkRepository) FindDuplicateLinks(limit int) ([]models.Link, error) {
+	duplicated := r.db.Model(&models.Link{}).
+		Select("canonical_url").
+		Group("canonical_url").
+		Having("COUNT(*) > 1").
+		Order("COUNT(*) DESC, canonical_url")
+	if limit > 0 {
+		duplicated = duplicated.Limit(limit)
+	}
+
+	var links []models.Link
+	err := r.db.Where("canonical_url IN (?)", duplicated).Order("canonical_url, id").Find(&links).Error
+	return links, err
+}
+
+// UpdateThreatFlag enregistre le signalement du lien par un flux de menaces (ou sa levée si FlaggedAt est nil).
+func (r *GormLinkRepository) UpdateThreatFlag(link *models.Link) error {
+	return r.db.Model(link).Select("flagged_at", "threat_feed", "threat_entry").Updates(link).Error
+}
+
+// ListFlaggedLinks retourne les liens non supprimés signalés par un flux de menaces, les plus récemment signalés d'abord.
+func (r *GormLinkRepository) ListFlaggedLinks() ([]models.Link, error) {
+	var links []models.Link
+	err := r.db.Where("flagged_at IS NOT NULL").Order("flagged_at DESC, id").Find(&links).Error
+	return links, err
+}
+
+// UpdateMetadata enregistre les métadonnées récupérées pour un lien, uniquement si sa destination
+// est toujours 'longURL' : un résultat arrivé après un changement de destination est ignoré.
+func (r *GormLinkRepository) UpdateMetadata(linkID uint, longURL string, meta *models.LinkMetadata) error {
+	return r.db.Model(&models.Link{}).
+		Where("id = ? AND long_url = ?", linkID, longURL).
+		Updates(map[string]any{
+			"meta_title":          meta.Title,
+			"meta_description":    meta.Description,
+			"meta_og_title":       meta.OGTitle,
+			"meta_og_description": meta.OGDescription,
+			"meta_og_image":       meta.OGImage,
+			"meta_og_site_name":   meta.OGSiteName,
+			"meta_favicon_url":    meta.FaviconURL,
+			"meta_fetched_at":     meta.FetchedAt,
+		}).Error
+}
+
+// UpdateRules enregistre les règles de redirection du lien (colonne JSON rules).
+func (r *GormLinkRepository) UpdateRules(link *models.Link) error {
+	return r.db.Model(link).Select("rules").Updates(link).Error
+}
+
+// GetRevisionsByLinkID récupère l'historique des destinations d'un lien, du plus récent au plus ancien.
+func (r *GormLinkRepository) GetRevisionsByLinkID(linkID uint) ([]models.LinkRevision, error) {
+	var revisions []models.LinkRevision
+	err := r.db.Where("link_id = ?", linkID).Order("id DESC").Find(&revisions).Error
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// GetRevision récupère une révision précise d'un lien.
+// Il renvoie gorm.ErrRecordNotFound si la révision n'existe pas ou appartient à un autre lien.
+func (r *GormLinkRepository) GetRevision(linkID, revisionID uint) (*models.LinkRevision, error) {
+	var revision models.LinkRevision
+	err := r.db.Where("id = ? AND link_id = ?", revisionID, linkID).First(&revision).Error
+	return &revision, err
+}
+
+// GetLinkByShortCodeUnscoped récupère un lien par son domaine et son shortCode, y compris s'il a été supprimé (soft delete).
+func (r *GormLinkRepository) GetLinkByShortCodeUnscoped(domain, shortCode string) (*models.Link, error) {
+	var link models.Link
+	err := r.db.Unscoped().Where("domain = ? AND short_code = ?", domain, shortCode).First(&link).Error
+	return &link, err
+}
+
+// ShortCodeExists indique si un code court est déjà utilisé sur un domaine, y compris par un lien supprimé
+// mais pas encore purgé (l'index unique couvre aussi ces lignes).
+func (r *GormLinkRepository) ShortCodeExists(domain, shortCode string) (bool, error) {
+	var count int64
+	err := r.db.Unscoped().Model(&models.Link{}).Where("domain = ? AND short_code = ?", domain, shortCode).Count(&count).Error
+	return count > 0, err
+}
+
+// IsShortCodeQuarantined indique si un code court libéré par une purge est encore en quarantaine sur un domaine.
+// Les quarantaines expirées sont levées par PurgeDeletedLinks.
+func (r *GormLinkRepository) IsShortCodeQuarantined(domain, shortCode string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.PurgedCode{}).Where("domain = ? AND short_code = ?", domain, shortCode).Count(&count).Error
+	return count > 0, err
+}
+
+// DeleteLink place un lien à la corbeille (soft delete via gorm.Model.DeletedAt).
+// Ses clics et son historique sont conservés.
+func (r *GormLinkRepository) DeleteLink(link *models.Link) error {
+	return r.db.Delete(link).Error
+}
+
+// RestoreLink sort un lien de la corbeille et le réactive.
+func (r *GormLinkRepository) RestoreLink(link *models.Link) error {
+	return r.db.Unscoped().Model(link).Updates(map[string]interface{}{
+		"deleted_at": nil,
+		"disabled":   false,
+	}).Error
+}
+
+// SetDisabled active ou désactive un lien sans le supprimer.
+func (r *GormLinkRepository) SetDisabled(link *models.Link, disabled bool) error {
+	return r.db.Model(link).Update("disabled", disabled).Error
+}
+
+// PurgeDeletedLinks supprime définitivement les liens mis à la corbeille avant 'deletedBefore',
+// ainsi que leurs clics et révisions. Les codes libérés sont placés en quarantaine et les
+// quarantaines antérieures à 'quarantineSince' sont levées. Retourne les codes purgés (voir Link.QualifiedCode).
+func (r *GormLinkRepository) PurgeDeletedLinks(deletedBefore, quarantineSince time.Time) ([]string, error) {
+	var purged []string
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var links []models.Link
+		if err := tx.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore).Find(&links).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, link := range links {
+			if err := tx.Where("link_id = ?", link.ID).Delete(&models.Click{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("link_id = ?", link.ID).Delete(&models.LinkRevision{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Unscoped().Delete(&models.Link{}, link.ID).Error; err != nil {
+				return err
+			}
+			if err := tx.Save(&models.PurgedCode{Domain: link.Domain, ShortCode: link.ShortCode, PurgedAt: now}).Error; err != nil {
+				return err
+			}
+			purged = append(purged, link.QualifiedCode())
+		}
+
+		return tx.Where("purged_at <= ?", quarantineSince).Delete(&models.PurgedCode{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return purged, nil
+}
+
+// UnavailableShortCodes retourne, parmi les codes fournis, ceux déjà portés par un lien (même supprimé)
+// du domaine ou encore en quarantaine sur ce domaine. Les vérifications sont faites par lots, en quelques requêtes seulement.
+func (r *GormLinkRepository) UnavailableShortCodes(domain string, shortCodes []string) (map[string]bool, error) {
+	unavailable := make(map[string]bool)
+	for start := 0; start < len(shortCodes); start += lookupChunkSize {
+		end := min(start+lookupChunkSize, len(shortCodes))
+		chunk := shortCodes[start:end]
+
+		var used []string
+		if err := r.db.Unscoped().Model(&models.Link{}).Where("domain = ? AND short_code IN ?", domain, chunk).Pluck("short_code", &used).Error; err != nil {
+			return nil, err
+		}
+		var quarantined []string
+		if err := r.db.Model(&models.PurgedCode{}).Where("domain = ? AND short_code IN ?", domain, chunk).Pluck("short_code", &quarantined).Error; err != nil {
+			return nil, err
+		}
+
+		for _, code := range used {
+			unavailable[code] = true
+		}
+		for _, code := range quarantined {
+			unavailable[code] = true
+		}
+	}
+	return unavailable, nil
+}
+
+// CreateLinks insère plusieurs liens dans une seule transaction : soit tous sont créés, soit aucun.
+// Il retourne ErrShortCodeTaken si l'un des codes est déjà utilisé sur le domaine de son lien.
+func (r *GormLinkRepository) CreateLinks(links []*models.Link) error {
+	return r.translateError(r.db.Transaction(func(tx *gorm.DB) error {
+		return tx.Create(links).Error
+	}))
+}
+
+// CountShortCodesByLength compte, par domaine et par longueur, les codes courts indisponibles :
+// ceux des liens (corbeille comprise) et ceux en quarantaine.
+func (r *GormLinkRepository) CountShortCodesByLength() ([]ShortCodeCount, error) {
+	var counts []ShortCodeCount
+	err := r.db.Raw(`SELECT domain, length, SUM(count) AS count FROM (
+		SELECT domain, LENGTH(short_code) AS length, COUNT(*) AS count FROM links GROUP BY domain, LENGTH(short_code)
+		UNION ALL
+		SELECT domain, LENGTH(short_code) AS length, COUNT(*) AS count FROM purged_codes GROUP BY domain, LENGTH(short_code)
+	) GROUP BY domain, length ORDER BY domain, length`).Scan(&counts).Error
+	return counts, err
+}
+
+// LinkSortField désigne le critère de tri utilisé par ListLinks.
+type LinkSortField string
+
+// Critères de tri disponibles pour ListLinks. Le tri par date de création suit l'identifiant
+// auto-incrémenté, strictement croissant avec l'ordre d'insertion, ce qui garantit une pagination stable.
+const (
+	SortByCreatedAt LinkSortField = "created_at"
+	SortByClicks    LinkSortField = "clicks"
+)
+
+// LinkCursor repère le dernier élément d'une page : la page suivante commence juste après lui.
+type LinkCursor struct {
+	ClickCount int  // Nombre de clics du dernier élément (tri par clics uniquement)
+	ID         uint // Identifiant du dernier élément
+}
+
+// LinkListQuery décrit les filtres, le tri et la pagination d'une recherche de liens.
+type LinkListQuery struct {
+	CreatedFrom *time.Time    // Date de création minimale (incluse)
+	CreatedTo   *time.Time    // Date de création maximale (exclue)
+	Domain      string        // Domaine de destination (sous-domaines inclus)
+	ShortDomain *string       // Domaine court des liens ("" = domaine par défaut, nil = tous)
+	Search      string        // Sous-chaîne recherchée dans l'URL longue ou le code court
+	SortBy      LinkSortField // Critère de tri
+	Ascending   bool          // Ordre croissant (décroissant par défaut)
+	After       *LinkCursor   // Position de départ (nil = première page)
+	Limit       int           // Nombre maximum de liens retournés
+}
+
+// LinkWithClicks associe un lien à son nombre total de clics.
+type LinkWithClicks struct {
+	models.Link
+	ClickCount int `gorm:"column:click_count"`
+}
+
+// ListLinks recherche des liens (hors corbeille) selon les filtres fournis, triés et paginés par curseur.
+func (r *GormLinkRepository) ListLinks(query LinkListQuery) ([]LinkWithClicks, error) {
+	clickCount := "(SELECT COUNT(*) FROM clicks WHERE clicks.link_id = links.id)"
+	inner := r.db.Model(&models.Link{}).Select("links.*, " + clickCount + " AS click_count")
+
+	if query.CreatedFrom != nil {
+		inner = inner.Where("links.created_at >= ?", *query.CreatedFrom)
+	}
+	if query.CreatedTo != nil {
+		inner = inner.Where("links.created_at < ?", *query.CreatedTo)
+	}
+	if query.Domain != "" {
+		// L'hôte est suivi d'une fin d'URL, d'un port, d'un chemin, d'une requête ou d'un fragment.
+		var conditions []string
+		var args []interface{}
+		for _, prefix := range []string{"%://", "%://%."} {
+			host := prefix + escapeLike(strings.ToLower(query.Domain))
+			conditions = append(conditions, `links.long_url LIKE ? ESCAPE '\'`)
+			args = append(args, host)
+			for _, suffix := range []string{":", "/", "?", "#"} {
+				conditions = append(conditions, `links.long_url LIKE ? ESCAPE '\'`)
+				args = append(args, host+suffix+"%")
+			}
+		}
+		inner = inner.Where(strings.Join(conditions, " OR "), args...)
+	}
+	if query.ShortDomain != nil {
+		inner = inner.Where("links.domain = ?", *query.ShortDomain)
+	}
+	if query.Search != "" {
+		pattern := "%" + escapeLike(query.Search) + "%"
+		inner = inner.Where(`links.long_url LIKE ? ESCAPE '\' OR links.short_code LIKE ? ESCAPE '\'`, pattern, pattern)
+	}
+
+	order, comparison := "DESC", "<"
+	if query.Ascending {
+		order, comparison = "ASC", ">"
+	}
+
+	outer := r.db.Table("(?) AS l", inner)
+	switch query.SortBy {
+	case SortByClicks:
+		if query.After != nil {
+			outer = outer.Where(
+				"l.click_count "+comparison+" ? OR (l.click_count = ? AND l.id "+comparison+" ?)",
+				query.After.ClickCount, query.After.ClickCount, query.After.ID,
+			)
+		}
+		outer = outer.Order("l.click_count " + order).Order("l.id " + order)
+	default:
+		if query.After != nil {
+			outer = outer.Where("l.id "+comparison+" ?", query.After.ID)
+		}
+		outer = outer.Order("l.id " + order)
+	}
+
+	var links []LinkWithClicks
+	if err := outer.Limit(query.Limit).Find(&links).Error; err != nil {
+		return nil, err
+	}
+	return links, nil
+}
 
-	return int(count), nil
+// escapeLike neutralise les caractères spéciaux d'un motif LIKE (à utiliser avec ESCAPE '\').
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
 }
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/urlnorm"
+	"gorm.io/gorm"
+)
+
+// Migrate crée ou met à jour le schéma de la base pour tous les modèles, puis renseigne
+// les formes canoniques des liens créés avant la détection des doublons.
+func Migrate(db *gorm.DB) error {
+	if err := db.AutoMigrate(&models.Link{}, &models.Click{}, &models.LinkRevision{}, &models.PurgedCode{}, &models.CodeCounter{}); err != nil {
+		return err
+	}
+	return backfillCanonicalURLs(db)
+}
+
+// backfillCanonicalURLs calcule la forme canonique de la destination des liens qui n'en ont pas encore
+// (créés avant la détection des doublons). La destination elle-même n'est jamais modifiée ; une URL
+// que urlnorm ne sait pas analyser sert telle quelle de forme canonique.
+func backfillCanonicalURLs(db *gorm.DB) error {
+	var links []models.Link
+	return db.Unscoped().Select("id", "long_url").Where("canonical_url = ''").
+		FindInBatches(&links, 500, func(_ *gorm.DB, _ int) error {
+			for _, link := range links {
+				canonical, err := urlnorm.Canonicalize(link.LongURL)
+				if err != nil {
+					canonical = link.LongURL
+				}
+				if err := db.Unscoped().Model(&models.Link{}).Where("id = ?", link.ID).
+					UpdateColumn("canonical_url", canonical).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+}
@@ -0,0 +1,284 @@
+// Package safety décide si une URL de destination peut être raccourcie : schémas autorisés,
+// listes de domaines autorisés ou refusés, et refus des adresses internes (privées, loopback,
+// link-local) pour qu'un lien ne puisse pas servir à atteindre le réseau du service.
+package safety
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Reason est le code, stable et lisible par une machine, d'un refus de destination.
+type Reason string
+
+const (
+	ReasonInvalidURL       Reason = "invalid_url"        // URL illisible, sans hôte ou adresse IP en notation non standard
+	ReasonSchemeNotAllowed Reason = "scheme_not_allowed" // Schéma absent de allowed_schemes (javascript:, file:, data:...)
+	ReasonDomainDenied     Reason = "domain_denied"      // Hôte présent dans denied_domains
+	ReasonDomainNotAllowed Reason = "domain_not_allowed" // Hôte absent de allowed_domains (quand la liste n'est pas vide)
+	ReasonPrivateAddress   Reason = "private_address"    // Adresse privée (RFC 1918, RFC 4193, CGNAT RFC 6598)
+	ReasonLoopbackAddress  Reason = "loopback_address"   // Adresse de bouclage (127.0.0.0/8, ::1)
+	ReasonLinkLocalAddress Reason = "link_local_address" // Adresse link-local (169.254.0.0/16, fe80::/10), métadonnées cloud comprises
+	ReasonReservedAddress  Reason = "reserved_address"   // Adresse non routable (0.0.0.0, multicast, broadcast)
+	ReasonUnresolvableHost Reason = "unresolvable_host"  // Résolution DNS en échec, avec reject_unresolvable
+	ReasonKnownThreat      Reason = "known_threat"       // Hôte ou URL listé dans un flux de menaces (voir le package threats)
+	ReasonSelfReference    Reason = "self_reference"     // Destination sur un domaine du service lui-même (voir le package chain)
+	ReasonRedirectLoop     Reason = "redirect_loop"      // Chaîne de raccourcisseurs qui boucle ou revient vers le service
+	ReasonTooManyRedirects Reason = "too_many_redirects" // Chaîne de raccourcisseurs plus longue que le nombre de redirections suivies
+)
+
+// DefaultResolveTimeout borne la résolution DNS d'un hôte quand Options.ResolveTimeout est nul.
+const DefaultResolveTimeout = 2 * time.Second
+
+// ErrInvalidOptions est retournée par New pour une configuration de politique invalide.
+var ErrInvalidOptions = errors.New("invalid destination policy")
+
+// Violation décrit le refus d'une destination : Reason est le code du refus, Detail l'explique.
+type Violation struct {
+	Reason Reason
+	URL    string
+	Detail string
+}
+
+// Error implémente l'interface error.
+func (v *Violation) Error() string {
+	return fmt.Sprintf("destination rejected (%s): %s", v.Reason, v.Detail)
+}
+
+// Resolver résout un nom d'hôte en adresses IP ; *net.Resolver le satisfait.
+type Resolver interface {
+	LookupNetIP(ctx context.Context, network, host string) ([]netip.Addr, error)
+}
+
+// Options configure une Policy.
+// AllowedSchemes liste les schémas acceptés (en minuscules, sans "://").
+// AllowedDomains (vide = tous) et DeniedDomains, prioritaire, contiennent des noms d'hôte exacts
+// ("example.com"), des jokers de sous-domaines ("*.example.com", qui n'inclut pas example.com) ou "*".
+// BlockPrivateAddresses refuse les hôtes qui sont, ou se résolvent en, une adresse interne ;
+// RejectUnresolvable refuse en plus les hôtes dont la résolution échoue.
+type Options struct {
+	AllowedSchemes        []string
+	AllowedDomains        []string
+	DeniedDomains         []string
+	BlockPrivateAddresses bool
+	RejectUnresolvable    bool
+	ResolveTimeout        time.Duration
+	Resolver              Resolver // nil = net.DefaultResolver
+}
+
+// Policy applique des Options à des URL de destination. Une Policy nil accepte tout.
+type Policy struct {
+	schemes            map[string]bool
+	allowed            []string
+	denied             []string
+	blockPrivate       bool
+	rejectUnresolvable bool
+	resolveTimeout     time.Duration
+	resolver           Resolver
+}
+
+// New valide les options et construit la politique correspondante.
+func New(opts Options) (*Policy, error) {
+	if len(opts.AllowedSchemes) == 0 {
+		return nil, fmt.Errorf("%w: at least one scheme must be allowed", ErrInvalidOptions)
+	}
+	p := &Policy{
+		schemes:            make(map[string]bool, len(opts.AllowedSchemes)),
+		blockPrivate:       opts.BlockPrivateAddresses,
+		rejectUnresolvable: opts.RejectUnresolvable,
+		resolveTimeout:     opts.ResolveTimeout,
+		resolver:           opts.Resolver,
+	}
+	for _, scheme := range opts.AllowedSchemes {
+		scheme = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(scheme), "://"))
+		if scheme == "" || strings.ContainsAny(scheme, ":/ ") {
+			return nil, fmt.Errorf("%w: invalid scheme %q", ErrInvalidOptions, scheme)
+		}
+		p.schemes[scheme] = true
+	}
+
+	var err error
+	if p.allowed, err = normalizePatterns(opts.AllowedDomains); err != nil {
+		return nil, err
+	}
+	if p.denied, err = normalizePatterns(opts.DeniedDomains); err != nil {
+		return nil, err
+	}
+
+	if p.resolveTimeout <= 0 {
+		p.resolveTimeout = DefaultResolveTimeout
+	}
+	if p.resolver == nil {
+		p.resolver = net.DefaultResolver
+	}
+	return p, nil
+}
+
+// normalizePatterns met les motifs de domaine en minuscules et vérifie que le joker n'apparaît
+// qu'en tête ("*.example.com") ou seul ("*").
+func normalizePatterns(patterns []string) ([]string, error) {
+	normalized := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(pattern)), ".")
+		suffix := strings.TrimPrefix(pattern, "*.")
+		if pattern == "" || (pattern != "*" && (suffix == "" || strings.ContainsAny(suffix, "*/: "))) {
+			return nil, fmt.Errorf("%w: invalid domain pattern %q", ErrInvalidOptions, pattern)
+		}
+		normalized = append(normalized, pattern)
+	}
+	return normalized, nil
+}
+
+// matchDomain indique si 'host' correspond à l'un des motifs.
+func matchDomain(patterns []string, host string) (string, bool) {
+	for _, pattern := range patterns {
+		switch {
+		case pattern == "*", pattern == host:
+			return pattern, true
+		case strings.HasPrefix(pattern, "*.") && strings.HasSuffix(host, pattern[1:]):
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// Check vérifie une URL de destination et retourne la raison de son refus, ou nil si elle est acceptée.
+// L'ordre des vérifications est : format, schéma, domaines refusés puis autorisés, et enfin adresses.
+// La résolution DNS n'a lieu que si BlockPrivateAddresses ou RejectUnresolvable est actif.
+func (p *Policy) Check(ctx context.Context, rawURL string) *Violation {
+	if p == nil {
+		return nil
+	}
+	reject := func(reason Reason, format string, args ...any) *Violation {
+		return &Violation{Reason: reason, URL: rawURL, Detail: fmt.Sprintf(format, args...)}
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return reject(ReasonInvalidURL, "unparsable URL")
+	}
+	scheme := strings.ToLower(u.Scheme)
+	if !p.schemes[scheme] {
+		return reject(ReasonSchemeNotAllowed, "scheme %q is not allowed", scheme)
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return reject(ReasonInvalidURL, "URL has no host")
+	}
+
+	if pattern, ok := matchDomain(p.denied, host); ok {
+		return reject(ReasonDomainDenied, "host %q matches denied domain %q", host, pattern)
+	}
+	if len(p.allowed) > 0 {
+		if _, ok := matchDomain(p.allowed, host); !ok {
+			return reject(ReasonDomainNotAllowed, "host %q is not in the allowed domains", host)
+		}
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if reason := p.classify(addr); reason != "" {
+			return reject(reason, "address %s is not publicly routable", addr)
+		}
+		return nil
+	}
+	if looksNumeric(host) {
+		// "2130706433" ou "0x7f.1" désignent 127.0.0.1 pour certains clients HTTP.
+		return reject(ReasonInvalidURL, "host %q uses a non-standard IP address notation", host)
+	}
+
+	if !p.blockPrivate && !p.rejectUnresolvable {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, p.resolveTimeout)
+	defer cancel()
+	addrs, err := p.resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil || len(addrs) == 0 {
+		if p.rejectUnresolvable {
+			return reject(ReasonUnresolvableHost, "host %q could not be resolved", host)
+		}
+		return nil
+	}
+	// Une seule adresse interne suffit : le client HTTP peut choisir n'importe laquelle.
+	for _, addr := range addrs {
+		if reason := p.classify(addr); reason != "" {
+			return reject(reason, "host %q resolves to %s, which is not publicly routable", host, addr.Unmap())
+		}
+	}
+	return nil
+}
+
+// cgnat est la plage d'adresses partagées des opérateurs (RFC 6598), traitée comme privée.
+var cgnat = netip.MustParsePrefix("100.64.0.0/10")
+
+// classify retourne la raison de refus d'une adresse, ou "" si elle est publique ou si
+// les adresses internes ne sont pas bloquées.
+func (p *Policy) classify(addr netip.Addr) Reason {
+	if p == nil || !p.blockPrivate {
+		return ""
+	}
+	addr = addr.Unmap()
+	switch {
+	case addr.IsLoopback():
+		return ReasonLoopbackAddress
+	case addr.IsLinkLocalUnicast(), addr.IsLinkLocalMulticast():
+		return ReasonLinkLocalAddress
+	case addr.IsPrivate(), cgnat.Contains(addr):
+		return ReasonPrivateAddress
+	case addr.IsUnspecified(), addr.IsMulticast(), addr == netip.AddrFrom4([4]byte{255, 255, 255, 255}),
+		addr.Is4() && addr.As4()[0] == 0:
+		return ReasonReservedAddress
+	}
+	return ""
+}
+
+// looksNumeric indique si un hôte n'est composé que de nombres (décimaux, octaux ou hexadécimaux)
+// séparés par des points, sans être une adresse IPv4 standard.
+func looksNumeric(host string) bool {
+	for _, label := range strings.Split(host, ".") {
+		digits := strings.TrimPrefix(label, "0x")
+		if digits == "" || strings.Trim(digits, "0123456789abcdef") != "" {
+			return false
+		}
+		if label == digits && strings.Trim(label, "0123456789") != "" {
+			return false // "cafe" est un nom, "0xcafe" un nombre
+		}
+	}
+	return true
+}
+
+// HTTPClient retourne un client HTTP dont les connexions vers une adresse interne sont refusées
+// au moment de la connexion, redirections comprises : une destination acceptée à la création
+// dont le DNS pointerait ensuite vers le réseau interne reste inaccessible. Sans blocage des adresses
+// internes (ou pour une Policy nil), le client est un client standard.
+func (p *Policy) HTTPClient(timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if p != nil && p.blockPrivate {
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: p.controlDial}
+		transport.DialContext = dialer.DialContext
+	}
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// controlDial refuse une connexion dont l'adresse, résolue, n'est pas publique.
+func (p *Policy) controlDial(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if reason := p.classify(addr); reason != "" {
+		return &Violation{Reason: reason, URL: address, Detail: fmt.Sprintf("connection to %s is not allowed", addr.Unmap())}
+	}
+	return nil
+}
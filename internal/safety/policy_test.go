@@ -0,0 +1,163 @@
+package safety
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+// fakeResolver résout les noms d'après une table ; un nom absent est introuvable.
+type fakeResolver map[string][]string
+
+func (r fakeResolver) LookupNetIP(_ context.Context, _, host string) ([]netip.Addr, error) {
+	addrs, ok := r[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	parsed := make([]netip.Addr, len(addrs))
+	for i, addr := range addrs {
+		parsed[i] = netip.MustParseAddr(addr)
+	}
+	return parsed, nil
+}
+
+func TestPolicyCheck(t *testing.T) {
+	resolver := fakeResolver{
+		"example.com":      {"93.184.215.14"},
+		"intranet.example": {"10.0.0.5"},
+		"mixed.example":    {"93.184.215.14", "192.168.1.1"},
+		"mapped.example":   {"::ffff:127.0.0.1"},
+		"metadata.example": {"169.254.169.254"},
+	}
+	strict := Options{
+		AllowedSchemes:        []string{"http", "https://"},
+		DeniedDomains:         []string{"evil.com", "*.tracker.net"},
+		BlockPrivateAddresses: true,
+		RejectUnresolvable:    true,
+		Resolver:              resolver,
+	}
+	lenient := Options{AllowedSchemes: []string{"http", "https"}, Resolver: resolver}
+	allowList := Options{AllowedSchemes: []string{"https"}, AllowedDomains: []string{"example.com", "*.example.org"}, DeniedDomains: []string{"bad.example.org"}}
+
+	tests := []struct {
+		name string
+		opts Options
+		url  string
+		want Reason
+	}{
+		{"public host", strict, "https://example.com/page", ""},
+		{"uppercase scheme and host", strict, "HTTPS://EXAMPLE.COM./page", ""},
+		{"unparsable URL", strict, "http://exa mple.com/", ReasonInvalidURL},
+		{"no host", strict, "https:///path", ReasonInvalidURL},
+		{"javascript scheme", strict, "javascript:alert(1)", ReasonSchemeNotAllowed},
+		{"file scheme", strict, "file:///etc/passwd", ReasonSchemeNotAllowed},
+		{"relative URL", strict, "/relative", ReasonSchemeNotAllowed},
+		{"denied domain", strict, "https://evil.com/", ReasonDomainDenied},
+		{"denied wildcard", strict, "https://a.b.tracker.net/", ReasonDomainDenied},
+		{"wildcard excludes apex", strict, "https://tracker.net/", ReasonUnresolvableHost},
+		{"loopback literal", strict, "http://127.0.0.1:8080/", ReasonLoopbackAddress},
+		{"IPv6 loopback", strict, "http://[::1]/", ReasonLoopbackAddress},
+		{"private literal", strict, "http://192.168.0.10/", ReasonPrivateAddress},
+		{"unique local IPv6", strict, "http://[fd00::1]/", ReasonPrivateAddress},
+		{"CGNAT", strict, "http://100.64.1.1/", ReasonPrivateAddress},
+		{"cloud metadata", strict, "http://169.254.169.254/latest/", ReasonLinkLocalAddress},
+		{"unspecified", strict, "http://0.0.0.0/", ReasonReservedAddress},
+		{"broadcast", strict, "http://255.255.255.255/", ReasonReservedAddress},
+		{"public literal", strict, "http://93.184.215.14/", ""},
+		{"decimal IP notation", strict, "http://2130706433/", ReasonInvalidURL},
+		{"hexadecimal IP notation", strict, "http://0x7f.1/", ReasonInvalidURL},
+		{"hexadecimal-looking name", strict, "http://cafe.example.com/", ReasonUnresolvableHost},
+		{"resolves to private", strict, "https://intranet.example/", ReasonPrivateAddress},
+		{"one private address among several", strict, "https://mixed.example/", ReasonPrivateAddress},
+		{"resolves to mapped loopback", strict, "https://mapped.example/", ReasonLoopbackAddress},
+		{"resolves to link-local", strict, "https://metadata.example/", ReasonLinkLocalAddress},
+		{"unresolvable", strict, "https://nowhere.example/", ReasonUnresolvableHost},
+		{"lenient private literal", lenient, "http://127.0.0.1/", ""},
+		{"lenient unresolvable", lenient, "https://nowhere.example/", ""},
+		{"allowed domain", allowList, "https://example.com/", ""},
+		{"allowed wildcard", allowList, "https://docs.example.org/", ""},
+		{"deny wins over allow", allowList, "https://bad.example.org/", ReasonDomainDenied},
+		{"not in allowed domains", allowList, "https://example.net/", ReasonDomainNotAllowed},
+		{"subdomain not allowed by exact entry", allowList, "https://www.example.com/", ReasonDomainNotAllowed},
+		{"scheme not allowed", allowList, "http://example.com/", ReasonSchemeNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := New(tt.opts)
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			violation := policy.Check(context.Background(), tt.url)
+			if tt.want == "" {
+				if violation != nil {
+					t.Fatalf("Check(%q) = %v, want nil", tt.url, violation)
+				}
+				return
+			}
+			if violation == nil || violation.Reason != tt.want {
+				t.Fatalf("Check(%q) = %v, want reason %s", tt.url, violation, tt.want)
+			}
+			if violation.URL != tt.url {
+				t.Errorf("Violation.URL = %q, want %q", violation.URL, tt.url)
+			}
+		})
+	}
+}
+
+func TestNilPolicyAcceptsEverything(t *testing.T) {
+	var policy *Policy
+	if violation := policy.Check(context.Background(), "javascript:alert(1)"); violation != nil {
+		t.Errorf("nil Policy Check = %v, want nil", violation)
+	}
+}
+
+func TestNewRejectsInvalidOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"no scheme", Options{}},
+		{"empty scheme", Options{AllowedSchemes: []string{" "}}},
+		{"scheme with separator", Options{AllowedSchemes: []string{"ht:tp"}}},
+		{"empty domain", Options{AllowedSchemes: []string{"https"}, AllowedDomains: []string{""}}},
+		{"wildcard in the middle", Options{AllowedSchemes: []string{"https"}, DeniedDomains: []string{"a.*.com"}}},
+		{"double wildcard", Options{AllowedSchemes: []string{"https"}, DeniedDomains: []string{"*.*.com"}}},
+		{"URL instead of domain", Options{AllowedSchemes: []string{"https"}, DeniedDomains: []string{"https://evil.com"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.opts); !errors.Is(err, ErrInvalidOptions) {
+				t.Errorf("New(%+v) error = %v, want ErrInvalidOptions", tt.opts, err)
+			}
+		})
+	}
+}
+
+func TestHTTPClientRefusesInternalAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	strict, err := New(Options{AllowedSchemes: []string{"http"}, BlockPrivateAddresses: true})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	_, err = strict.HTTPClient(time.Second).Get(server.URL)
+	var violation *Violation
+	if !errors.As(err, &violation) || violation.Reason != ReasonLoopbackAddress {
+		t.Errorf("Get(%s) error = %v, want a %s violation", server.URL, err, ReasonLoopbackAddress)
+	}
+
+	lenient, err := New(Options{AllowedSchemes: []string{"http"}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	resp, err := lenient.HTTPClient(time.Second).Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get(%s) without address blocking: %v", server.URL, err)
+	}
+	resp.Body.Close()
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+)
+
+// batchChunkSize est le nombre de liens insérés par transaction lors d'une création en masse.
+const batchChunkSize = 500
+
+// maxChunkInserts est le nombre d'essais d'insertion d'un paquet dont un code a été pris entre
+// la vérification des codes et l'insertion.
+const maxChunkInserts = 3
+
+// MaxBatchSize est le nombre maximum de liens acceptés dans une seule création en masse.
+const MaxBatchSize = 10000
+
+// ErrBatchTooLarge est renvoyée quand une création en masse dépasse MaxBatchSize.
+var ErrBatchTooLarge = fmt.Errorf("batch exceeds %d links", MaxBatchSize)
+
+// BatchResult est le résultat de la création d'un lien au sein d'un lot :
+// soit Link est renseigné (marqué Reused s'il s'agit d'un lien existant équivalent), soit Err explique
+// l'échec de cet élément.
+type BatchResult struct {
+	Link *models.Link
+	Err  error
+}
+
+// CreateLinks crée plusieurs liens en une fois et retourne un résultat par élément, dans le même ordre.
+// La disponibilité des codes est vérifiée par lots plutôt que code par code, puis les liens
+// sont insérés par paquets transactionnels de batchChunkSize : l'échec d'un paquet n'affecte pas les autres.
+// Si un code est pris par une création concurrente entre la vérification et l'insertion, l'index unique
+// fait échouer le paquet : ses codes sont revérifiés (alias refusé, code généré remplacé) et l'insertion reprise.
+// Les éléments en mode réutilisation (ReuseExisting) sont comparés aux seuls liens déjà en base, pas aux autres
+// éléments du lot. Les métadonnées des liens insérés sont récupérées en arrière-plan, comme pour CreateLink.
+func (s *LinkService) CreateLinks(inputs []CreateLinkInput) ([]BatchResult, error) {
+	if len(inputs) > MaxBatchSize {
+		return nil, ErrBatchTooLarge
+	}
+
+	results := make([]BatchResult, len(inputs))
+	pending := make([]int, 0, len(inputs)) // Index des éléments valides restant à insérer
+
+	// Valide chaque élément et réserve les alias demandés (y compris les doublons au sein du lot).
+	// Les codes étant uniques par domaine, ils sont repérés par leur clé "domaine/code" (voir codeKey).
+	requested := make(map[string]int)
+	aliases := make(map[string][]string)
+	for i, input := range inputs {
+		link, err := newLinkFromInput(input)
+		if err == nil {
+			err = s.checkDestinations(link)
+		}
+		if err == nil {
+			err = s.followDestination(link)
+		}
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		if input.ReuseExisting && input.Alias == "" {
+			existing, err := s.findEquivalentLink(link)
+			if err != nil {
+				return nil, err
+			}
+			if existing != nil {
+				results[i].Link = existing
+				continue
+			}
+		}
+		if input.Alias != "" {
+			if err := s.checkAlias(input.Alias); err != nil {
+				results[i].Err = err
+				continue
+			}
+			key := codeKey(link.Domain, input.Alias)
+			if _, dup := requested[key]; dup {
+				results[i].Err = fmt.Errorf("%w: '%s' is requested more than once in the batch", ErrAliasTaken, input.Alias)
+				continue
+			}
+			requested[key] = i
+			aliases[link.Domain] = append(aliases[link.Domain], input.Alias)
+			link.ShortCode = input.Alias
+		}
+		results[i].Link = link
+		pending = append(pending, i)
+	}
+
+	unavailable, err := s.unavailableShortCodes(aliases)
+	if err != nil {
+		return nil, fmt.Errorf("database error checking alias availability: %w", err)
+	}
+	var needCode []int
+	kept := pending[:0]
+	for _, i := range pending {
+		link := results[i].Link
+		switch {
+		case link.ShortCode == "":
+			needCode = append(needCode, i)
+		case unavailable[codeKey(link.Domain, link.ShortCode)]:
+			results[i] = BatchResult{Err: fmt.Errorf("%w: '%s'", ErrAliasTaken, link.ShortCode)}
+			continue
+		}
+		kept = append(kept, i)
+	}
+	pending = kept
+
+	// Génère les codes manquants puis écarte les collisions en une requête par tour.
+	generated := make(map[int]bool, len(needCode))
+	for _, i := range needCode {
+		generated[i] = true
+	}
+	if err := s.assignGeneratedCodes(results, needCode, requested); err != nil {
+		return nil, err
+	}
+
+	// Insère les liens par paquets transactionnels.
+	for start := 0; start < len(pending); start += batchChunkSize {
+		end := min(start+batchChunkSize, len(pending))
+		if err := s.insertChunk(results, pending[start:end], generated, requested); err != nil {
+			return nil, err
+		}
+	}
+
+	// Récupère les métadonnées des liens insérés, sans retarder la réponse ; les liens réutilisés
+	// ont déjà les leurs.
+	var created []*models.Link
+	for _, i := range pending {
+		if results[i].Link != nil {
+			created = append(created, results[i].Link)
+		}
+	}
+	s.fetchMetadataBatchAsync(created)
+
+	return results, nil
+}
+
+// insertChunk insère les liens encore valides de 'indexes' dans une même transaction. Quand un code
+// a été pris entre-temps par une autre création, les codes du paquet sont revérifiés : un alias pris
+// devient une erreur de l'élément, un code généré est remplacé, puis l'insertion est reprise.
+// L'échec définitif du paquet est reporté sur chacun de ses éléments ; seule une erreur de la base
+// lors de la revérification interrompt le lot entier.
+func (s *LinkService) insertChunk(results []BatchResult, indexes []int, generated map[int]bool, taken map[string]int) error {
+	for attempt := 1; ; attempt++ {
+		var chunk []*models.Link
+		codes := make(map[string][]string)
+		for _, i := range indexes {
+			if link := results[i].Link; link != nil {
+				chunk = append(chunk, link)
+				codes[link.Domain] = append(codes[link.Domain], link.ShortCode)
+			}
+		}
+		if len(chunk) == 0 {
+			return nil
+		}
+
+		err := s.linkRepo.CreateLinks(chunk)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, repository.ErrShortCodeTaken) || attempt == maxChunkInserts {
+			err = fmt.Errorf("failed to save link to database: %w", err)
+			for _, i := range indexes {
+				if results[i].Link != nil {
+					results[i] = BatchResult{Err: err}
+				}
+			}
+			return nil
+		}
+
+		unavailable, err := s.unavailableShortCodes(codes)
+		if err != nil {
+			return fmt.Errorf("database error checking short code uniqueness: %w", err)
+		}
+		var regenerate []int
+		for _, i := range indexes {
+			link := results[i].Link
+			if link == nil || !unavailable[codeKey(link.Domain, link.ShortCode)] {
+				continue
+			}
+			if !generated[i] {
+				results[i] = BatchResult{Err: fmt.Errorf("%w: '%s'", ErrAliasTaken, link.ShortCode)}
+				continue
+			}
+			s.recordCodeAttempts(0, 1)
+			link.ShortCode = ""
+			regenerate = append(regenerate, i)
+		}
+		if err := s.assignGeneratedCodes(results, regenerate, taken); err != nil {
+			return err
+		}
+	}
+}
+
+// unavailableShortCodes retourne, indexés par codeKey, les codes déjà utilisés ou en quarantaine
+// parmi ceux fournis pour chaque domaine. Une seule série de requêtes est faite par domaine.
+func (s *LinkService) unavailableShortCodes(codesByDomain map[string][]string) (map[string]bool, error) {
+	unavailable := make(map[string]bool)
+	for domain, codes := range codesByDomain {
+		found, err := s.linkRepo.UnavailableShortCodes(domain, codes)
+		if err != nil {
+			return nil, err
+		}
+		for code := range found {
+			unavailable[codeKey(domain, code)] = true
+		}
+	}
+	return unavailable, nil
+}
+
+// codeKey identifie un code court parmi tous les domaines. Les codes ne contenant jamais de '/',
+// la clé est sans ambiguïté.
+func codeKey(domain, shortCode string) string {
+	return domain + "/" + shortCode
+}
+
+// assignGeneratedCodes attribue un code généré à chaque élément de 'indexes'.
+// Les codes sont vérifiés tous ensemble à chaque tour ; seuls les éléments en collision
+// (avec la base, un alias du lot ou un autre code généré sur le même domaine) sont régénérés au tour suivant.
+// 'taken' est indexé par codeKey.
+func (s *LinkService) assignGeneratedCodes(results []BatchResult, indexes []int, taken map[string]int) error {
+	const maxRounds = 5
+
+	for round := 0; round < maxRounds && len(indexes) > 0; round++ {
+		codes := make([]string, len(indexes))
+		byDomain := make(map[string][]string)
+		for j, i := range indexes {
+			code, err := s.GenerateShortCode()
+			if err != nil {
+				return fmt.Errorf("failed to generate short code: %w", err)
+			}
+			codes[j] = code
+			domain := results[i].Link.Domain
+			byDomain[domain] = append(byDomain[domain], code)
+		}
+
+		unavailable, err := s.unavailableShortCodes(byDomain)
+		if err != nil {
+			return fmt.Errorf("database error checking short code uniqueness: %w", err)
+		}
+
+		var retry []int
+		for j, i := range indexes {
+			key := codeKey(results[i].Link.Domain, codes[j])
+			if _, dup := taken[key]; dup || unavailable[key] {
+				retry = append(retry, i)
+				continue
+			}
+			taken[key] = i
+			results[i].Link.ShortCode = codes[j]
+		}
+		s.recordCodeAttempts(len(indexes), len(retry))
+		indexes = retry
+	}
+
+	for _, i := range indexes {
+		results[i] = BatchResult{Err: fmt.Errorf("%w after %d attempts", ErrNoShortCodeAvailable, maxRounds)}
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+)
+
+// recordingRepo enveloppe le repository SQLite pour observer les insertions par paquets
+// et simuler l'échec ou la course d'un paquet.
+type recordingRepo struct {
+	repository.LinkRepository
+	chunks       []int                 // Taille de chaque paquet inséré (ou tenté)
+	beforeInsert func(chunk int) error // Appelée avant chaque paquet ; une erreur fait échouer le paquet
+}
+
+func (r *recordingRepo) CreateLinks(links []*models.Link) error {
+	r.chunks = append(r.chunks, len(links))
+	if r.beforeInsert != nil {
+		if err := r.beforeInsert(len(r.chunks)); err != nil {
+			return err
+		}
+	}
+	return r.LinkRepository.CreateLinks(links)
+}
+
+// newTestService crée un LinkService sur une base SQLite temporaire, migrée comme au démarrage du serveur.
+func newTestService(t *testing.T) (*LinkService, *recordingRepo) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "links.db")), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+	if err := repository.Migrate(db); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	repo := &recordingRepo{LinkRepository: repository.NewLinkRepository(db)}
+	return NewLinkService(repo), repo
+}
+
+// urls retourne n entrées de lot vers des destinations distinctes.
+func urls(n int) []CreateLinkInput {
+	inputs := make([]CreateLinkInput, n)
+	for i := range inputs {
+		inputs[i] = CreateLinkInput{LongURL: fmt.Sprintf("https://example.com/page/%d", i)}
+	}
+	return inputs
+}
+
+func TestCreateLinksChunking(t *testing.T) {
+	tests := []struct {
+		name       string
+		size       int
+		wantChunks []int
+	}{
+		{"empty batch", 0, nil},
+		{"single link", 1, []int{1}},
+		{"exactly one chunk", batchChunkSize, []int{batchChunkSize}},
+		{"several chunks", 2*batchChunkSize + 7, []int{batchChunkSize, batchChunkSize, 7}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo := newTestService(t)
+
+			results, err := service.CreateLinks(urls(tt.size))
+			if err != nil {
+				t.Fatalf("CreateLinks: %v", err)
+			}
+			if fmt.Sprint(repo.chunks) != fmt.Sprint(tt.wantChunks) {
+				t.Errorf("chunks = %v, want %v", repo.chunks, tt.wantChunks)
+			}
+			if len(results) != tt.size {
+				t.Fatalf("%d results, want %d", len(results), tt.size)
+			}
+			codes := make(map[string]bool, tt.size)
+			for i, result := range results {
+				if result.Err != nil || result.Link == nil || result.Link.ID == 0 {
+					t.Fatalf("result %d = %+v, want a saved link", i, result)
+				}
+				if want := fmt.Sprintf("https://example.com/page/%d", i); result.Link.LongURL != want {
+					t.Errorf("result %d LongURL = %q, want %q (results out of order)", i, result.Link.LongURL, want)
+				}
+				if codes[result.Link.ShortCode] {
+					t.Fatalf("short code %q generated twice", result.Link.ShortCode)
+				}
+				codes[result.Link.ShortCode] = true
+			}
+		})
+	}
+}
+
+func TestCreateLinksMaxBatchSize(t *testing.T) {
+	service, repo := newTestService(t)
+
+	if _, err := service.CreateLinks(urls(MaxBatchSize + 1)); !errors.Is(err, ErrBatchTooLarge) {
+		t.Fatalf("CreateLinks(%d links) error = %v, want ErrBatchTooLarge", MaxBatchSize+1, err)
+	}
+	if len(repo.chunks) != 0 {
+		t.Errorf("%d chunks inserted for a rejected batch, want 0", len(repo.chunks))
+	}
+}
+
+func TestCreateLinksPartialFailure(t *testing.T) {
+	service, _ := newTestService(t)
+	existing, err := service.CreateLink(CreateLinkInput{LongURL: "https://example.com/existing", Alias: "taken"})
+	if err != nil {
+		t.Fatalf("CreateLink: %v", err)
+	}
+
+	inputs := []CreateLinkInput{
+		{LongURL: "https://example.com/ok"},
+		{LongURL: "http://exa mple.com/"},
+		{LongURL: "https://example.com/a", Alias: "promo"},
+		{LongURL: "https://example.com/b", Alias: "promo"},
+		{LongURL: "https://example.com/c", Alias: "taken"},
+		{LongURL: "https://example.com/d", Alias: "no spaces"},
+		{LongURL: "https://example.com/e", MaxClicks: -1},
+		{LongURL: "https://EXAMPLE.com/existing/", ReuseExisting: true},
+		{LongURL: "https://example.com/new", ReuseExisting: true},
+	}
+	wantErrs := []error{nil, ErrInvalidURL, nil, ErrAliasTaken, ErrAliasTaken, ErrInvalidAlias, ErrInvalidMaxClicks, nil, nil}
+
+	results, err := service.CreateLinks(inputs)
+	if err != nil {
+		t.Fatalf("CreateLinks: %v", err)
+	}
+	for i, result := range results {
+		if !errors.Is(result.Err, wantErrs[i]) || (wantErrs[i] == nil && result.Err != nil) {
+			t.Errorf("result %d error = %v, want %v", i, result.Err, wantErrs[i])
+		}
+		if (result.Link != nil) == (result.Err != nil) {
+			t.Errorf("result %d = %+v, want either a link or an error", i, result)
+		}
+	}
+	if results[2].Link == nil || results[2].Link.ShortCode != "promo" {
+		t.Errorf("result 2 = %+v, want the link with alias 'promo'", results[2])
+	}
+	if results[7].Link == nil || results[7].Link.ID != existing.ID {
+		t.Errorf("result 7 = %+v, want the existing link %d to be reused", results[7], existing.ID)
+	}
+	if results[8].Link == nil || results[8].Link.ID == existing.ID || results[8].Link.ID == 0 {
+		t.Errorf("result 8 = %+v, want a new link", results[8])
+	}
+}
+
+func TestCreateLinksFailedChunk(t *testing.T) {
+	service, repo := newTestService(t)
+	repo.beforeInsert = func(chunk int) error {
+		if chunk == 2 {
+			return errors.New("disk full")
+		}
+		return nil
+	}
+
+	size := batchChunkSize + 10
+	results, err := service.CreateLinks(urls(size))
+	if err != nil {
+		t.Fatalf("CreateLinks: %v", err)
+	}
+	for i, result := range results {
+		inFailedChunk := i >= batchChunkSize
+		if inFailedChunk && (result.Err == nil || result.Link != nil) {
+			t.Fatalf("result %d = %+v, want the chunk error", i, result)
+		}
+		if !inFailedChunk && (result.Err != nil || result.Link == nil || result.Link.ID == 0) {
+			t.Fatalf("result %d = %+v, want a saved link", i, result)
+		}
+	}
+}
+
+func TestCreateLinksCodeTakenDuringInsert(t *testing.T) {
+	service, repo := newTestService(t)
+	inputs := []CreateLinkInput{
+		{LongURL: "https://example.com/a", Alias: "raced"},
+		{LongURL: "https://example.com/b"},
+		{LongURL: "https://example.com/c", Alias: "safe"},
+	}
+	// Une création concurrente prend l'alias "raced" entre la vérification et l'insertion du paquet.
+	repo.beforeInsert = func(chunk int) error {
+		if chunk == 1 {
+			return repo.LinkRepository.CreateLink(&models.Link{ShortCode: "raced", LongURL: "https://example.com/other"})
+		}
+		return nil
+	}
+
+	results, err := service.CreateLinks(inputs)
+	if err != nil {
+		t.Fatalf("CreateLinks: %v", err)
+	}
+	if !errors.Is(results[0].Err, ErrAliasTaken) {
+		t.Errorf("result 0 error = %v, want ErrAliasTaken", results[0].Err)
+	}
+	for _, i := range []int{1, 2} {
+		if results[i].Err != nil || results[i].Link == nil || results[i].Link.ID == 0 {
+			t.Errorf("result %d = %+v, want a saved link", i, results[i])
+		}
+	}
+	if want := []int{3, 2}; fmt.Sprint(repo.chunks) != fmt.Sprint(want) {
+		t.Errorf("insert attempts = %v, want %v", repo.chunks, want)
+	}
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/urlnorm"
+)
+
+// canonicalDestination retourne la forme canonique d'une URL de destination (voir urlnorm.Canonicalize).
+func canonicalDestination(raw string) (string, error) {
+	canonical, err := urlnorm.Canonicalize(raw)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	return canonical, nil
+}
+
+// linkBehavior regroupe tout ce qui détermine la réponse d'un lien à ses visiteurs, hors domaine et code :
+// deux liens de même signature (voir behaviorSignature) sont interchangeables.
+type linkBehavior struct {
+	CanonicalURL   string                        `json:"canonical_url"`
+	ExpiresAt      *time.Time                    `json:"expires_at,omitempty"`
+	NotBefore      *time.Time                    `json:"not_before,omitempty"`
+	IOSURL         string                        `json:"ios_url,omitempty"`
+	AndroidURL     string                        `json:"android_url,omitempty"`
+	DesktopURL     string                        `json:"desktop_url,omitempty"`
+	CountryURLs    map[string]string             `json:"country_urls,omitempty"`
+	Schedule       []models.ScheduledDestination `json:"schedule,omitempty"`
+	Rules          []models.RedirectRule         `json:"rules,omitempty"`
+	Variants       []models.SplitVariant         `json:"variants,omitempty"`
+	StickyVariants bool                          `json:"sticky_variants"`
+	RedirectType   models.RedirectType           `json:"redirect_type,omitempty"`
+	ForwardQuery   bool                          `json:"forward_query"`
+	UTM            models.UTMParams              `json:"utm"`
+}
+
+// behaviorSignature retourne la signature JSON du comportement d'un lien, vide en cas d'échec de l'encodage.
+func behaviorSignature(link *models.Link) string {
+	signature, err := json.Marshal(linkBehavior{
+		CanonicalURL:   link.CanonicalURL,
+		ExpiresAt:      utcTime(link.ExpiresAt),
+		NotBefore:      utcTime(link.NotBefore),
+		IOSURL:         link.IOSURL,
+		AndroidURL:     link.AndroidURL,
+		DesktopURL:     link.DesktopURL,
+		CountryURLs:    link.CountryURLs,
+		Schedule:       link.Schedule,
+		Rules:          link.Rules,
+		Variants:       link.Variants,
+		StickyVariants: link.StickyVariants,
+		RedirectType:   link.RedirectType,
+		ForwardQuery:   link.ForwardQuery,
+		UTM:            link.UTM,
+	})
+	if err != nil {
+		return ""
+	}
+	return string(signature)
+}
+
+// findEquivalentLink cherche, sur le domaine du lien à créer, un lien existant vers la même destination
+// canonique et au comportement identique (voir linkBehavior). Les liens protégés par mot de passe
+// ou limités en clics ne sont jamais réutilisés, ni les liens qui ne redirigent plus (désactivés,
+// expirés, pas encore actifs). Le lien trouvé est marqué Reused ; nil si aucun ne convient.
+func (s *LinkService) findEquivalentLink(link *models.Link) (*models.Link, error) {
+	if link.IsProtected() || link.MaxClicks > 0 {
+		return nil, nil
+	}
+	want := behaviorSignature(link)
+	if want == "" {
+		return nil, nil
+	}
+
+	candidates, err := s.linkRepo.FindLinksByCanonicalURL(link.Domain, link.CanonicalURL)
+	if err != nil {
+		return nil, fmt.Errorf("database error looking up equivalent links: %w", err)
+	}
+	for i := range candidates {
+		candidate := &candidates[i]
+		if candidate.IsProtected() || candidate.MaxClicks > 0 || s.CheckAvailability(candidate) != nil {
+			continue
+		}
+		if behaviorSignature(candidate) == want {
+			candidate.Reused = true
+			return candidate, nil
+		}
+	}
+	return nil, nil
+}
+
+// DuplicateGroup regroupe les liens pointant vers une même destination canonique.
+type DuplicateGroup struct {
+	CanonicalURL string
+	Links        []models.Link
+}
+
+// FindDuplicates retourne les groupes de liens (non supprimés, tous domaines confondus) qui partagent
+// leur destination canonique, les plus nombreux d'abord. 'limit' borne le nombre de groupes, 0 = sans limite.
+func (s *LinkService) FindDuplicates(limit int) ([]DuplicateGroup, error) {
+	links, err := s.linkRepo.FindDuplicateLinks(limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find duplicate links: %w", err)
+	}
+
+	var groups []DuplicateGroup
+	for _, link := range links {
+		if n := len(groups); n > 0 && groups[n-1].CanonicalURL == link.CanonicalURL {
+			groups[n-1].Links = append(groups[n-1].Links, link)
+			continue
+		}
+		groups = append(groups, DuplicateGroup{CanonicalURL: link.CanonicalURL, Links: []models.Link{link}})
+	}
+	slices.SortStableFunc(groups, func(a, b DuplicateGroup) int {
+		return len(b.Links) - len(a.Links)
+	})
+	return groups, nil
+}
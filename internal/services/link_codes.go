@@ -0,0 +1,241 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/axellelanca/urlshortener/internal/codegen"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+)
+
+// maxCodeAttempts est le nombre de codes générés essayés pour un même lien avant d'abandonner.
+const maxCodeAttempts = 10
+
+// ErrNoShortCodeAvailable est retournée quand aucun code libre n'a été trouvé après maxCodeAttempts essais.
+var ErrNoShortCodeAvailable = errors.New("no short code available")
+
+// CodeGrowthPolicy décrit l'allongement automatique des codes générés. Le taux de collision est mesuré
+// sur des fenêtres de Window codes générés : au-delà de MaxCollisionRate, la longueur augmente d'un
+// caractère, jusqu'à MaxLength. Seuls les générateurs codegen.Resizable sont concernés.
+type CodeGrowthPolicy struct {
+	MaxLength        int
+	MaxCollisionRate float64
+	Window           int
+}
+
+// codeStats compte les codes générés et les collisions de la fenêtre de mesure en cours.
+type codeStats struct {
+	mu         sync.Mutex
+	attempts   int
+	collisions int
+}
+
+// defaultCodeWindow est la taille de la fenêtre de mesure tant qu'aucune politique n'est configurée.
+const defaultCodeWindow = 200
+
+// SetCodeGrowth active l'allongement automatique des codes et l'enregistrement des collisions dans les
+// compteurs persistants 'counters' (nil = mesure en mémoire uniquement). La longueur de départ est
+// aussitôt ajustée : elle augmente tant que l'occupation de l'espace de codes à la longueur actuelle,
+// qui est la probabilité qu'un nouveau code soit déjà pris, dépasse MaxCollisionRate sur un domaine.
+func (s *LinkService) SetCodeGrowth(policy CodeGrowthPolicy, counters repository.CounterRepository) error {
+	s.codeGrowth = policy
+	s.counters = counters
+
+	resizable, ok := s.codeGenerator.(codegen.Resizable)
+	if !ok {
+		return nil
+	}
+	counts, err := s.linkRepo.CountShortCodesByLength()
+	if err != nil {
+		return fmt.Errorf("failed to count short codes: %w", err)
+	}
+	for length := resizable.Length(); length < policy.MaxLength; length++ {
+		occupancy := 0.0
+		for _, count := range counts {
+			if count.Length == length {
+				occupancy = max(occupancy, float64(count.Count)/resizable.Capacity(length))
+			}
+		}
+		if occupancy <= policy.MaxCollisionRate {
+			break
+		}
+		if err := resizable.SetLength(length + 1); err != nil {
+			return err
+		}
+		log.Printf("Espace des codes de %d caractères occupé à %.1f%%, les nouveaux codes auront %d caractères.", length, occupancy*100, length+1)
+	}
+	return nil
+}
+
+// insertWithGeneratedCode attribue un code généré au lien et l'insère. L'unicité est garantie par
+// l'index unique (domaine, code) : en cas de collision à l'insertion, y compris avec un lien créé au
+// même instant par une autre requête, un nouveau code est généré. Les codes en quarantaine sont
+// écartés avant l'insertion, la quarantaine n'étant pas couverte par l'index.
+func (s *LinkService) insertWithGeneratedCode(link *models.Link) error {
+	for attempt := 1; attempt <= maxCodeAttempts; attempt++ {
+		code, err := s.GenerateShortCode()
+		if err != nil {
+			return fmt.Errorf("failed to generate short code: %w", err)
+		}
+
+		quarantined, err := s.linkRepo.IsShortCodeQuarantined(link.Domain, code)
+		if err != nil {
+			return fmt.Errorf("database error checking short code quarantine: %w", err)
+		}
+		if !quarantined {
+			link.ShortCode = code
+			err = s.linkRepo.CreateLink(link)
+			if err == nil {
+				s.recordCodeAttempts(1, 0)
+				return nil
+			}
+			if !errors.Is(err, repository.ErrShortCodeTaken) {
+				return fmt.Errorf("failed to save link to database: %w", err)
+			}
+		}
+
+		s.recordCodeAttempts(1, 1)
+		log.Printf("Short code '%s' already exists, retrying generation (%d/%d)...", code, attempt, maxCodeAttempts)
+	}
+	return fmt.Errorf("%w after %d attempts", ErrNoShortCodeAvailable, maxCodeAttempts)
+}
+
+// recordCodeAttempts comptabilise des codes générés et leurs collisions. À la fin de chaque fenêtre
+// de mesure, les totaux sont ajoutés aux compteurs persistants et la longueur des codes augmente
+// si le taux de collision de la fenêtre dépasse le seuil de la politique. Une fenêtre incomplète
+// n'est pas persistée à l'arrêt du processus.
+func (s *LinkService) recordCodeAttempts(attempts, collisions int) {
+	window := s.codeGrowth.Window
+	if window <= 0 {
+		window = defaultCodeWindow
+	}
+
+	s.codeStats.mu.Lock()
+	s.codeStats.attempts += attempts
+	s.codeStats.collisions += collisions
+	if s.codeStats.attempts < window {
+		s.codeStats.mu.Unlock()
+		return
+	}
+	attempts, collisions = s.codeStats.attempts, s.codeStats.collisions
+	s.codeStats.attempts, s.codeStats.collisions = 0, 0
+	s.codeStats.mu.Unlock()
+
+	if s.counters != nil {
+		if _, err := s.counters.Add(repository.CounterCodeAttempts, uint64(attempts)); err != nil {
+			log.Printf("Error recording short code attempts: %v", err)
+		}
+		if _, err := s.counters.Add(repository.CounterCodeCollisions, uint64(collisions)); err != nil {
+			log.Printf("Error recording short code collisions: %v", err)
+		}
+	}
+
+	rate := float64(collisions) / float64(attempts)
+	if s.codeGrowth.MaxLength > 0 && rate > s.codeGrowth.MaxCollisionRate {
+		s.growCodeLength(rate, attempts)
+	}
+}
+
+// growCodeLength allonge d'un caractère les codes générés, sans dépasser la longueur maximale.
+func (s *LinkService) growCodeLength(rate float64, attempts int) {
+	resizable, ok := s.codeGenerator.(codegen.Resizable)
+	if !ok {
+		return
+	}
+
+	s.codeStats.mu.Lock()
+	defer s.codeStats.mu.Unlock()
+
+	length := resizable.Length()
+	if length >= s.codeGrowth.MaxLength {
+		log.Printf("Attention: taux de collision de %.1f%% sur %d codes, mais la longueur maximale des codes (%d) est atteinte.", rate*100, attempts, length)
+		return
+	}
+	if err := resizable.SetLength(length + 1); err != nil {
+		log.Printf("Error growing short code length: %v", err)
+		return
+	}
+	log.Printf("Taux de collision de %.1f%% sur %d codes : les nouveaux codes auront %d caractères.", rate*100, attempts, length+1)
+}
+
+// KeyspaceUsage décrit l'occupation de l'espace des codes d'une longueur sur un domaine.
+// Capacity vaut 0 quand le générateur configuré ne la connaît pas.
+type KeyspaceUsage struct {
+	Domain   string
+	Length   int
+	Used     int64
+	Capacity float64
+}
+
+// Occupancy retourne la part de l'espace de codes déjà utilisée (0 si la capacité est inconnue).
+// Pour un générateur aléatoire, c'est aussi la probabilité qu'un nouveau code soit déjà pris.
+func (u KeyspaceUsage) Occupancy() float64 {
+	if u.Capacity == 0 {
+		return 0
+	}
+	return float64(u.Used) / u.Capacity
+}
+
+// KeyspaceReport résume l'usage des codes courts : occupation par domaine et par longueur, longueur
+// actuelle des codes générés (0 si le générateur n'en a pas) et collisions enregistrées depuis l'origine.
+type KeyspaceReport struct {
+	Length     int
+	Policy     CodeGrowthPolicy
+	Usage      []KeyspaceUsage
+	Attempts   uint64
+	Collisions uint64
+}
+
+// GetKeyspaceReport calcule l'occupation de l'espace des codes courts. Les codes comptés sont ceux
+// des liens (corbeille comprise) et ceux en quarantaine, alias personnalisés inclus : à une longueur
+// donnée, un alias occupe la même place qu'un code généré.
+func (s *LinkService) GetKeyspaceReport() (*KeyspaceReport, error) {
+	counts, err := s.linkRepo.CountShortCodesByLength()
+	if err != nil {
+		return nil, fmt.Errorf("failed to count short codes: %w", err)
+	}
+
+	report := &KeyspaceReport{Policy: s.codeGrowth}
+	keyspace, sized := s.codeGenerator.(codegen.Keyspace)
+	if sized {
+		report.Length = keyspace.Length()
+	}
+
+	// La longueur actuelle figure pour chaque domaine, même si aucun code de cette longueur n'existe encore.
+	hasCurrent := map[string]bool{"": false}
+	for _, count := range counts {
+		usage := KeyspaceUsage{Domain: count.Domain, Length: count.Length, Used: count.Count}
+		if sized {
+			usage.Capacity = keyspace.Capacity(count.Length)
+		}
+		hasCurrent[count.Domain] = hasCurrent[count.Domain] || count.Length == report.Length
+		report.Usage = append(report.Usage, usage)
+	}
+	if sized {
+		for domain, present := range hasCurrent {
+			if !present {
+				report.Usage = append(report.Usage, KeyspaceUsage{Domain: domain, Length: report.Length, Capacity: keyspace.Capacity(report.Length)})
+			}
+		}
+	}
+	sort.Slice(report.Usage, func(i, j int) bool {
+		if report.Usage[i].Domain != report.Usage[j].Domain {
+			return report.Usage[i].Domain < report.Usage[j].Domain
+		}
+		return report.Usage[i].Length < report.Usage[j].Length
+	})
+
+	if s.counters != nil {
+		if report.Attempts, err = s.counters.Get(repository.CounterCodeAttempts); err != nil {
+			return nil, err
+		}
+		if report.Collisions, err = s.counters.Get(repository.CounterCodeCollisions); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
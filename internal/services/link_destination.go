@@ -0,0 +1,323 @@
+package services
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// Platform est la plateforme d'un visiteur, déduite de son User-Agent.
+type Platform string
+
+// Plateformes reconnues. PlatformOther couvre les robots, les User-Agent vides ou inconnus.
+const (
+	PlatformIOS     Platform = "ios"
+	PlatformAndroid Platform = "android"
+	PlatformDesktop Platform = "desktop"
+	PlatformOther   Platform = "other"
+)
+
+// maxScheduleEntries limite le nombre de destinations programmées d'un lien.
+const maxScheduleEntries = 20
+
+// Limites appliquées aux destinations pondérées (tests A/B).
+const (
+	minVariants       = 2
+	maxVariants       = 10
+	variantNameMaxLen = 32
+	variantWeightMax  = 10000
+)
+
+// countryCodePattern valide un code pays ISO 3166-1 alpha-2 normalisé en majuscules.
+var countryCodePattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// CountryResolver donne le code pays ISO 3166-1 alpha-2 d'une adresse IP, ou "" si inconnu.
+// Implémentée par geoip.Resolver.
+type CountryResolver interface {
+	Country(ip string) string
+}
+
+// SetCountryResolver active les destinations par pays. Sans résolveur, LongURL est toujours utilisée.
+func (s *LinkService) SetCountryResolver(resolver CountryResolver) {
+	s.countryResolver = resolver
+}
+
+// Visit décrit la requête d'un visiteur, à partir de laquelle la destination d'un lien est choisie.
+type Visit struct {
+	UserAgent string      // En-tête User-Agent, utilisé pour les destinations par plateforme
+	Header    http.Header // En-têtes de la requête, utilisés par les règles de redirection
+	IP        string      // Adresse IP du visiteur, utilisée pour les destinations par pays
+	Query     url.Values  // Query string de l'URL courte, transmise si le lien l'autorise
+	Variant   string      // Variante A/B déjà attribuée au visiteur (cookie), respectée si le lien est "sticky"
+	At        time.Time   // Instant de la visite, utilisé pour les destinations programmées (maintenant si zéro)
+}
+
+// Destination est le résultat de ResolveDestination : l'URL finale et ce qui a permis de la choisir.
+type Destination struct {
+	URL      string
+	Platform Platform
+	Country  string // Pays résolu, uniquement si le lien a des destinations par pays
+	Variant  string // Variante A/B servie, vide si aucune n'a été utilisée
+	Rule     int    // Position (à partir de 1) de la règle de redirection appliquée, 0 si aucune
+}
+
+// DetectPlatform déduit la plateforme d'un visiteur de son User-Agent.
+// Les iPad sous iPadOS 13+ s'annoncent comme un Mac et sont donc vus comme des ordinateurs.
+func DetectPlatform(userAgent string) Platform {
+	ua := strings.ToLower(userAgent)
+	switch {
+	case ua == "", strings.Contains(ua, "windows phone"):
+		return PlatformOther
+	case strings.Contains(ua, "android"):
+		return PlatformAndroid
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"), strings.Contains(ua, "ipod"):
+		return PlatformIOS
+	case strings.Contains(ua, "bot"), strings.Contains(ua, "spider"), strings.Contains(ua, "crawl"):
+		return PlatformOther
+	case strings.Contains(ua, "windows nt"), strings.Contains(ua, "macintosh"),
+		strings.Contains(ua, "x11"), strings.Contains(ua, "cros"), strings.Contains(ua, "linux"):
+		return PlatformDesktop
+	default:
+		return PlatformOther
+	}
+}
+
+// ResolveDestination choisit l'URL vers laquelle rediriger un visiteur, par ordre de priorité :
+// la première règle de redirection dont les conditions sont remplies, puis la destination programmée pour l'instant de la visite (évaluée en UTC), puis celle de sa plateforme
+// (liens d'application), puis celle de son pays, puis une variante A/B tirée selon les poids,
+// et enfin LongURL. Les paramètres de la visite et les UTM du lien sont ensuite ajoutés (voir appendQuery).
+func (s *LinkService) ResolveDestination(link *models.Link, visit Visit) Destination {
+	destination := Destination{Platform: DetectPlatform(visit.UserAgent)}
+
+	at := visit.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+	if position := s.matchRules(link, visit, at.UTC(), &destination); position > 0 {
+		destination.Rule = position
+		destination.URL = appendQuery(link, link.Rules[position-1].URL, visit.Query)
+		return destination
+	}
+	if target, ok := link.ScheduledDestination(at.UTC()); ok {
+		destination.URL = appendQuery(link, target, visit.Query)
+		return destination
+	}
+
+	var target string
+	switch destination.Platform {
+	case PlatformIOS:
+		target = link.IOSURL
+	case PlatformAndroid:
+		target = link.AndroidURL
+	case PlatformDesktop:
+		target = link.DesktopURL
+	}
+
+	// La base GeoIP n'est interrogée que pour les liens qui ont des destinations par pays.
+	if len(link.CountryURLs) > 0 && s.countryResolver != nil {
+		destination.Country = s.countryResolver.Country(visit.IP)
+		if target == "" && destination.Country != "" {
+			target = link.CountryURLs[destination.Country]
+		}
+	}
+
+	if target == "" && len(link.Variants) > 0 {
+		variant := pickVariant(link, visit.Variant)
+		target = variant.URL
+		destination.Variant = variant.Name
+	}
+
+	if target == "" {
+		target = link.LongURL
+	}
+	destination.URL = appendQuery(link, target, visit.Query)
+	return destination
+}
+
+// normalizeSchedule valide les destinations programmées d'un lien et convertit leurs bornes en UTC.
+func normalizeSchedule(schedule []models.ScheduledDestination) ([]models.ScheduledDestination, error) {
+	if len(schedule) == 0 {
+		return nil, nil
+	}
+	if len(schedule) > maxScheduleEntries {
+		return nil, fmt.Errorf("%w: at most %d scheduled destinations are allowed", ErrInvalidSchedule, maxScheduleEntries)
+	}
+
+	normalized := make([]models.ScheduledDestination, len(schedule))
+	for i, entry := range schedule {
+		if entry.From == nil && entry.Until == nil {
+			return nil, fmt.Errorf("%w: entry %d needs a start or an end time", ErrInvalidSchedule, i+1)
+		}
+		if entry.From != nil && entry.Until != nil && !entry.From.Before(*entry.Until) {
+			return nil, fmt.Errorf("%w: entry %d must start before it ends", ErrInvalidSchedule, i+1)
+		}
+		u, err := url.ParseRequestURI(entry.URL)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("%w: invalid URL for entry %d", ErrInvalidSchedule, i+1)
+		}
+		normalized[i] = models.ScheduledDestination{
+			From:  utcTime(entry.From),
+			Until: utcTime(entry.Until),
+			URL:   entry.URL,
+		}
+	}
+	return normalized, nil
+}
+
+// utcTime retourne une copie de 't' exprimée en UTC, ou nil.
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+// pickVariant retourne la variante A/B à servir. Pour un lien "sticky", la variante déjà attribuée
+// au visiteur est conservée tant qu'elle existe ; sinon une variante est tirée au sort selon les poids.
+func pickVariant(link *models.Link, assigned string) models.SplitVariant {
+	if link.StickyVariants && assigned != "" {
+		for _, variant := range link.Variants {
+			if variant.Name == assigned {
+				return variant
+			}
+		}
+	}
+
+	total := 0
+	for _, variant := range link.Variants {
+		total += variant.Weight
+	}
+	n := rand.IntN(total)
+	for _, variant := range link.Variants {
+		if n < variant.Weight {
+			return variant
+		}
+		n -= variant.Weight
+	}
+	return link.Variants[len(link.Variants)-1]
+}
+
+// normalizeVariants valide les destinations pondérées d'un lien.
+// Une variante sans nom reçoit une lettre selon sa position ("a", "b", ...).
+func normalizeVariants(variants []models.SplitVariant) ([]models.SplitVariant, error) {
+	if len(variants) == 0 {
+		return nil, nil
+	}
+	if len(variants) < minVariants || len(variants) > maxVariants {
+		return nil, fmt.Errorf("%w: between %d and %d variants are required", ErrInvalidVariants, minVariants, maxVariants)
+	}
+
+	normalized := make([]models.SplitVariant, len(variants))
+	seen := make(map[string]bool, len(variants))
+	for i, variant := range variants {
+		if variant.Name == "" {
+			variant.Name = string(rune('a' + i))
+		}
+		if len(variant.Name) > variantNameMaxLen || !aliasPattern.MatchString(variant.Name) {
+			return nil, fmt.Errorf("%w: invalid variant name '%s'", ErrInvalidVariants, variant.Name)
+		}
+		if seen[variant.Name] {
+			return nil, fmt.Errorf("%w: variant '%s' is listed more than once", ErrInvalidVariants, variant.Name)
+		}
+		seen[variant.Name] = true
+		if variant.Weight < 1 || variant.Weight > variantWeightMax {
+			return nil, fmt.Errorf("%w: weight of variant '%s' must be between 1 and %d", ErrInvalidVariants, variant.Name, variantWeightMax)
+		}
+		u, err := url.ParseRequestURI(variant.URL)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("%w: invalid URL for variant '%s'", ErrInvalidVariants, variant.Name)
+		}
+		normalized[i] = variant
+	}
+	return normalized, nil
+}
+
+// normalizeCountryURLs valide les destinations par pays et met les codes pays en majuscules.
+func normalizeCountryURLs(countryURLs map[string]string) (map[string]string, error) {
+	if len(countryURLs) == 0 {
+		return nil, nil
+	}
+	normalized := make(map[string]string, len(countryURLs))
+	for country, target := range countryURLs {
+		code := strings.ToUpper(strings.TrimSpace(country))
+		if !countryCodePattern.MatchString(code) {
+			return nil, fmt.Errorf("%w: '%s' is not an ISO 3166-1 alpha-2 country code", ErrInvalidCountryRule, country)
+		}
+		if _, dup := normalized[code]; dup {
+			return nil, fmt.Errorf("%w: country %s is listed more than once", ErrInvalidCountryRule, code)
+		}
+		u, err := url.ParseRequestURI(target)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("%w: invalid URL for country %s", ErrInvalidCountryRule, code)
+		}
+		normalized[code] = target
+	}
+	return normalized, nil
+}
+
+// appendQuery ajoute à 'target' les paramètres de la visite et les UTM par défaut du lien.
+//
+// Les paramètres sont fusionnés selon une priorité fixe, un paramètre déjà présent
+// n'étant jamais remplacé ni dupliqué :
+//  1. les paramètres écrits dans l'URL de destination l'emportent toujours ;
+//  2. la query string de l'URL courte (si ForwardQuery est activé) complète ceux qui manquent ;
+//  3. les paramètres UTM par défaut du lien ne sont ajoutés que s'ils sont encore absents,
+//     ce qui permet à un utm_source passé sur l'URL courte de remplacer la valeur par défaut.
+//
+// La query string de la destination est conservée octet pour octet (ordre et encodage compris) :
+// les URL signées (S3, callbacks HMAC) restent valides. Les paramètres ajoutés sont placés à la suite,
+// avant le fragment. Sans paramètre à ajouter, 'target' est retournée telle quelle.
+func appendQuery(link *models.Link, target string, incoming url.Values) string {
+	utm := link.UTM.Values()
+	if (!link.ForwardQuery || len(incoming) == 0) && len(utm) == 0 {
+		return target
+	}
+
+	dest, err := url.Parse(target)
+	if err != nil {
+		return target
+	}
+	existing := dest.Query()
+	extra := url.Values{}
+
+	if link.ForwardQuery {
+		for name, values := range incoming {
+			if _, exists := existing[name]; exists {
+				continue
+			}
+			extra[name] = values
+		}
+	}
+	for name, value := range utm {
+		if _, exists := existing[name]; exists {
+			continue
+		}
+		if _, exists := extra[name]; exists {
+			continue
+		}
+		extra.Set(name, value)
+	}
+
+	if len(extra) == 0 {
+		return target
+	}
+	base, fragment, hasFragment := strings.Cut(target, "#")
+	switch {
+	case !strings.Contains(base, "?"):
+		base += "?"
+	case !strings.HasSuffix(base, "?") && !strings.HasSuffix(base, "&"):
+		base += "&"
+	}
+	base += extra.Encode()
+	if hasFragment {
+		base += "#" + fragment
+	}
+	return base
+}
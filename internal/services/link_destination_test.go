@@ -0,0 +1,90 @@
+package services
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+func TestAppendQuery(t *testing.T) {
+	// Query string d'une URL S3 présignée : ni triée, ni encodée comme le ferait url.Values.Encode.
+	const presigned = "https://bucket.s3.amazonaws.com/file.pdf?X-Amz-Signature=ab%2Fcd&X-Amz-Algorithm=AWS4-HMAC-SHA256&X-Amz-Credential=AKIA%2F20261017%2Fus-east-1"
+	utm := models.UTMParams{Source: "newsletter"}
+
+	tests := []struct {
+		name     string
+		forward  bool
+		utm      models.UTMParams
+		target   string
+		incoming url.Values
+		want     string
+	}{
+		{
+			name:   "nothing to add",
+			target: presigned,
+			want:   presigned,
+		},
+		{
+			name:     "forwarding disabled",
+			target:   presigned,
+			incoming: url.Values{"ref": {"tw"}},
+			want:     presigned,
+		},
+		{
+			name:     "forwarded parameter already in the destination",
+			forward:  true,
+			target:   presigned,
+			incoming: url.Values{"X-Amz-Signature": {"forged"}},
+			want:     presigned,
+		},
+		{
+			name:     "unsorted destination query kept as is",
+			forward:  true,
+			utm:      utm,
+			target:   presigned,
+			incoming: url.Values{"ref": {"tw"}},
+			want:     presigned + "&ref=tw&utm_source=newsletter",
+		},
+		{
+			name:   "destination without query",
+			utm:    utm,
+			target: "https://example.com/page",
+			want:   "https://example.com/page?utm_source=newsletter",
+		},
+		{
+			name:   "empty query",
+			utm:    utm,
+			target: "https://example.com/page?",
+			want:   "https://example.com/page?utm_source=newsletter",
+		},
+		{
+			name:   "fragment stays last",
+			utm:    utm,
+			target: "https://example.com/page?b=2&a=1#section",
+			want:   "https://example.com/page?b=2&a=1&utm_source=newsletter#section",
+		},
+		{
+			name:     "forwarded UTM replaces the default",
+			forward:  true,
+			utm:      models.UTMParams{Source: "newsletter", Medium: "email"},
+			target:   "https://example.com/",
+			incoming: url.Values{"utm_source": {"twitter"}},
+			want:     "https://example.com/?utm_medium=email&utm_source=twitter",
+		},
+		{
+			name:   "UTM already in the destination",
+			utm:    utm,
+			target: "https://example.com/?utm_source=partner",
+			want:   "https://example.com/?utm_source=partner",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			link := &models.Link{ForwardQuery: tt.forward, UTM: tt.utm}
+			if got := appendQuery(link, tt.target, tt.incoming); got != tt.want {
+				t.Errorf("appendQuery(%q) = %q, want %q", tt.target, got, tt.want)
+			}
+		})
+	}
+}
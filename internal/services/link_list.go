@@ -0,0 +1,154 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/repository"
+)
+
+// Bornes de la taille de page de ListLinks.
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+// Erreurs renvoyées par ListLinks pour des paramètres invalides.
+var (
+	ErrInvalidCursor     = errors.New("invalid cursor")
+	ErrInvalidListFilter = errors.New("invalid list filter")
+)
+
+// ListLinksParams regroupe les filtres d'une recherche de liens.
+// Cursor est la valeur NextCursor de la page précédente (vide pour la première page).
+type ListLinksParams struct {
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Domain      string
+	ShortDomain *string // Domaine court des liens, déjà normalisé (nil = tous les domaines)
+	Search      string
+	SortBy      string // "created_at" (défaut) ou "clicks"
+	Ascending   bool
+	Cursor      string
+	Limit       int
+}
+
+// LinkPage est une page de résultats de ListLinks.
+// NextCursor est vide lorsqu'il n'y a plus de résultats.
+type LinkPage struct {
+	Links      []repository.LinkWithClicks
+	NextCursor string
+}
+
+// listCursor est le contenu (opaque pour les clients) d'un curseur de pagination.
+// Le tri et l'ordre y sont inclus pour refuser un curseur réutilisé avec d'autres paramètres.
+type listCursor struct {
+	SortBy     string `json:"s"`
+	Ascending  bool   `json:"a,omitempty"`
+	ClickCount int    `json:"c,omitempty"`
+	ID         uint   `json:"i"`
+}
+
+// ListLinks recherche des liens selon les filtres fournis et retourne une page de résultats.
+func (s *LinkService) ListLinks(params ListLinksParams) (*LinkPage, error) {
+	sortBy := repository.LinkSortField(params.SortBy)
+	switch sortBy {
+	case "":
+		sortBy = repository.SortByCreatedAt
+	case repository.SortByCreatedAt, repository.SortByClicks:
+	default:
+		return nil, fmt.Errorf("%w: sort must be '%s' or '%s'", ErrInvalidListFilter, repository.SortByCreatedAt, repository.SortByClicks)
+	}
+
+	limit := params.Limit
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	if limit > MaxListLimit {
+		limit = MaxListLimit
+	}
+
+	if params.CreatedFrom != nil && params.CreatedTo != nil && !params.CreatedFrom.Before(*params.CreatedTo) {
+		return nil, fmt.Errorf("%w: created_from must be before created_to", ErrInvalidListFilter)
+	}
+
+	query := repository.LinkListQuery{
+		CreatedFrom: params.CreatedFrom,
+		CreatedTo:   params.CreatedTo,
+		Domain:      params.Domain,
+		ShortDomain: params.ShortDomain,
+		Search:      params.Search,
+		SortBy:      sortBy,
+		Ascending:   params.Ascending,
+		Limit:       limit + 1, // Un élément de plus pour savoir s'il existe une page suivante
+	}
+	if params.Cursor != "" {
+		cursor, err := decodeListCursor(params.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if cursor.SortBy != string(sortBy) || cursor.Ascending != params.Ascending {
+			return nil, fmt.Errorf("%w: cursor was issued for a different sort order", ErrInvalidCursor)
+		}
+		query.After = &repository.LinkCursor{ClickCount: cursor.ClickCount, ID: cursor.ID}
+	}
+
+	links, err := s.linkRepo.ListLinks(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list links: %w", err)
+	}
+
+	page := &LinkPage{Links: links}
+	if len(links) > limit {
+		page.Links = links[:limit]
+		last := page.Links[limit-1]
+		page.NextCursor = encodeListCursor(listCursor{
+			SortBy:     string(sortBy),
+			Ascending:  params.Ascending,
+			ClickCount: last.ClickCount,
+			ID:         last.ID,
+		})
+	}
+	return page, nil
+}
+
+// ParseDateFilter interprète une borne de date au format RFC 3339 ou AAAA-MM-JJ.
+// Pour une borne de fin au format date seule, la journée entière est incluse.
+func ParseDateFilter(value string, endOfRange bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: '%s' is not a RFC 3339 or YYYY-MM-DD date", ErrInvalidListFilter, value)
+	}
+	if endOfRange {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+// encodeListCursor sérialise un curseur en chaîne opaque utilisable dans une URL.
+func encodeListCursor(cursor listCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeListCursor relit un curseur produit par encodeListCursor.
+func decodeListCursor(value string) (*listCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor listCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
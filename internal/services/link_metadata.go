@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// maxConcurrentMetadataFetches limite le nombre de pages de destination récupérées en parallèle.
+const maxConcurrentMetadataFetches = 8
+
+// MetadataFetcher récupère les métadonnées (titre, description, Open Graph, favicon)
+// de la page de destination d'un lien. Implémentée par metadata.Fetcher.
+type MetadataFetcher interface {
+	Fetch(ctx context.Context, rawURL string) (*models.LinkMetadata, error)
+}
+
+// SetMetadataFetcher active la récupération des métadonnées en arrière-plan après la création
+// d'un lien ou un changement de destination. Sans fetcher, aucune page n'est récupérée.
+func (s *LinkService) SetMetadataFetcher(fetcher MetadataFetcher) {
+	s.metadataFetcher = fetcher
+	s.metadataSlots = make(chan struct{}, maxConcurrentMetadataFetches)
+}
+
+// fetchMetadataAsync lance la récupération des métadonnées de la destination actuelle du lien
+// dans une goroutine : la réponse à l'appelant n'attend jamais la page distante.
+func (s *LinkService) fetchMetadataAsync(link *models.Link) {
+	if s.metadataFetcher == nil {
+		return
+	}
+	linkID, longURL, shortCode := link.ID, link.LongURL, link.ShortCode
+
+	go func() {
+		s.metadataSlots <- struct{}{}
+		s.fetchMetadata(linkID, longURL, shortCode)
+	}()
+}
+
+// fetchMetadataBatchAsync récupère en arrière-plan les métadonnées des liens créés par un lot.
+// Une seule goroutine parcourt le lot et n'en lance une par lien qu'une fois une place obtenue
+// dans metadataSlots : un lot de plusieurs milliers de liens ne crée pas autant de goroutines en attente.
+func (s *LinkService) fetchMetadataBatchAsync(links []*models.Link) {
+	if s.metadataFetcher == nil || len(links) == 0 {
+		return
+	}
+	type target struct {
+		linkID             uint
+		longURL, shortCode string
+	}
+	targets := make([]target, len(links))
+	for i, link := range links {
+		targets[i] = target{link.ID, link.LongURL, link.ShortCode}
+	}
+
+	go func() {
+		for _, t := range targets {
+			s.metadataSlots <- struct{}{}
+			go s.fetchMetadata(t.linkID, t.longURL, t.shortCode)
+		}
+	}()
+}
+
+// fetchMetadata récupère et enregistre les métadonnées de 'longURL', puis libère la place
+// prise par l'appelant dans metadataSlots.
+func (s *LinkService) fetchMetadata(linkID uint, longURL, shortCode string) {
+	defer func() { <-s.metadataSlots }()
+
+	meta, err := s.metadataFetcher.Fetch(context.Background(), longURL)
+	if err != nil {
+		log.Printf("[METADATA] Récupération impossible pour le lien %s (%s) : %v", shortCode, longURL, err)
+		return
+	}
+	// La mise à jour est ignorée si la destination a changé entre-temps.
+	if err := s.linkRepo.UpdateMetadata(linkID, longURL, meta); err != nil {
+		log.Printf("[METADATA] ERREUR lors de l'enregistrement des métadonnées du lien %s : %v", shortCode, err)
+	}
+}
@@ -0,0 +1,329 @@
+package services
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// Limites appliquées aux règles de redirection d'un lien.
+const (
+	maxRules          = 50
+	maxRuleValues     = 50 // Nombre maximum de valeurs par condition (en-têtes, langues, plages IP...)
+	ruleNameMaxLen    = 64
+	rulePatternMaxLen = 256
+)
+
+// languageTagPattern valide une étiquette de langue BCP 47 simplifiée (ex: "fr", "fr-CA", "zh-Hant-TW").
+var languageTagPattern = regexp.MustCompile(`^[a-zA-Z]{1,8}(-[a-zA-Z0-9]{1,8})*$`)
+
+// ruleWeekdays associe les abréviations acceptées dans les règles aux jours de la semaine.
+var ruleWeekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// SetLinkRules remplace les règles de redirection d'un lien après les avoir validées.
+// Une liste vide supprime toutes les règles.
+func (s *LinkService) SetLinkRules(domain, shortCode string, rules []models.RedirectRule) (*models.Link, error) {
+	normalized, err := normalizeRules(rules)
+	if err != nil {
+		return nil, err
+	}
+	targets := make([]string, len(normalized))
+	for i, rule := range normalized {
+		targets[i] = rule.URL
+	}
+	if err := s.checkURLs(targets); err != nil {
+		return nil, err
+	}
+	link, err := s.GetLinkByShortCode(domain, shortCode)
+	if err != nil {
+		return nil, err
+	}
+	link.Rules = normalized
+	if err := s.linkRepo.UpdateRules(link); err != nil {
+		return nil, fmt.Errorf("failed to update link rules: %w", err)
+	}
+	return link, nil
+}
+
+// matchRules retourne la position (à partir de 1) de la première règle du lien dont toutes
+// les conditions sont remplies par la visite, ou 0 si aucune ne correspond.
+// Le pays du visiteur n'est résolu que si une règle le demande ; il est alors reporté dans 'destination'.
+func (s *LinkService) matchRules(link *models.Link, visit Visit, at time.Time, destination *Destination) int {
+	countryResolved := false
+	for i, rule := range link.Rules {
+		when := rule.When
+		if len(when.Countries) > 0 && !countryResolved {
+			countryResolved = true
+			if s.countryResolver != nil {
+				destination.Country = s.countryResolver.Country(visit.IP)
+			}
+		}
+		if matchConditions(when, visit, at, destination) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// matchConditions indique si toutes les conditions renseignées de 'when' sont remplies.
+func matchConditions(when models.RuleConditions, visit Visit, at time.Time, destination *Destination) bool {
+	for name, pattern := range when.Headers {
+		if !matchValues(visit.Header.Values(name), pattern) {
+			return false
+		}
+	}
+	for name, pattern := range when.Query {
+		if !matchValues(visit.Query[name], pattern) {
+			return false
+		}
+	}
+	if len(when.Languages) > 0 && !matchLanguages(when.Languages, visit.Header.Get("Accept-Language")) {
+		return false
+	}
+	if len(when.CIDRs) > 0 && !matchCIDRs(when.CIDRs, visit.IP) {
+		return false
+	}
+	if len(when.Countries) > 0 && !containsFold(when.Countries, destination.Country) {
+		return false
+	}
+	if len(when.Platforms) > 0 && !containsFold(when.Platforms, string(destination.Platform)) {
+		return false
+	}
+	if len(when.Weekdays) > 0 && !containsFold(when.Weekdays, weekdayName(at.Weekday())) {
+		return false
+	}
+	if when.Hours != nil && !when.Hours.Contains(at.Hour()) {
+		return false
+	}
+	return true
+}
+
+// matchValues indique si au moins une des valeurs correspond au motif.
+// Le motif "*" demande seulement la présence d'une valeur, même vide.
+func matchValues(values []string, pattern string) bool {
+	if pattern == "*" {
+		return len(values) > 0
+	}
+	for _, value := range values {
+		if globMatch(strings.ToLower(pattern), strings.ToLower(value)) {
+			return true
+		}
+	}
+	return false
+}
+
+// globMatch compare une valeur à un motif où '*' remplace n'importe quelle suite de caractères.
+// Contrairement à path.Match, '/' n'a pas de rôle particulier (utile pour les User-Agent).
+func globMatch(pattern, value string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == value
+	}
+	if !strings.HasPrefix(value, parts[0]) {
+		return false
+	}
+	value = value[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		idx := strings.Index(value, part)
+		if idx < 0 {
+			return false
+		}
+		value = value[idx+len(part):]
+	}
+	return len(value) >= len(last) && strings.HasSuffix(value, last)
+}
+
+// matchLanguages indique si l'une des langues de la règle figure dans l'en-tête Accept-Language.
+// Une langue de règle sans sous-étiquette ("fr") couvre ses variantes régionales ("fr-CA").
+// Les langues refusées explicitement (q=0) et le joker "*" sont ignorés.
+func matchLanguages(languages []string, acceptLanguage string) bool {
+	for _, entry := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(entry), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" || tag == "*" {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if weight, err := strconv.ParseFloat(q, 64); err == nil && weight <= 0 {
+				continue
+			}
+		}
+		for _, language := range languages {
+			if strings.EqualFold(tag, language) ||
+				(len(tag) > len(language) && tag[len(language)] == '-' && strings.EqualFold(tag[:len(language)], language)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchCIDRs indique si l'adresse IP du visiteur appartient à l'une des plages.
+func matchCIDRs(cidrs []string, rawIP string) bool {
+	ip := net.ParseIP(rawIP)
+	if ip == nil {
+		return false
+	}
+	for _, cidr := range cidrs {
+		if _, network, err := net.ParseCIDR(cidr); err == nil && network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold indique si 'value' figure dans 'list', sans tenir compte de la casse.
+func containsFold(list []string, value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// weekdayName retourne l'abréviation d'un jour telle qu'utilisée dans les règles.
+func weekdayName(day time.Weekday) string {
+	return strings.ToLower(day.String()[:3])
+}
+
+// normalizeRules valide les règles de redirection d'un lien et normalise leurs valeurs
+// (noms d'en-têtes canoniques, pays en majuscules, jours et plateformes en minuscules).
+func normalizeRules(rules []models.RedirectRule) ([]models.RedirectRule, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	if len(rules) > maxRules {
+		return nil, fmt.Errorf("%w: at most %d rules are allowed", ErrInvalidRules, maxRules)
+	}
+
+	normalized := make([]models.RedirectRule, len(rules))
+	for i, rule := range rules {
+		label := fmt.Sprintf("rule %d", i+1)
+		if rule.Name != "" {
+			label = fmt.Sprintf("rule '%s'", rule.Name)
+		}
+		if len(rule.Name) > ruleNameMaxLen {
+			return nil, fmt.Errorf("%w: %s: name must be at most %d characters", ErrInvalidRules, label, ruleNameMaxLen)
+		}
+		u, err := url.ParseRequestURI(rule.URL)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("%w: %s: invalid URL", ErrInvalidRules, label)
+		}
+		when, err := normalizeConditions(rule.When)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %s", ErrInvalidRules, label, err)
+		}
+		normalized[i] = models.RedirectRule{Name: rule.Name, When: when, URL: rule.URL}
+	}
+	return normalized, nil
+}
+
+// normalizeConditions valide les conditions d'une règle. Les erreurs retournées décrivent
+// la condition fautive et sont enveloppées par normalizeRules.
+func normalizeConditions(when models.RuleConditions) (models.RuleConditions, error) {
+	var out models.RuleConditions
+	for _, list := range [][]string{when.Languages, when.CIDRs, when.Countries, when.Platforms, when.Weekdays} {
+		if len(list) > maxRuleValues {
+			return out, fmt.Errorf("at most %d values are allowed per condition", maxRuleValues)
+		}
+	}
+	if len(when.Headers) > maxRuleValues || len(when.Query) > maxRuleValues {
+		return out, fmt.Errorf("at most %d values are allowed per condition", maxRuleValues)
+	}
+
+	if len(when.Headers) > 0 {
+		out.Headers = make(map[string]string, len(when.Headers))
+		for name, pattern := range when.Headers {
+			canonical := http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if canonical == "" || strings.ContainsAny(canonical, " :") {
+				return out, fmt.Errorf("invalid header name '%s'", name)
+			}
+			if pattern == "" || len(pattern) > rulePatternMaxLen {
+				return out, fmt.Errorf("pattern for header %s must be between 1 and %d characters", canonical, rulePatternMaxLen)
+			}
+			out.Headers[canonical] = pattern
+		}
+	}
+
+	if len(when.Query) > 0 {
+		out.Query = make(map[string]string, len(when.Query))
+		for name, pattern := range when.Query {
+			if name == "" {
+				return out, fmt.Errorf("query parameter name cannot be empty")
+			}
+			if pattern == "" || len(pattern) > rulePatternMaxLen {
+				return out, fmt.Errorf("pattern for query parameter %s must be between 1 and %d characters", name, rulePatternMaxLen)
+			}
+			out.Query[name] = pattern
+		}
+	}
+
+	for _, language := range when.Languages {
+		if !languageTagPattern.MatchString(language) {
+			return out, fmt.Errorf("invalid language tag '%s'", language)
+		}
+		out.Languages = append(out.Languages, language)
+	}
+
+	for _, cidr := range when.CIDRs {
+		_, network, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			return out, fmt.Errorf("invalid CIDR '%s'", cidr)
+		}
+		out.CIDRs = append(out.CIDRs, network.String())
+	}
+
+	for _, country := range when.Countries {
+		code := strings.ToUpper(strings.TrimSpace(country))
+		if !countryCodePattern.MatchString(code) {
+			return out, fmt.Errorf("'%s' is not an ISO 3166-1 alpha-2 country code", country)
+		}
+		out.Countries = append(out.Countries, code)
+	}
+
+	for _, platform := range when.Platforms {
+		switch p := Platform(strings.ToLower(strings.TrimSpace(platform))); p {
+		case PlatformIOS, PlatformAndroid, PlatformDesktop, PlatformOther:
+			out.Platforms = append(out.Platforms, string(p))
+		default:
+			return out, fmt.Errorf("unknown platform '%s'", platform)
+		}
+	}
+
+	for _, day := range when.Weekdays {
+		name := strings.ToLower(strings.TrimSpace(day))
+		if _, ok := ruleWeekdays[name]; !ok {
+			return out, fmt.Errorf("unknown weekday '%s' (expected mon, tue, wed, thu, fri, sat or sun)", day)
+		}
+		out.Weekdays = append(out.Weekdays, name)
+	}
+
+	if when.Hours != nil {
+		hours := *when.Hours
+		if hours.From < 0 || hours.From > 23 || hours.To < 0 || hours.To > 24 || hours.From == hours.To {
+			return out, fmt.Errorf("hours must satisfy 0 <= from <= 23, 0 <= to <= 24 and from != to")
+		}
+		out.Hours = &hours
+	}
+	return out, nil
+}
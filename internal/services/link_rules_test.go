@@ -0,0 +1,202 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// countries est un résolveur GeoIP en mémoire qui compte ses appels.
+type countries struct {
+	byIP  map[string]string
+	calls int
+}
+
+func (c *countries) Country(ip string) string {
+	c.calls++
+	return c.byIP[ip]
+}
+
+const (
+	iPhoneUA  = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)"
+	desktopUA = "Mozilla/5.0 (Windows NT 10.0; Win64; x64)"
+)
+
+func TestResolveDestinationRules(t *testing.T) {
+	link := &models.Link{
+		LongURL: "https://example.com/default",
+		Rules: []models.RedirectRule{
+			{Name: "beta", When: models.RuleConditions{Query: map[string]string{"beta": "*"}}, URL: "https://example.com/beta"},
+			{Name: "bots", When: models.RuleConditions{Headers: map[string]string{"User-Agent": "*bot*"}}, URL: "https://example.com/bots"},
+			{Name: "office", When: models.RuleConditions{CIDRs: []string{"10.0.0.0/8"}}, URL: "https://example.com/office"},
+			{Name: "french mobile", When: models.RuleConditions{Languages: []string{"fr"}, Platforms: []string{"ios", "android"}}, URL: "https://example.com/fr-mobile"},
+			{Name: "canada", When: models.RuleConditions{Countries: []string{"CA"}}, URL: "https://example.com/ca"},
+			{Name: "weekend nights", When: models.RuleConditions{Weekdays: []string{"sat", "sun"}, Hours: &models.HourRange{From: 22, To: 6}}, URL: "https://example.com/night"},
+		},
+	}
+	weekday := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)       // Mercredi midi
+	saturdayNight := time.Date(2026, 10, 17, 23, 0, 0, 0, time.UTC) // Samedi 23h
+	sundayDawn := time.Date(2026, 10, 18, 5, 59, 0, 0, time.UTC)    // Dimanche 5h59
+	sundayMorning := time.Date(2026, 10, 18, 6, 0, 0, 0, time.UTC)  // Dimanche 6h
+
+	tests := []struct {
+		name     string
+		visit    Visit
+		wantURL  string
+		wantRule int
+	}{
+		{name: "no rule matches", visit: Visit{UserAgent: desktopUA, IP: "203.0.113.1", At: weekday}, wantURL: "https://example.com/default"},
+		{name: "query parameter present", visit: Visit{Query: url.Values{"beta": {""}}, At: weekday}, wantURL: "https://example.com/beta", wantRule: 1},
+		{name: "header glob", visit: Visit{Header: http.Header{"User-Agent": {"Googlebot/2.1"}}, At: weekday}, wantURL: "https://example.com/bots", wantRule: 2},
+		{name: "header glob ignores case", visit: Visit{Header: http.Header{"User-Agent": {"SomeBOT"}}, At: weekday}, wantURL: "https://example.com/bots", wantRule: 2},
+		{name: "first matching rule wins", visit: Visit{Query: url.Values{"beta": {"1"}}, IP: "10.1.2.3", At: weekday}, wantURL: "https://example.com/beta", wantRule: 1},
+		{name: "IP range", visit: Visit{IP: "10.1.2.3", At: weekday}, wantURL: "https://example.com/office", wantRule: 3},
+		{name: "language and platform", visit: Visit{UserAgent: iPhoneUA, Header: http.Header{"Accept-Language": {"fr-CA,en;q=0.8"}}, At: weekday}, wantURL: "https://example.com/fr-mobile", wantRule: 4},
+		{name: "language without platform", visit: Visit{UserAgent: desktopUA, Header: http.Header{"Accept-Language": {"fr"}}, At: weekday}, wantURL: "https://example.com/default"},
+		{name: "refused language", visit: Visit{UserAgent: iPhoneUA, Header: http.Header{"Accept-Language": {"en, fr;q=0"}}, At: weekday}, wantURL: "https://example.com/default"},
+		{name: "country", visit: Visit{IP: "198.51.100.7", At: weekday}, wantURL: "https://example.com/ca", wantRule: 5},
+		{name: "saturday night", visit: Visit{At: saturdayNight}, wantURL: "https://example.com/night", wantRule: 6},
+		{name: "range over midnight", visit: Visit{At: sundayDawn}, wantURL: "https://example.com/night", wantRule: 6},
+		{name: "end of range excluded", visit: Visit{At: sundayMorning}, wantURL: "https://example.com/default"},
+		{name: "evaluated in UTC", visit: Visit{At: saturdayNight.In(time.FixedZone("UTC-10", -10*3600))}, wantURL: "https://example.com/night", wantRule: 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewLinkService(nil)
+			service.SetCountryResolver(&countries{byIP: map[string]string{"198.51.100.7": "CA"}})
+			if tt.visit.Header == nil {
+				tt.visit.Header = http.Header{}
+			}
+
+			got := service.ResolveDestination(link, tt.visit)
+			if got.URL != tt.wantURL || got.Rule != tt.wantRule {
+				t.Errorf("ResolveDestination = %q (rule %d), want %q (rule %d)", got.URL, got.Rule, tt.wantURL, tt.wantRule)
+			}
+		})
+	}
+}
+
+func TestMatchRulesResolvesCountryOnlyWhenNeeded(t *testing.T) {
+	resolver := &countries{byIP: map[string]string{"198.51.100.7": "CA"}}
+	service := NewLinkService(nil)
+	service.SetCountryResolver(resolver)
+	visit := Visit{IP: "198.51.100.7", Header: http.Header{}, At: time.Now()}
+
+	withoutCountry := &models.Link{LongURL: "https://example.com/", Rules: []models.RedirectRule{
+		{When: models.RuleConditions{CIDRs: []string{"10.0.0.0/8"}}, URL: "https://example.com/office"},
+	}}
+	service.ResolveDestination(withoutCountry, visit)
+	if resolver.calls != 0 {
+		t.Errorf("country resolved %d times for rules without a country condition, want 0", resolver.calls)
+	}
+
+	withCountry := &models.Link{LongURL: "https://example.com/", Rules: []models.RedirectRule{
+		{When: models.RuleConditions{Countries: []string{"FR"}}, URL: "https://example.com/fr"},
+		{When: models.RuleConditions{Countries: []string{"CA"}}, URL: "https://example.com/ca"},
+	}}
+	got := service.ResolveDestination(withCountry, visit)
+	if resolver.calls != 1 {
+		t.Errorf("country resolved %d times for two country rules, want 1", resolver.calls)
+	}
+	if got.Country != "CA" || got.Rule != 2 {
+		t.Errorf("ResolveDestination = %+v, want country CA and rule 2", got)
+	}
+}
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern, value string
+		want           bool
+	}{
+		{"abc", "abc", true},
+		{"abc", "abcd", false},
+		{"*", "", true},
+		{"a*", "abc", true},
+		{"*c", "abc", true},
+		{"a*c", "ac", true},
+		{"a*b*c", "axxbyyc", true},
+		{"a*b*c", "axxcyyb", false},
+		{"ab*ba", "aba", false},
+		{"mozilla/*(iphone*", "mozilla/5.0 (iphone; cpu)", true},
+	}
+	for _, tt := range tests {
+		if got := globMatch(tt.pattern, tt.value); got != tt.want {
+			t.Errorf("globMatch(%q, %q) = %v, want %v", tt.pattern, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeRules(t *testing.T) {
+	rules, err := normalizeRules([]models.RedirectRule{{
+		Name: "all conditions",
+		When: models.RuleConditions{
+			Headers:   map[string]string{" x-campaign ": "spring*"},
+			Languages: []string{"fr-CA"},
+			CIDRs:     []string{"10.1.2.3/8"},
+			Countries: []string{" ca "},
+			Platforms: []string{"IOS"},
+			Weekdays:  []string{"Sat"},
+			Hours:     &models.HourRange{From: 22, To: 6},
+		},
+		URL: "https://example.com/",
+	}})
+	if err != nil {
+		t.Fatalf("normalizeRules: %v", err)
+	}
+	want := models.RuleConditions{
+		Headers:   map[string]string{"X-Campaign": "spring*"},
+		Languages: []string{"fr-CA"},
+		CIDRs:     []string{"10.0.0.0/8"},
+		Countries: []string{"CA"},
+		Platforms: []string{"ios"},
+		Weekdays:  []string{"sat"},
+		Hours:     &models.HourRange{From: 22, To: 6},
+	}
+	if !reflect.DeepEqual(rules[0].When, want) {
+		t.Errorf("normalized conditions = %+v, want %+v", rules[0].When, want)
+	}
+
+	if rules, err := normalizeRules(nil); rules != nil || err != nil {
+		t.Errorf("normalizeRules(nil) = %v, %v; want nil, nil", rules, err)
+	}
+}
+
+func TestNormalizeRulesRejects(t *testing.T) {
+	rule := func(when models.RuleConditions) []models.RedirectRule {
+		return []models.RedirectRule{{When: when, URL: "https://example.com/"}}
+	}
+	tests := []struct {
+		name  string
+		rules []models.RedirectRule
+	}{
+		{"too many rules", make([]models.RedirectRule, maxRules+1)},
+		{"name too long", []models.RedirectRule{{Name: strings.Repeat("n", ruleNameMaxLen+1), URL: "https://example.com/"}}},
+		{"relative URL", []models.RedirectRule{{URL: "/path"}}},
+		{"URL without host", []models.RedirectRule{{URL: "https:///path"}}},
+		{"header name with colon", rule(models.RuleConditions{Headers: map[string]string{"X:Y": "a"}})},
+		{"empty header pattern", rule(models.RuleConditions{Headers: map[string]string{"X-A": ""}})},
+		{"pattern too long", rule(models.RuleConditions{Query: map[string]string{"q": strings.Repeat("a", rulePatternMaxLen+1)}})},
+		{"empty query name", rule(models.RuleConditions{Query: map[string]string{"": "a"}})},
+		{"invalid language", rule(models.RuleConditions{Languages: []string{"fr_FR"}})},
+		{"invalid CIDR", rule(models.RuleConditions{CIDRs: []string{"10.0.0.0"}})},
+		{"invalid country", rule(models.RuleConditions{Countries: []string{"FRA"}})},
+		{"unknown platform", rule(models.RuleConditions{Platforms: []string{"windows"}})},
+		{"unknown weekday", rule(models.RuleConditions{Weekdays: []string{"monday"}})},
+		{"empty hour range", rule(models.RuleConditions{Hours: &models.HourRange{From: 8, To: 8}})},
+		{"hour out of range", rule(models.RuleConditions{Hours: &models.HourRange{From: 24, To: 2}})},
+		{"too many values", rule(models.RuleConditions{Countries: make([]string, maxRuleValues+1)})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := normalizeRules(tt.rules); !errors.Is(err, ErrInvalidRules) {
+				t.Errorf("normalizeRules error = %v, want ErrInvalidRules", err)
+			}
+		})
+	}
+}
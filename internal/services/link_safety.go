@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/axellelanca/urlshortener/internal/chain"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/safety"
+)
+
+// SetDestinationPolicy active la politique de sécurité des destinations (schémas, domaines, adresses internes),
+// appliquée à toutes les destinations d'un lien à sa création et à chaque modification.
+func (s *LinkService) SetDestinationPolicy(policy *safety.Policy) {
+	s.destinationPolicy = policy
+}
+
+// SetRedirectChain active le refus des destinations qui renvoient vers le service lui-même et, selon sa
+// configuration, le suivi des raccourcisseurs connus quand la destination principale d'un lien est fixée.
+func (s *LinkService) SetRedirectChain(follower *chain.Follower) {
+	s.redirectChain = follower
+}
+
+// CheckDestination retourne la raison pour laquelle une URL de destination est refusée, ou nil si elle est acceptée.
+func (s *LinkService) CheckDestination(rawURL string) *safety.Violation {
+	return s.destinationPolicy.Check(context.Background(), rawURL)
+}
+
+// checkDestinations vérifie chaque destination possible du lien avec la politique configurée et les flux de menaces.
+// L'erreur retournée enveloppe ErrUnsafeDestination et la *safety.Violation de la première destination refusée.
+func (s *LinkService) checkDestinations(link *models.Link) error {
+	return s.checkURLs(link.Destinations())
+}
+
+// checkURLs refuse les destinations qui désignent le service lui-même, vérifie les autres avec la politique
+// configurée puis les cherche dans les flux de menaces (voir checkDestinations et checkThreat).
+// Le service pouvant écouter sur une adresse interne (localhost), le lien vers soi-même est testé en premier
+// pour que son refus porte la raison self_reference.
+func (s *LinkService) checkURLs(urls []string) error {
+	for _, target := range urls {
+		violation := s.redirectChain.Check(target)
+		if violation == nil {
+			violation = s.CheckDestination(target)
+		}
+		if violation != nil {
+			return fmt.Errorf("%w: %w", ErrUnsafeDestination, violation)
+		}
+		if err := s.checkThreat(target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// followURL suit la chaîne de raccourcisseurs d'une destination déjà vérifiée et retourne la destination finale,
+// vérifiée à son tour. Sans suivi configuré, la destination est retournée telle quelle.
+func (s *LinkService) followURL(rawURL string) (string, error) {
+	final, violation := s.redirectChain.Resolve(context.Background(), rawURL)
+	if violation != nil {
+		return "", fmt.Errorf("%w: %w", ErrUnsafeDestination, violation)
+	}
+	if final == rawURL {
+		return rawURL, nil
+	}
+	if _, err := canonicalDestination(final); err != nil {
+		return "", err
+	}
+	if err := s.checkURLs([]string{final}); err != nil {
+		return "", err
+	}
+	return final, nil
+}
+
+// followDestination remplace la destination principale du lien par la fin de sa chaîne de raccourcisseurs
+// (voir followURL) et met à jour sa forme canonique. Les autres destinations du lien ne sont pas suivies.
+func (s *LinkService) followDestination(link *models.Link) error {
+	final, err := s.followURL(link.LongURL)
+	if err != nil || final == link.LongURL {
+		return err
+	}
+	canonical, err := canonicalDestination(final)
+	if err != nil {
+		return err
+	}
+	link.LongURL, link.CanonicalURL = final, canonical
+	return nil
+}
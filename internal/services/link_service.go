@@ -1,134 +1,659 @@
 package services
 
 import (
-	"crypto/rand"
 	"errors"
 	"fmt"
-	"log"
-	"math/big"
+	"regexp"
+	"time"
 
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm" // Nécessaire pour la gestion spécifique de gorm.ErrRecordNotFound
 
+	"github.com/axellelanca/urlshortener/internal/chain"
+	"github.com/axellelanca/urlshortener/internal/codegen"
 	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/axellelanca/urlshortener/internal/repository" // Importe le package repository
+	"github.com/axellelanca/urlshortener/internal/safety"
 )
 
-// Définition du jeu de caractères pour la génération des codes courts.
-const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+// Contraintes appliquées aux alias personnalisés choisis par l'utilisateur.
+const (
+	aliasMinLength = 3
+	aliasMaxLength = 32
+)
+
+// Contraintes appliquées aux mots de passe des liens protégés (bcrypt ignore au-delà de 72 octets).
+const (
+	passwordMinLength = 4
+	passwordMaxLength = 72
+)
+
+// utmMaxLength borne la longueur de chaque paramètre UTM attaché à un lien.
+const utmMaxLength = 100
+
+// aliasPattern liste les caractères autorisés dans un alias : lettres, chiffres, '-' et '_'.
+var aliasPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// Erreurs métier renvoyées par LinkService, à tester avec errors.Is.
+var (
+	ErrInvalidAlias        = errors.New("invalid alias")
+	ErrAliasTaken          = errors.New("alias already in use")
+	ErrInvalidExpiration   = errors.New("invalid expiration date")
+	ErrInvalidMaxClicks    = errors.New("invalid max clicks")
+	ErrLinkExpired         = errors.New("link has expired")
+	ErrClickLimitReached   = errors.New("link has reached its click limit")
+	ErrInvalidPassword     = errors.New("invalid password")
+	ErrNoRevision          = errors.New("no revision to roll back to")
+	ErrLinkDisabled        = errors.New("link is disabled")
+	ErrLinkDeleted         = errors.New("link has been deleted")
+	ErrLinkNotYetActive    = errors.New("link is not active yet")
+	ErrInvalidUTM          = errors.New("invalid UTM parameter")
+	ErrInvalidCountryRule  = errors.New("invalid country destination")
+	ErrInvalidVariants     = errors.New("invalid split destinations")
+	ErrInvalidSchedule     = errors.New("invalid schedule")
+	ErrInvalidRules        = errors.New("invalid redirect rules")
+	ErrInvalidRedirectType = errors.New("invalid redirect type")
+	ErrCodeNotAllowed      = errors.New("short code not allowed")
+	ErrInvalidURL          = errors.New("invalid destination URL")
+	ErrUnsafeDestination   = errors.New("unsafe destination")
+)
+
+// CreateLinkInput regroupe les paramètres de création d'un lien.
+// Seul LongURL est obligatoire ; un Alias vide déclenche la génération d'un code aléatoire.
+// Domain est l'hôte du domaine court de marque (vide = domaine par défaut) ; les codes sont uniques par domaine.
+// ExpiresAt (nil = jamais) et MaxClicks (0 = illimité) limitent la durée de vie du lien,
+// NotBefore (nil = immédiatement) retarde son activation.
+// Un Password non vide protège la redirection derrière une page de déverrouillage.
+// IOSURL, AndroidURL et DesktopURL remplacent LongURL pour les visiteurs de la plateforme correspondante,
+// CountryURLs (indexé par code pays ISO 3166-1 alpha-2) pour ceux du pays correspondant.
+// Rules liste des règles déclaratives (première qui correspond), prioritaires sur toutes les autres destinations.
+// Schedule programme des destinations sur des plages horaires, prioritaires sur les suivantes.
+// Variants répartit le reste du trafic entre plusieurs destinations pondérées (test A/B).
+// RedirectType choisit le code de redirection (ou la page meta refresh) ; vide = défaut du serveur.
+// ForwardQuery et UTM contrôlent les paramètres ajoutés à la destination (voir ResolveDestination).
+// ReuseExisting retourne un lien existant équivalent plutôt que d'en créer un nouveau (voir findEquivalentLink) ;
+// il est ignoré quand un Alias est demandé.
+type CreateLinkInput struct {
+	LongURL   string
+	Alias     string
+	Domain    string
+	ExpiresAt *time.Time
+	MaxClicks int
+	Password  string
+	NotBefore *time.Time
+
+	Rules    []models.RedirectRule
+	Schedule []models.ScheduledDestination
+
+	IOSURL      string
+	AndroidURL  string
+	DesktopURL  string
+	CountryURLs map[string]string
+
+	Variants       []models.SplitVariant
+	StickyVariants bool
+
+	RedirectType models.RedirectType
+	ForwardQuery bool
+	UTM          models.UTMParams
+
+	ReuseExisting bool
+}
 
-// TODO Créer la struct
 // LinkService est une structure qui g fournit des méthodes pour la logique métier des liens.
 // Elle détient linkRepo qui est une référence vers une interface LinkRepository.
 // IMPORTANT : Le champ doit être du type de l'interface (non-pointeur).
 type LinkService struct {
 	linkRepo repository.LinkRepository
+
+	codeGenerator codegen.Generator            // Stratégie de génération des codes courts, voir SetCodeGenerator
+	codeFilter    *codegen.Filter              // Optionnel, mots réservés et bloqués, voir SetCodeFilter
+	codeGrowth    CodeGrowthPolicy             // Allongement automatique des codes, voir SetCodeGrowth
+	counters      repository.CounterRepository // Optionnel, compteurs persistants des collisions
+	codeStats     codeStats                    // Mesure du taux de collision de la fenêtre en cours
+
+	metadataFetcher MetadataFetcher // Optionnel, voir SetMetadataFetcher
+	metadataSlots   chan struct{}   // Sémaphore bornant les récupérations de métadonnées simultanées
+
+	countryResolver CountryResolver // Optionnel, voir SetCountryResolver
+
+	destinationPolicy *safety.Policy  // Optionnel, voir SetDestinationPolicy
+	threatChecker     ThreatChecker   // Optionnel, voir SetThreatChecker
+	redirectChain     *chain.Follower // Optionnel, voir SetRedirectChain
 }
 
 // NewLinkService crée et retourne une nouvelle instance de LinkService.
 func NewLinkService(linkRepo repository.LinkRepository) *LinkService {
 	return &LinkService{
-		linkRepo: linkRepo,
+		linkRepo:      linkRepo,
+		codeGenerator: codegen.NewDefault(),
 	}
 }
 
-// TODO Créer la méthode GenerateShortCode
-// GenerateShortCode est une méthode rattachée à LinkService
-// Elle génère un code court aléatoire d'une longueur spécifiée. Elle prend une longueur en paramètre et retourne une string et une erreur
-// Il utilise le package 'crypto/rand' pour éviter la prévisibilité.
-// Je vous laisse chercher un peu :) C'est faisable en une petite dizaine de ligne
-func (s *LinkService) GenerateShortCode(length int) (string, error) {
-	result := make([]byte, length)
-	for i := 0; i < length; i++ {
-		// Générer un index aléatoire entre 0 et len(charset)-1
-		randomIndex, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
-		if err != nil {
-			return "", err
+// SetCodeGenerator remplace la stratégie de génération des codes courts
+// (par défaut : aléatoire base62 de 6 caractères).
+func (s *LinkService) SetCodeGenerator(generator codegen.Generator) {
+	s.codeGenerator = generator
+}
+
+// SetCodeFilter active le refus des codes réservés ou contenant un mot bloqué, pour les codes
+// générés comme pour les alias choisis par l'utilisateur.
+func (s *LinkService) SetCodeFilter(filter *codegen.Filter) {
+	s.codeFilter = filter
+}
+
+// maxFilteredCodes borne le nombre de codes générés écartés par le filtre pour une même demande.
+const maxFilteredCodes = 100
+
+// GenerateShortCode génère un code court candidat avec la stratégie configurée (voir SetCodeGenerator).
+// Les codes refusés par le filtre sont écartés sans bruit et remplacés. L'unicité du code n'est pas vérifiée ici.
+func (s *LinkService) GenerateShortCode() (string, error) {
+	for i := 0; i < maxFilteredCodes; i++ {
+		code, err := s.codeGenerator.Generate()
+		if err != nil || s.codeFilter.Check(code) == nil {
+			return code, err
 		}
-		result[i] = charset[randomIndex.Int64()]
 	}
-	return string(result), nil
+	return "", fmt.Errorf("%w: every generated code was rejected by the blocklist", ErrNoShortCodeAvailable)
+}
+
+// CheckShortCode retourne la raison pour laquelle un code est refusé par le filtre, ou nil s'il est accepté.
+func (s *LinkService) CheckShortCode(code string) *codegen.Rejection {
+	return s.codeFilter.Check(code)
+}
+
+// checkAlias vérifie qu'un alias choisi par l'utilisateur n'est ni réservé ni bloqué.
+func (s *LinkService) checkAlias(alias string) error {
+	if rejection := s.codeFilter.Check(alias); rejection != nil {
+		return fmt.Errorf("%w: %s", ErrCodeNotAllowed, rejection)
+	}
+	return nil
 }
 
 // CreateLink crée un nouveau lien raccourci.
-// Il génère un code court unique, puis persiste le lien dans la base de données.
-func (s *LinkService) CreateLink(longURL string) (*models.Link, error) {
-	// Créer une variable shortcode pour stocker le shortcode créé
-	var shortCode string
+// Il utilise l'alias demandé s'il est fourni, sinon génère un code court unique,
+// puis persiste le lien dans la base de données. L'unicité du code est garantie par
+// l'index unique de la base, même face à des créations simultanées.
+// En mode réutilisation (input.ReuseExisting), un lien existant équivalent est retourné tel quel,
+// marqué Reused, à la place d'un nouveau lien. Une destination sur un raccourcisseur connu est remplacée
+// par la fin de sa chaîne de redirections (voir SetRedirectChain).
+func (s *LinkService) CreateLink(input CreateLinkInput) (*models.Link, error) {
+	// Crée une nouvelle instance du modèle Link à partir des paramètres validés
+	link, err := newLinkFromInput(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkDestinations(link); err != nil {
+		return nil, err
+	}
+	if err := s.followDestination(link); err != nil {
+		return nil, err
+	}
 
-	// Définir un nombre maximum (5) de tentative pour trouver un code unique
-	maxRetries := 5
+	if input.ReuseExisting && input.Alias == "" {
+		existing, err := s.findEquivalentLink(link)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return existing, nil
+		}
+	}
 
-	for i := 0; i < maxRetries; i++ {
-		// Génère un code de 6 caractères (GenerateShortCode)
-		code, err := s.GenerateShortCode(6)
+	if input.Alias != "" {
+		link.ShortCode, err = s.reserveAlias(link.Domain, input.Alias)
 		if err != nil {
-			return nil, fmt.Errorf("failed to generate short code: %w", err)
+			return nil, err
 		}
 
-		// Vérifie si le code généré existe déjà en base de données (GetLinkByShortCode)
-		// On ignore la première valeur
-		_, err = s.linkRepo.GetLinkByShortCode(code)
+		// Persiste le nouveau lien dans la base de données via le repository (CreateLink).
+		// L'alias a pu être pris entre la vérification et l'insertion : l'index unique tranche.
+		err = s.linkRepo.CreateLink(link)
+		if errors.Is(err, repository.ErrShortCodeTaken) {
+			return nil, fmt.Errorf("%w: '%s'", ErrAliasTaken, link.ShortCode)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to save link to database: %w", err)
+		}
+	} else if err := s.insertWithGeneratedCode(link); err != nil {
+		return nil, err
+	}
 
+	// Récupère le titre et la description de la destination sans retarder la réponse
+	s.fetchMetadataAsync(link)
+
+	// Retourne le lien créé
+	return link, nil
+}
+
+// newLinkFromInput valide les paramètres de création et construit le lien correspondant,
+// sans code court : son attribution est laissée à l'appelant.
+func newLinkFromInput(input CreateLinkInput) (*models.Link, error) {
+	if input.Alias != "" {
+		if err := ValidateAlias(input.Alias); err != nil {
+			return nil, err
+		}
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("%w: must be in the future", ErrInvalidExpiration)
+	}
+	if input.NotBefore != nil && input.ExpiresAt != nil && !input.NotBefore.Before(*input.ExpiresAt) {
+		return nil, fmt.Errorf("%w: activation date must be before the expiration date", ErrInvalidSchedule)
+	}
+	if input.MaxClicks < 0 {
+		return nil, fmt.Errorf("%w: must be positive", ErrInvalidMaxClicks)
+	}
+
+	canonicalURL, err := canonicalDestination(input.LongURL)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, value := range input.UTM.Values() {
+		if len(value) > utmMaxLength {
+			return nil, fmt.Errorf("%w: %s must be at most %d characters", ErrInvalidUTM, name, utmMaxLength)
+		}
+	}
+
+	countryURLs, err := normalizeCountryURLs(input.CountryURLs)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule, err := normalizeSchedule(input.Schedule)
+	if err != nil {
+		return nil, err
+	}
+
+	variants, err := normalizeVariants(input.Variants)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := normalizeRules(input.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.RedirectType != "" && !input.RedirectType.IsValid() {
+		return nil, fmt.Errorf("%w: '%s' (expected 301, 302, 307, 308 or meta-refresh)", ErrInvalidRedirectType, input.RedirectType)
+	}
+
+	var passwordHash string
+	if input.Password != "" {
+		hash, err := hashPassword(input.Password)
 		if err != nil {
-			// Si l'erreur est 'record not found' de GORM, cela signifie que le code est unique.
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				shortCode = code
-				break
-			}
-			// Si c'est une autre erreur de base de données, retourne l'erreur.
-			return nil, fmt.Errorf("database error checking short code uniqueness: %w", err)
+			return nil, err
 		}
+		passwordHash = hash
+	}
 
-		// Si aucune erreur (le code a été trouvé), cela signifie une collision.
-		log.Printf("Short code '%s' already exists, retrying generation (%d/%d)...", code, i+1, maxRetries)
-		// La boucle continuera pour générer un nouveau code.
+	return &models.Link{
+		Domain:         input.Domain,
+		LongURL:        input.LongURL,
+		CanonicalURL:   canonicalURL,
+		ExpiresAt:      utcTime(input.ExpiresAt),
+		NotBefore:      utcTime(input.NotBefore),
+		MaxClicks:      input.MaxClicks,
+		PasswordHash:   passwordHash,
+		IOSURL:         input.IOSURL,
+		AndroidURL:     input.AndroidURL,
+		DesktopURL:     input.DesktopURL,
+		CountryURLs:    countryURLs,
+		Schedule:       schedule,
+		Rules:          rules,
+		Variants:       variants,
+		StickyVariants: input.StickyVariants,
+		RedirectType:   input.RedirectType,
+		ForwardQuery:   input.ForwardQuery,
+		UTM:            input.UTM,
+	}, nil
+}
+
+// ValidateAlias vérifie qu'un alias personnalisé respecte la longueur et les caractères autorisés.
+func ValidateAlias(alias string) error {
+	if len(alias) < aliasMinLength || len(alias) > aliasMaxLength {
+		return fmt.Errorf("%w: length must be between %d and %d characters", ErrInvalidAlias, aliasMinLength, aliasMaxLength)
+	}
+	if !aliasPattern.MatchString(alias) {
+		return fmt.Errorf("%w: only letters, digits, '-' and '_' are allowed", ErrInvalidAlias)
 	}
+	return nil
+}
 
-	// Si après toutes les tentatives, aucun code unique n'a été trouvé
-	if shortCode == "" {
-		return nil, errors.New("impossible de générer un code unique après 5 tentatives")
+// reserveAlias valide un alias personnalisé et vérifie qu'il est autorisé et pas déjà utilisé sur le domaine.
+func (s *LinkService) reserveAlias(domain, alias string) (string, error) {
+	if err := ValidateAlias(alias); err != nil {
+		return "", err
+	}
+	if err := s.checkAlias(alias); err != nil {
+		return "", err
+	}
+
+	available, err := s.isShortCodeAvailable(domain, alias)
+	if err != nil {
+		return "", fmt.Errorf("database error checking alias availability: %w", err)
+	}
+	if !available {
+		return "", fmt.Errorf("%w: '%s'", ErrAliasTaken, alias)
 	}
+	return alias, nil
+}
+
+// CheckAlias vérifie qu'un alias peut être choisi sur un domaine : format, mots réservés et bloqués,
+// puis disponibilité. Il retourne l'erreur qu'une création avec cet alias renverrait.
+func (s *LinkService) CheckAlias(domain, alias string) error {
+	_, err := s.reserveAlias(domain, alias)
+	return err
+}
 
-	// Crée une nouvelle instance du modèle Link
-	link := &models.Link{
-		ShortCode: shortCode,
-		LongURL:   longURL,
+// isShortCodeAvailable indique si un code peut être attribué sur un domaine : il ne doit être porté
+// par aucun lien du domaine, même supprimé, ni être en quarantaine après la purge d'un ancien lien.
+func (s *LinkService) isShortCodeAvailable(domain, code string) (bool, error) {
+	exists, err := s.linkRepo.ShortCodeExists(domain, code)
+	if err != nil || exists {
+		return false, err
 	}
+	quarantined, err := s.linkRepo.IsShortCodeQuarantined(domain, code)
+	if err != nil {
+		return false, err
+	}
+	return !quarantined, nil
+}
 
-	// Persiste le nouveau lien dans la base de données via le repository (CreateLink)
-	err := s.linkRepo.CreateLink(link)
+// GetLinkByShortCode récupère un lien via son domaine ("" = domaine par défaut) et son code court.
+// Il délègue l'opération de recherche au repository.
+func (s *LinkService) GetLinkByShortCode(domain, shortCode string) (*models.Link, error) {
+	if shortCode == "" {
+		return nil, errors.New("shortCode cannot be empty")
+	}
+
+	link, err := s.linkRepo.GetLinkByShortCode(domain, shortCode)
 	if err != nil {
-		return nil, fmt.Errorf("failed to save link to database: %w", err)
+		return nil, err
 	}
 
-	// Retourne le lien créé
 	return link, nil
 }
 
-// GetLinkByShortCode récupère un lien via son code court.
-// Il délègue l'opération de recherche au repository.
-func (s *LinkService) GetLinkByShortCode(shortCode string) (*models.Link, error) {
-	// TODO : Récupérer un lien par son code court en utilisant s.linkRepo.GetLinkByShortCode.
-	// Retourner le lien trouvé ou une erreur si non trouvé/problème DB.
+// CheckAvailability vérifie qu'un lien peut être suivi, sans consommer de clic. Elle retourne
+// ErrLinkDeleted, ErrLinkDisabled, ErrLinkNotYetActive, ErrLinkExpired ou ErrClickLimitReached selon le cas.
+func (s *LinkService) CheckAvailability(link *models.Link) error {
+	now := time.Now()
+	if link.DeletedAt.Valid {
+		return ErrLinkDeleted
+	}
+	if link.Disabled {
+		return ErrLinkDisabled
+	}
+	if link.IsNotYetActive(now) {
+		return ErrLinkNotYetActive
+	}
+	if link.IsExpired(now) {
+		return ErrLinkExpired
+	}
+	if remaining, limited := link.RemainingClicks(); limited && remaining == 0 {
+		return ErrClickLimitReached
+	}
+	return nil
+}
+
+// ConsumeClick vérifie la disponibilité du lien puis décompte un clic de son budget.
+// Le décompte est fait en base de manière atomique pour ne jamais dépasser MaxClicks.
+func (s *LinkService) ConsumeClick(link *models.Link) error {
+	if err := s.CheckAvailability(link); err != nil {
+		return err
+	}
+	if link.MaxClicks == 0 {
+		return nil
+	}
+
+	consumed, err := s.linkRepo.ConsumeClick(link.ID)
+	if err != nil {
+		return fmt.Errorf("failed to consume click: %w", err)
+	}
+	if !consumed {
+		return ErrClickLimitReached
+	}
+	link.ClicksUsed++
+	return nil
+}
+
+// hashPassword valide la longueur d'un mot de passe et retourne son hash bcrypt.
+func hashPassword(password string) (string, error) {
+	if len(password) < passwordMinLength || len(password) > passwordMaxLength {
+		return "", fmt.Errorf("%w: length must be between %d and %d characters", ErrInvalidPassword, passwordMinLength, passwordMaxLength)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// VerifyPassword compare un mot de passe saisi avec le hash stocké sur le lien.
+func (s *LinkService) VerifyPassword(link *models.Link, password string) bool {
+	if !link.IsProtected() {
+		return true
+	}
+	return bcrypt.CompareHashAndPassword([]byte(link.PasswordHash), []byte(password)) == nil
+}
+
+// UpdateLinkDestination change l'URL longue d'un lien et conserve l'ancienne valeur dans l'historique.
+// Si la destination est inchangée (après le suivi éventuel des raccourcisseurs), le lien est retourné
+// tel quel sans créer de révision. Une écriture différente d'une même adresse (slash final, ordre des
+// paramètres...) est bien enregistrée : le serveur de destination peut ne pas les traiter à l'identique.
+func (s *LinkService) UpdateLinkDestination(domain, shortCode, newURL, changedBy string) (*models.Link, error) {
+	if _, err := canonicalDestination(newURL); err != nil {
+		return nil, err
+	}
+	link, err := s.GetLinkByShortCode(domain, shortCode)
+	if err != nil {
+		return nil, err
+	}
+	if link.LongURL == newURL {
+		return link, nil
+	}
+	if err := s.checkURLs([]string{newURL}); err != nil {
+		return nil, err
+	}
+	if newURL, err = s.followURL(newURL); err != nil {
+		return nil, err
+	}
+	if link.LongURL == newURL {
+		return link, nil
+	}
+	canonical, err := canonicalDestination(newURL)
+	if err != nil {
+		return nil, err
+	}
+	return link, s.changeDestination(link, newURL, canonical, changedBy)
+}
+
+// GetLinkRevisions récupère un lien et l'historique de ses destinations, du plus récent au plus ancien.
+func (s *LinkService) GetLinkRevisions(domain, shortCode string) (*models.Link, []models.LinkRevision, error) {
+	link, err := s.GetLinkByShortCode(domain, shortCode)
+	if err != nil {
+		return nil, nil, err
+	}
+	revisions, err := s.linkRepo.GetRevisionsByLinkID(link.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to retrieve revisions: %w", err)
+	}
+	return link, revisions, nil
+}
+
+// RollbackLink annule une modification de destination en restaurant l'URL qui précédait la révision donnée.
+// Avec un revisionID à 0, la dernière modification est annulée. Le retour arrière est lui-même
+// enregistré comme une nouvelle révision, il peut donc être annulé à son tour.
+func (s *LinkService) RollbackLink(domain, shortCode string, revisionID uint, changedBy string) (*models.Link, error) {
+	link, err := s.GetLinkByShortCode(domain, shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	var revision *models.LinkRevision
+	if revisionID == 0 {
+		revisions, err := s.linkRepo.GetRevisionsByLinkID(link.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve revisions: %w", err)
+		}
+		if len(revisions) == 0 {
+			return nil, ErrNoRevision
+		}
+		revision = &revisions[0]
+	} else {
+		revision, err = s.linkRepo.GetRevision(link.ID, revisionID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("%w: revision %d not found", ErrNoRevision, revisionID)
+			}
+			return nil, fmt.Errorf("failed to retrieve revision: %w", err)
+		}
+	}
+
+	if link.LongURL == revision.OldURL {
+		return link, nil
+	}
+	// La politique a pu changer depuis : une ancienne destination désormais refusée n'est pas restaurée.
+	if err := s.checkURLs([]string{revision.OldURL}); err != nil {
+		return nil, err
+	}
+	// Une destination antérieure à la validation des URL que urlnorm ne sait pas analyser sert de forme canonique.
+	canonical, err := canonicalDestination(revision.OldURL)
+	if err != nil {
+		canonical = revision.OldURL
+	}
+	return link, s.changeDestination(link, revision.OldURL, canonical, changedBy)
+}
+
+// changeDestination applique une nouvelle destination au lien (et sa forme canonique)
+// et persiste la révision correspondante. Le signalement par les flux de menaces est recalculé
+// d'après les nouvelles destinations : un lien dont la destination listée a été remplacée n'est plus signalé.
+func (s *LinkService) changeDestination(link *models.Link, longURL, canonicalURL, changedBy string) error {
+	revision := &models.LinkRevision{
+		LinkID:    link.ID,
+		OldURL:    link.LongURL,
+		NewURL:    longURL,
+		ChangedBy: changedBy,
+	}
+	link.LongURL = longURL
+	link.CanonicalURL = canonicalURL
+	if s.threatChecker != nil {
+		s.refreshThreatFlag(link, time.Now().UTC())
+	}
+	if err := s.linkRepo.UpdateLongURL(link, revision); err != nil {
+		return fmt.Errorf("failed to update link destination: %w", err)
+	}
+	s.fetchMetadataAsync(link)
+	return nil
+}
+
+// GetLinkForRedirect récupère un lien par son code court, y compris s'il est à la corbeille,
+// afin que la redirection puisse répondre 410 plutôt que 404 pour un lien supprimé.
+func (s *LinkService) GetLinkForRedirect(domain, shortCode string) (*models.Link, error) {
 	if shortCode == "" {
 		return nil, errors.New("shortCode cannot be empty")
 	}
+	return s.linkRepo.GetLinkByShortCodeUnscoped(domain, shortCode)
+}
 
-	link, err := s.linkRepo.GetLinkByShortCode(shortCode)
+// DeleteLink place un lien à la corbeille. Ses clics sont conservés jusqu'à la purge définitive.
+func (s *LinkService) DeleteLink(domain, shortCode string) (*models.Link, error) {
+	link, err := s.GetLinkByShortCode(domain, shortCode)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.linkRepo.DeleteLink(link); err != nil {
+		return nil, fmt.Errorf("failed to delete link: %w", err)
+	}
+	return link, nil
+}
 
+// RestoreLink sort un lien de la corbeille et le réactive s'il était désactivé.
+func (s *LinkService) RestoreLink(domain, shortCode string) (*models.Link, error) {
+	link, err := s.GetLinkForRedirect(domain, shortCode)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.linkRepo.RestoreLink(link); err != nil {
+		return nil, fmt.Errorf("failed to restore link: %w", err)
+	}
+	link.DeletedAt = gorm.DeletedAt{}
+	link.Disabled = false
 	return link, nil
 }
 
+// DisableLink désactive un lien : la redirection répond 410 mais le lien reste consultable.
+func (s *LinkService) DisableLink(domain, shortCode string) (*models.Link, error) {
+	link, err := s.GetLinkByShortCode(domain, shortCode)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.linkRepo.SetDisabled(link, true); err != nil {
+		return nil, fmt.Errorf("failed to disable link: %w", err)
+	}
+	link.Disabled = true
+	return link, nil
+}
+
+// PurgeTrash supprime définitivement les liens restés à la corbeille plus longtemps que 'retention'.
+// Leurs codes restent en quarantaine pendant 'quarantine' avant de pouvoir être réattribués.
+func (s *LinkService) PurgeTrash(retention, quarantine time.Duration) ([]string, error) {
+	now := time.Now()
+	codes, err := s.linkRepo.PurgeDeletedLinks(now.Add(-retention), now.Add(-quarantine))
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge trash: %w", err)
+	}
+	return codes, nil
+}
+
 // GetLinkStats récupère les statistiques pour un lien donné (nombre total de clics).
-// Il interagit avec le LinkRepository pour obtenir le lien, puis avec le ClickRepository
-func (s *LinkService) GetLinkStats(shortCode string) (*models.Link, int, error) {
-	// TODO : Récupérer le lien par son shortCode
+// Il interagit avec le LinkRepository pour obtenir le lien, puis compter ses clics.
+func (s *LinkService) GetLinkStats(domain, shortCode string) (*models.Link, int, error) {
+	link, err := s.GetLinkByShortCode(domain, shortCode)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	totalClicks, err := s.linkRepo.CountClicksByLinkID(link.ID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count clicks: %w", err)
+	}
+
+	return link, totalClicks, nil
+}
 
-	// TODO 4: Compter le nombre de clics pour ce LinkID
+// GetClicksByPlatform retourne le nombre de clics d'un lien par plateforme servie.
+// Les clics enregistrés avant la détection des plateformes sont comptés sous "unknown".
+func (s *LinkService) GetClicksByPlatform(link *models.Link) (map[string]int, error) {
+	counts, err := s.linkRepo.CountClicksByPlatform(link.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count clicks by platform: %w", err)
+	}
+	if n, ok := counts[""]; ok {
+		delete(counts, "")
+		counts["unknown"] += n
+	}
+	return counts, nil
+}
 
-	// TODO : on retourne les 3 valeurs
-	return nil, 0, nil
+// GetClicksByVariant retourne le nombre de clics d'un lien par variante A/B servie.
+// Les clics servis hors test A/B (destination par plateforme ou pays, ou antérieurs aux variantes) sont ignorés.
+func (s *LinkService) GetClicksByVariant(link *models.Link) (map[string]int, error) {
+	counts, err := s.linkRepo.CountClicksByVariant(link.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count clicks by variant: %w", err)
+	}
+	delete(counts, "")
+	return counts, nil
+}
+
+// CountLinkClicks retourne le nombre total de clics enregistrés pour un lien déjà chargé.
+func (s *LinkService) CountLinkClicks(link *models.Link) (int, error) {
+	totalClicks, err := s.linkRepo.CountClicksByLinkID(link.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count clicks: %w", err)
+	}
+	return totalClicks, nil
 }
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/safety"
+	"github.com/axellelanca/urlshortener/internal/threats"
+)
+
+// ThreatChecker indique si une URL figure dans un flux de menaces. Implémentée par threats.Checker.
+type ThreatChecker interface {
+	Check(rawURL string) *threats.Match
+}
+
+// SetThreatChecker active la vérification des destinations dans les flux de menaces : une destination
+// listée est refusée à la création et à chaque modification, et RescanThreats signale les liens existants.
+func (s *LinkService) SetThreatChecker(checker ThreatChecker) {
+	s.threatChecker = checker
+}
+
+// checkThreat retourne une erreur enveloppant ErrUnsafeDestination et une *safety.Violation de raison
+// safety.ReasonKnownThreat si l'URL figure dans un flux de menaces.
+func (s *LinkService) checkThreat(rawURL string) error {
+	if s.threatChecker == nil {
+		return nil
+	}
+	match := s.threatChecker.Check(rawURL)
+	if match == nil {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrUnsafeDestination, &safety.Violation{
+		Reason: safety.ReasonKnownThreat,
+		URL:    rawURL,
+		Detail: fmt.Sprintf("listed in threat feed %s", match),
+	})
+}
+
+// threatMatch retourne la première destination du lien trouvée dans un flux de menaces, ou nil.
+func (s *LinkService) threatMatch(link *models.Link) *threats.Match {
+	for _, target := range link.Destinations() {
+		if match := s.threatChecker.Check(target); match != nil {
+			return match
+		}
+	}
+	return nil
+}
+
+// ThreatScanResult résume une vérification des liens existants dans les flux de menaces.
+type ThreatScanResult struct {
+	Scanned int // Liens vérifiés
+	Flagged int // Liens nouvellement signalés (ou dont la correspondance a changé)
+	Cleared int // Liens dont le signalement a été levé, leurs destinations n'étant plus listées
+}
+
+// RescanThreats vérifie toutes les destinations de tous les liens (voir GetAllLinks) dans les flux
+// de menaces, à lancer après chaque rechargement des flux. Un lien listé est signalé ; un lien signalé
+// qui ne l'est plus voit son signalement levé. La date du premier signalement est conservée.
+func (s *LinkService) RescanThreats() (ThreatScanResult, error) {
+	var result ThreatScanResult
+	if s.threatChecker == nil {
+		return result, nil
+	}
+	links, err := s.linkRepo.GetAllLinks()
+	if err != nil {
+		return result, fmt.Errorf("failed to retrieve links: %w", err)
+	}
+
+	now := time.Now().UTC()
+	for i := range links {
+		link := &links[i]
+		result.Scanned++
+
+		flagged, cleared := s.refreshThreatFlag(link, now)
+		switch {
+		case flagged:
+			result.Flagged++
+		case cleared:
+			result.Cleared++
+		default:
+			continue
+		}
+		if err := s.linkRepo.UpdateThreatFlag(link); err != nil {
+			return result, fmt.Errorf("failed to update threat flag of link %s: %w", link.QualifiedCode(), err)
+		}
+	}
+	return result, nil
+}
+
+// refreshThreatFlag recalcule le signalement du lien d'après ses destinations actuelles, sans l'enregistrer.
+// 'flagged' indique un lien nouvellement signalé ou dont la correspondance a changé, 'cleared' un signalement levé.
+// La date du premier signalement est conservée.
+func (s *LinkService) refreshThreatFlag(link *models.Link, now time.Time) (flagged, cleared bool) {
+	match := s.threatMatch(link)
+	switch {
+	case match != nil && (!link.IsFlagged() || link.ThreatFeed != match.Feed || link.ThreatEntry != match.Entry):
+		if !link.IsFlagged() {
+			link.FlaggedAt = &now
+		}
+		link.ThreatFeed, link.ThreatEntry = match.Feed, match.Entry
+		return true, false
+	case match == nil && link.IsFlagged():
+		link.FlaggedAt, link.ThreatFeed, link.ThreatEntry = nil, "", ""
+		return false, true
+	}
+	return false, false
+}
+
+// ListFlaggedLinks retourne les liens signalés par un flux de menaces, les plus récents d'abord.
+func (s *LinkService) ListFlaggedLinks() ([]models.Link, error) {
+	links, err := s.linkRepo.ListFlaggedLinks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list flagged links: %w", err)
+	}
+	return links, nil
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/threats"
+)
+
+// listedHosts est un flux de menaces en mémoire qui liste des noms d'hôte.
+type listedHosts map[string]bool
+
+func (l listedHosts) Check(rawURL string) *threats.Match {
+	u, err := url.Parse(rawURL)
+	if err != nil || !l[u.Hostname()] {
+		return nil
+	}
+	return &threats.Match{Feed: "test", Entry: u.Hostname()}
+}
+
+func TestChangeDestinationRefreshesThreatFlag(t *testing.T) {
+	service, repo := newTestService(t)
+	create := func(input CreateLinkInput) {
+		t.Helper()
+		if _, err := service.CreateLink(input); err != nil {
+			t.Fatalf("CreateLink(%s): %v", input.Alias, err)
+		}
+	}
+	create(CreateLinkInput{LongURL: "https://listed.example/a", Alias: "replaced"})
+	create(CreateLinkInput{LongURL: "https://listed.example/b", IOSURL: "https://ios-listed.example/", Alias: "still-listed"})
+	create(CreateLinkInput{LongURL: "https://old.example/", Alias: "rolled-back"})
+	if _, err := service.UpdateLinkDestination("", "rolled-back", "https://listed-later.example/", "test"); err != nil {
+		t.Fatalf("UpdateLinkDestination: %v", err)
+	}
+
+	feed := listedHosts{"listed.example": true, "ios-listed.example": true, "listed-later.example": true}
+	service.SetThreatChecker(feed)
+	if result, err := service.RescanThreats(); err != nil || result.Flagged != 3 {
+		t.Fatalf("RescanThreats = %+v, %v; want 3 flagged links", result, err)
+	}
+	before, err := service.GetLinkByShortCode("", "still-listed")
+	if err != nil {
+		t.Fatalf("GetLinkByShortCode: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		code      string
+		change    func() error
+		wantFlag  bool
+		wantEntry string
+	}{
+		{
+			name: "listed destination replaced",
+			code: "replaced",
+			change: func() error {
+				_, err := service.UpdateLinkDestination("", "replaced", "https://clean.example/", "test")
+				return err
+			},
+		},
+		{
+			name: "another destination still listed",
+			code: "still-listed",
+			change: func() error {
+				_, err := service.UpdateLinkDestination("", "still-listed", "https://clean.example/", "test")
+				return err
+			},
+			wantFlag:  true,
+			wantEntry: "ios-listed.example",
+		},
+		{
+			name:   "rolled back to a clean destination",
+			code:   "rolled-back",
+			change: func() error { _, err := service.RollbackLink("", "rolled-back", 0, "test"); return err },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.change(); err != nil {
+				t.Fatalf("change: %v", err)
+			}
+			stored, err := repo.GetLinkByShortCode("", tt.code)
+			if err != nil {
+				t.Fatalf("GetLinkByShortCode: %v", err)
+			}
+			if stored.IsFlagged() != tt.wantFlag || stored.ThreatEntry != tt.wantEntry {
+				t.Errorf("stored flag = %v (%q), want %v (%q)", stored.FlaggedAt, stored.ThreatEntry, tt.wantFlag, tt.wantEntry)
+			}
+		})
+	}
+
+	after, err := repo.GetLinkByShortCode("", "still-listed")
+	if err != nil {
+		t.Fatalf("GetLinkByShortCode: %v", err)
+	}
+	if !after.FlaggedAt.Equal(*before.FlaggedAt) {
+		t.Errorf("FlaggedAt = %v after the change, want the first flag date %v", after.FlaggedAt, before.FlaggedAt)
+	}
+
+	// Une destination listée reste refusée : le signalement ne sert qu'aux liens déjà enregistrés.
+	if _, err := service.UpdateLinkDestination("", "replaced", "https://listed.example/c", "test"); !errors.Is(err, ErrUnsafeDestination) {
+		t.Errorf("UpdateLinkDestination to a listed URL error = %v, want ErrUnsafeDestination", err)
+	}
+}
+
+func TestRescanThreatsAfterReload(t *testing.T) {
+	service, _ := newTestService(t)
+	for alias, longURL := range map[string]string{
+		"first":  "https://first.example/",
+		"second": "https://second.example/",
+		"clean":  "https://clean.example/",
+	} {
+		if _, err := service.CreateLink(CreateLinkInput{LongURL: longURL, Alias: alias}); err != nil {
+			t.Fatalf("CreateLink(%s): %v", alias, err)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "hosts.txt")
+	modified := time.Now().Add(-time.Hour)
+	writeFeed := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		// Chaque écriture reçoit sa propre date de modification, même sur un système de fichiers peu précis.
+		modified = modified.Add(time.Minute)
+		if err := os.Chtimes(path, modified, modified); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFeed("first.example\nsecond.example\n")
+	checker, err := threats.NewChecker([]threats.Feed{{Path: path}})
+	if err != nil {
+		t.Fatalf("NewChecker: %v", err)
+	}
+	if err := checker.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	service.SetThreatChecker(checker)
+
+	tests := []struct {
+		name string
+		feed string
+		want ThreatScanResult
+	}{
+		{name: "initial feed", want: ThreatScanResult{Scanned: 3, Flagged: 2}},
+		{name: "unchanged feed", want: ThreatScanResult{Scanned: 3}},
+		{name: "entry removed", feed: "first.example\n", want: ThreatScanResult{Scanned: 3, Cleared: 1}},
+		{name: "entry added", feed: "first.example\nclean.example\n", want: ThreatScanResult{Scanned: 3, Flagged: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.feed != "" {
+				writeFeed(tt.feed)
+				if reloaded, err := checker.ReloadIfChanged(); err != nil || !reloaded {
+					t.Fatalf("ReloadIfChanged = %v, %v; want true, nil", reloaded, err)
+				}
+			}
+			result, err := service.RescanThreats()
+			if err != nil {
+				t.Fatalf("RescanThreats: %v", err)
+			}
+			if result != tt.want {
+				t.Errorf("RescanThreats = %+v, want %+v", result, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,301 @@
+// Package threats confronte les URL de destination à des flux locaux d'hôtes malveillants
+// (hameçonnage, logiciels malveillants), sans aucun appel réseau. Deux formats sont lus :
+//
+//   - hosts : un hôte par ligne, ou des lignes au format /etc/hosts ("0.0.0.0 evil.example").
+//     Un hôte listé couvre aussi ses sous-domaines.
+//   - hashed : un préfixe hexadécimal (8 à 64 caractères) du SHA-256 d'une expression "hôte/chemin",
+//     à la manière des listes Safe Browsing (voir expressions).
+//
+// Dans les deux formats, les lignes vides et celles qui commencent par '#' sont ignorées.
+package threats
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Format est le format d'un fichier de flux.
+type Format string
+
+const (
+	FormatHosts  Format = "hosts"
+	FormatHashed Format = "hashed"
+)
+
+// Longueurs autorisées d'un préfixe de hash, en caractères hexadécimaux.
+const (
+	minPrefixLength = 8
+	maxPrefixLength = sha256.Size * 2
+)
+
+// ErrInvalidFeed est retournée pour un flux mal configuré ou un fichier de flux illisible.
+var ErrInvalidFeed = errors.New("invalid threat feed")
+
+// Feed décrit un fichier de flux. Name identifie le flux dans les signalements (par défaut le nom du fichier).
+type Feed struct {
+	Name   string
+	Path   string
+	Format Format
+}
+
+// Match décrit la correspondance d'une URL avec un flux : Entry est l'hôte ou le préfixe listé.
+type Match struct {
+	Feed  string
+	Entry string
+}
+
+// String implémente fmt.Stringer.
+func (m *Match) String() string {
+	return fmt.Sprintf("%s (%s)", m.Feed, m.Entry)
+}
+
+// list est le contenu chargé de tous les flux.
+type list struct {
+	hosts         map[string]string // Hôte -> flux
+	prefixes      map[string]string // Préfixe hexadécimal -> flux
+	prefixLengths []int             // Longueurs distinctes des préfixes, pour la recherche
+}
+
+// Checker confronte des URL aux flux chargés. Les flux peuvent être rechargés à chaud :
+// un rechargement échoué laisse les flux précédents en place.
+type Checker struct {
+	feeds []Feed
+
+	mu       sync.RWMutex // Protège current et modTimes pendant un rechargement
+	current  *list        // nil tant qu'aucun chargement n'a réussi
+	modTimes []time.Time  // Date de modification de chaque fichier au dernier chargement
+}
+
+// NewChecker valide la description des flux et crée le Checker correspondant. Les fichiers
+// ne sont lus qu'au premier Reload. Un format vide vaut FormatHosts.
+func NewChecker(feeds []Feed) (*Checker, error) {
+	checked := make([]Feed, len(feeds))
+	for i, feed := range feeds {
+		if feed.Path == "" {
+			return nil, fmt.Errorf("%w: feed %d has no path", ErrInvalidFeed, i+1)
+		}
+		if feed.Name == "" {
+			feed.Name = filepath.Base(feed.Path)
+		}
+		switch feed.Format {
+		case "":
+			feed.Format = FormatHosts
+		case FormatHosts, FormatHashed:
+		default:
+			return nil, fmt.Errorf("%w: unknown format %q for %s (expected hosts or hashed)", ErrInvalidFeed, feed.Format, feed.Path)
+		}
+		checked[i] = feed
+	}
+	return &Checker{feeds: checked}, nil
+}
+
+// Feeds retourne la description des flux du Checker.
+func (c *Checker) Feeds() []Feed {
+	return c.feeds
+}
+
+// Reload relit tous les flux. En cas d'échec d'un seul fichier, aucun flux n'est remplacé.
+func (c *Checker) Reload() error {
+	loaded := &list{hosts: make(map[string]string), prefixes: make(map[string]string)}
+	modTimes := make([]time.Time, len(c.feeds))
+	for i, feed := range c.feeds {
+		info, err := os.Stat(feed.Path)
+		if err != nil {
+			return err
+		}
+		if err := loaded.load(feed); err != nil {
+			return err
+		}
+		modTimes[i] = info.ModTime()
+	}
+	slices.Sort(loaded.prefixLengths)
+
+	c.mu.Lock()
+	c.current = loaded
+	c.modTimes = modTimes
+	c.mu.Unlock()
+	return nil
+}
+
+// ReloadIfChanged relit les flux si l'un des fichiers a été modifié depuis le dernier chargement.
+// Elle retourne true si de nouveaux flux ont été chargés.
+func (c *Checker) ReloadIfChanged() (bool, error) {
+	c.mu.RLock()
+	unchanged := c.current != nil
+	for i, feed := range c.feeds {
+		if !unchanged {
+			break
+		}
+		info, err := os.Stat(feed.Path)
+		unchanged = err == nil && info.ModTime().Equal(c.modTimes[i])
+	}
+	c.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+	if err := c.Reload(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Len retourne le nombre d'hôtes et de préfixes chargés.
+func (c *Checker) Len() (hosts, prefixes int) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.current == nil {
+		return 0, 0
+	}
+	return len(c.current.hosts), len(c.current.prefixes)
+}
+
+// load ajoute le contenu d'un fichier de flux à la liste.
+func (l *list) load(feed Feed) error {
+	file, err := os.Open(feed.Path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if feed.Format == FormatHashed {
+			prefix := strings.ToLower(text)
+			if _, err := hex.DecodeString(prefix); err != nil || len(prefix) < minPrefixLength || len(prefix) > maxPrefixLength {
+				return fmt.Errorf("%w: %s:%d: expected a hex SHA-256 prefix of %d to %d characters", ErrInvalidFeed, feed.Path, line, minPrefixLength, maxPrefixLength)
+			}
+			if _, seen := l.prefixes[prefix]; !seen && !slices.Contains(l.prefixLengths, len(prefix)) {
+				l.prefixLengths = append(l.prefixLengths, len(prefix))
+			}
+			l.prefixes[prefix] = feed.Name
+			continue
+		}
+
+		// Format hosts : "hôte" ou "adresse hôte [hôte...]" comme dans /etc/hosts.
+		fields := strings.Fields(text)
+		if len(fields) > 1 {
+			if _, err := netip.ParseAddr(fields[0]); err == nil {
+				fields = fields[1:]
+			}
+		}
+		for _, host := range fields {
+			if strings.HasPrefix(host, "#") {
+				break
+			}
+			l.hosts[normalizeHost(host)] = feed.Name
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidFeed, feed.Path, err)
+	}
+	return nil
+}
+
+// Check retourne la première correspondance de l'URL avec les flux chargés, ou nil.
+// Un Checker nil, ou dont aucun flux n'a encore été chargé, ne signale rien.
+func (c *Checker) Check(rawURL string) *Match {
+	if c == nil {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return nil
+	}
+	host := normalizeHost(u.Hostname())
+
+	c.mu.RLock()
+	current := c.current
+	c.mu.RUnlock()
+	if current == nil {
+		return nil
+	}
+
+	for _, suffix := range hostSuffixes(host) {
+		if feed, ok := current.hosts[suffix]; ok {
+			return &Match{Feed: feed, Entry: suffix}
+		}
+	}
+	if len(current.prefixes) == 0 {
+		return nil
+	}
+	for _, expression := range expressions(host, u) {
+		sum := sha256.Sum256([]byte(expression))
+		digest := hex.EncodeToString(sum[:])
+		for _, length := range current.prefixLengths {
+			if feed, ok := current.prefixes[digest[:length]]; ok {
+				return &Match{Feed: feed, Entry: digest[:length]}
+			}
+		}
+	}
+	return nil
+}
+
+// normalizeHost met un hôte en minuscules, sans point final.
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// hostSuffixes retourne l'hôte puis au plus quatre de ses domaines parents, en partant des cinq
+// derniers composants et sans jamais retenir le seul domaine de premier niveau. Une adresse IP
+// n'a pas de domaine parent.
+func hostSuffixes(host string) []string {
+	if _, err := netip.ParseAddr(host); err == nil {
+		return []string{host}
+	}
+	suffixes := []string{host}
+	labels := strings.Split(host, ".")
+	start := max(1, len(labels)-5)
+	for i := start; i < len(labels)-1 && len(suffixes) < 5; i++ {
+		suffixes = append(suffixes, strings.Join(labels[i:], "."))
+	}
+	return suffixes
+}
+
+// expressions retourne les expressions "hôte/chemin" dont le hash est recherché dans les flux hashed :
+// chaque suffixe d'hôte (voir hostSuffixes) combiné au chemin exact avec sa query string, au chemin
+// exact sans query string, et à au plus quatre préfixes du chemin en partant de "/".
+func expressions(host string, u *url.URL) []string {
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	paths := []string{}
+	if u.RawQuery != "" {
+		paths = append(paths, path+"?"+u.RawQuery)
+	}
+	paths = append(paths, path)
+	prefix := "/"
+	components := strings.Split(strings.Trim(path, "/"), "/")
+	for i := 0; i < len(components) && len(paths) < 6; i++ {
+		if !slices.Contains(paths, prefix) {
+			paths = append(paths, prefix)
+		}
+		if components[i] == "" {
+			break
+		}
+		prefix += components[i] + "/"
+	}
+
+	var result []string
+	for _, suffix := range hostSuffixes(host) {
+		for _, p := range paths {
+			result = append(result, suffix+p)
+		}
+	}
+	return result
+}
@@ -0,0 +1,251 @@
+package threats
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFeed écrit un fichier de flux temporaire et retourne son chemin.
+func writeFeed(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// prefix retourne les 'length' premiers caractères hexadécimaux du SHA-256 d'une expression.
+func prefix(expression string, length int) string {
+	sum := sha256.Sum256([]byte(expression))
+	return hex.EncodeToString(sum[:])[:length]
+}
+
+// loadedChecker crée un Checker sur les flux donnés et les charge.
+func loadedChecker(t *testing.T, feeds ...Feed) *Checker {
+	t.Helper()
+	checker, err := NewChecker(feeds)
+	if err != nil {
+		t.Fatalf("NewChecker: %v", err)
+	}
+	if err := checker.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	return checker
+}
+
+func TestHostSuffixes(t *testing.T) {
+	tests := []struct {
+		host string
+		want []string
+	}{
+		{"example.com", []string{"example.com"}},
+		{"a.b.example.com", []string{"a.b.example.com", "b.example.com", "example.com"}},
+		{"a.b.c.d.e.f.g", []string{"a.b.c.d.e.f.g", "c.d.e.f.g", "d.e.f.g", "e.f.g", "f.g"}},
+		{"localhost", []string{"localhost"}},
+		{"192.0.2.1", []string{"192.0.2.1"}},
+		{"2001:db8::1", []string{"2001:db8::1"}},
+	}
+	for _, tt := range tests {
+		if got := hostSuffixes(tt.host); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("hostSuffixes(%q) = %q, want %q", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestExpressions(t *testing.T) {
+	tests := []struct {
+		url  string
+		want []string
+	}{
+		{
+			url: "http://a.b.c/1/2.html?param=1",
+			want: []string{
+				"a.b.c/1/2.html?param=1", "a.b.c/1/2.html", "a.b.c/", "a.b.c/1/",
+				"b.c/1/2.html?param=1", "b.c/1/2.html", "b.c/", "b.c/1/",
+			},
+		},
+		{
+			url:  "http://example.com",
+			want: []string{"example.com/"},
+		},
+		{
+			url:  "http://example.com/dir/",
+			want: []string{"example.com/dir/", "example.com/"},
+		},
+		{
+			// Au plus six chemins : les préfixes les plus profonds ne sont pas essayés.
+			url:  "http://example.com/1/2/3/4/5/6",
+			want: []string{"example.com/1/2/3/4/5/6", "example.com/", "example.com/1/", "example.com/1/2/", "example.com/1/2/3/", "example.com/1/2/3/4/"},
+		},
+		{
+			url:  "http://example.com/1/2/3/4/5/6?q=1",
+			want: []string{"example.com/1/2/3/4/5/6?q=1", "example.com/1/2/3/4/5/6", "example.com/", "example.com/1/", "example.com/1/2/", "example.com/1/2/3/"},
+		},
+		{
+			url:  "http://example.com/a%20b?q",
+			want: []string{"example.com/a%20b?q", "example.com/a%20b", "example.com/"},
+		},
+	}
+	for _, tt := range tests {
+		u, err := url.Parse(tt.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := expressions(normalizeHost(u.Hostname()), u); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("expressions(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	hosts := writeFeed(t, "hosts.txt", strings.Join([]string{
+		"# Hameçonnage",
+		"evil.example",
+		"0.0.0.0 Malware.Example. tracker.example # bloqué",
+		"",
+		"198.51.100.7",
+	}, "\n"))
+	hashed := writeFeed(t, "hashed.txt", strings.Join([]string{
+		"# Préfixes de longueurs différentes",
+		prefix("phish.example/login/", 8),
+		strings.ToUpper(prefix("bank.example/", 16)),
+		prefix("files.example/payload.exe?id=42", 64),
+	}, "\n"))
+	checker := loadedChecker(t,
+		Feed{Path: hosts},
+		Feed{Name: "safe-browsing", Path: hashed, Format: FormatHashed},
+	)
+
+	tests := []struct {
+		url       string
+		wantFeed  string
+		wantEntry string
+	}{
+		{url: "https://evil.example/", wantFeed: "hosts.txt", wantEntry: "evil.example"},
+		{url: "https://EVIL.example./path", wantFeed: "hosts.txt", wantEntry: "evil.example"},
+		{url: "https://sub.evil.example/", wantFeed: "hosts.txt", wantEntry: "evil.example"},
+		{url: "https://notevil.example/"},
+		{url: "http://malware.example/", wantFeed: "hosts.txt", wantEntry: "malware.example"},
+		{url: "http://tracker.example/", wantFeed: "hosts.txt", wantEntry: "tracker.example"},
+		{url: "http://bloqué/"},
+		{url: "http://198.51.100.7:8080/", wantFeed: "hosts.txt", wantEntry: "198.51.100.7"},
+		{url: "https://phish.example/login/form.php?x=1", wantFeed: "safe-browsing", wantEntry: prefix("phish.example/login/", 8)},
+		{url: "https://www.phish.example/login/", wantFeed: "safe-browsing", wantEntry: prefix("phish.example/login/", 8)},
+		{url: "https://phish.example/logout/"},
+		{url: "https://bank.example/anything", wantFeed: "safe-browsing", wantEntry: prefix("bank.example/", 16)},
+		{url: "https://files.example/payload.exe?id=42", wantFeed: "safe-browsing", wantEntry: prefix("files.example/payload.exe?id=42", 64)},
+		{url: "https://files.example/payload.exe?id=43"},
+		{url: "https://clean.example/"},
+		{url: "not a url"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			got := checker.Check(tt.url)
+			if tt.wantFeed == "" {
+				if got != nil {
+					t.Fatalf("Check(%q) = %v, want nil", tt.url, got)
+				}
+				return
+			}
+			if got == nil || got.Feed != tt.wantFeed || got.Entry != tt.wantEntry {
+				t.Fatalf("Check(%q) = %v, want %s (%s)", tt.url, got, tt.wantFeed, tt.wantEntry)
+			}
+		})
+	}
+
+	if hosts, prefixes := checker.Len(); hosts != 4 || prefixes != 3 {
+		t.Errorf("Len() = %d, %d; want 4, 3", hosts, prefixes)
+	}
+}
+
+func TestCheckBeforeLoad(t *testing.T) {
+	var none *Checker
+	if got := none.Check("https://evil.example/"); got != nil {
+		t.Errorf("nil Checker Check = %v, want nil", got)
+	}
+	checker, err := NewChecker([]Feed{{Path: "unused.txt"}})
+	if err != nil {
+		t.Fatalf("NewChecker: %v", err)
+	}
+	if got := checker.Check("https://evil.example/"); got != nil {
+		t.Errorf("Check before Reload = %v, want nil", got)
+	}
+}
+
+func TestInvalidFeeds(t *testing.T) {
+	tests := []struct {
+		name   string
+		feeds  []Feed
+		reload bool // L'erreur n'apparaît qu'à la lecture du fichier
+	}{
+		{name: "missing path", feeds: []Feed{{Name: "x"}}},
+		{name: "unknown format", feeds: []Feed{{Path: "feed.txt", Format: "csv"}}},
+		{name: "prefix too short", feeds: []Feed{{Path: writeFeed(t, "short.txt", "abcdef0"), Format: FormatHashed}}, reload: true},
+		{name: "prefix too long", feeds: []Feed{{Path: writeFeed(t, "long.txt", strings.Repeat("a", 65)), Format: FormatHashed}}, reload: true},
+		{name: "prefix not hex", feeds: []Feed{{Path: writeFeed(t, "hex.txt", "evil.example"), Format: FormatHashed}}, reload: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker, err := NewChecker(tt.feeds)
+			if tt.reload {
+				if err != nil {
+					t.Fatalf("NewChecker: %v", err)
+				}
+				err = checker.Reload()
+			}
+			if !errors.Is(err, ErrInvalidFeed) {
+				t.Errorf("error = %v, want ErrInvalidFeed", err)
+			}
+		})
+	}
+}
+
+func TestReloadIfChanged(t *testing.T) {
+	path := writeFeed(t, "hosts.txt", "evil.example\n")
+	checker := loadedChecker(t, Feed{Path: path})
+	modified := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(path, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded, err := checker.ReloadIfChanged(); err != nil || !reloaded {
+		t.Fatalf("ReloadIfChanged after a new mtime = %v, %v; want true, nil", reloaded, err)
+	}
+
+	if reloaded, err := checker.ReloadIfChanged(); err != nil || reloaded {
+		t.Fatalf("ReloadIfChanged without change = %v, %v; want false, nil", reloaded, err)
+	}
+
+	if err := os.WriteFile(path, []byte("other.example\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	modified = modified.Add(time.Minute)
+	if err := os.Chtimes(path, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded, err := checker.ReloadIfChanged(); err != nil || !reloaded {
+		t.Fatalf("ReloadIfChanged after an edit = %v, %v; want true, nil", reloaded, err)
+	}
+	if checker.Check("https://evil.example/") != nil || checker.Check("https://other.example/") == nil {
+		t.Fatal("the edited feed was not loaded")
+	}
+
+	// Un fichier disparu fait échouer le rechargement et laisse le flux précédent en place.
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded, err := checker.ReloadIfChanged(); err == nil || reloaded {
+		t.Fatalf("ReloadIfChanged with a missing file = %v, %v; want an error", reloaded, err)
+	}
+	if checker.Check("https://other.example/") == nil {
+		t.Error("a failed reload dropped the previous feed")
+	}
+}
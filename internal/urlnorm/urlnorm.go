@@ -0,0 +1,111 @@
+// Package urlnorm met les URL de destination sous une forme canonique, afin que deux écritures
+// d'une même adresse (majuscules dans l'hôte, port par défaut, slash final, ordre des paramètres...)
+// soient reconnues comme équivalentes. La forme canonique sert uniquement de clé de comparaison :
+// elle ne désigne pas forcément la même ressource pour tous les serveurs et ne sert jamais à rediriger.
+package urlnorm
+
+import (
+	"net"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// defaultPorts associe chaque schéma à son port par défaut, omis de la forme canonique.
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// percentEscape repère les séquences d'échappement, dont les chiffres hexadécimaux sont mis en majuscules.
+var percentEscape = regexp.MustCompile(`%[0-9a-fA-F]{2}`)
+
+// Canonicalize retourne la forme canonique d'une URL absolue :
+//   - schéma et hôte en minuscules, sans point final ni port par défaut ;
+//   - chemin vide remplacé par "/", segments "." et ".." résolus, slash final retiré ;
+//   - échappements en majuscules ("%2f" devient "%2F") ;
+//   - paramètres de la query string triés par nom (l'ordre des valeurs d'un même nom est conservé),
+//     séparateurs "&" superflus (segments vides) et "?" final retirés.
+//
+// L'encodage des paramètres et le fragment sont conservés tels quels. Une URL sans hôte
+// (ex: "mailto:...") est retournée sans autre transformation que l'analyse.
+func Canonicalize(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", err
+	}
+	if u.Opaque != "" || u.Host == "" {
+		return u.String(), nil
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = canonicalHost(u.Scheme, u.Hostname(), u.Port())
+
+	escaped := canonicalPath(u.EscapedPath())
+	if u.Path, err = url.PathUnescape(escaped); err != nil {
+		return "", err
+	}
+	u.RawPath = escaped
+
+	u.RawQuery = canonicalQuery(u.RawQuery)
+	u.ForceQuery = false
+	return u.String(), nil
+}
+
+// canonicalHost met l'hôte en minuscules et retire le port s'il s'agit du port par défaut du schéma.
+func canonicalHost(scheme, hostname, port string) string {
+	hostname = strings.TrimSuffix(strings.ToLower(hostname), ".")
+	if port == defaultPorts[scheme] {
+		port = ""
+	}
+	if port == "" {
+		if strings.Contains(hostname, ":") {
+			return "[" + hostname + "]" // IPv6
+		}
+		return hostname
+	}
+	return net.JoinHostPort(hostname, port)
+}
+
+// canonicalPath normalise un chemin encodé. Le travail sur la forme encodée garantit qu'un "%2F"
+// n'est jamais confondu avec un séparateur.
+func canonicalPath(escaped string) string {
+	escaped = percentEscape.ReplaceAllStringFunc(escaped, strings.ToUpper)
+	if escaped == "" {
+		return "/"
+	}
+	if slices.ContainsFunc(strings.Split(escaped, "/"), func(segment string) bool {
+		return segment == "." || segment == ".."
+	}) {
+		escaped = path.Clean(escaped)
+	}
+	if len(escaped) > 1 {
+		escaped = strings.TrimRight(escaped, "/")
+	}
+	if escaped == "" {
+		return "/"
+	}
+	return escaped
+}
+
+// canonicalQuery trie les paramètres d'une query string brute par nom, sans les réencoder.
+func canonicalQuery(rawQuery string) string {
+	var params []string
+	for _, param := range strings.Split(rawQuery, "&") {
+		if param != "" {
+			params = append(params, percentEscape.ReplaceAllStringFunc(param, strings.ToUpper))
+		}
+	}
+	slices.SortStableFunc(params, func(a, b string) int {
+		return strings.Compare(paramName(a), paramName(b))
+	})
+	return strings.Join(params, "&")
+}
+
+// paramName retourne le nom (encore encodé) d'un paramètre "nom=valeur".
+func paramName(param string) string {
+	name, _, _ := strings.Cut(param, "=")
+	return name
+}
@@ -0,0 +1,77 @@
+package urlnorm
+
+import "testing"
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"already canonical", "https://example.com/a/b?x=1", "https://example.com/a/b?x=1"},
+		{"scheme and host case", "HTTPS://Example.COM/Path", "https://example.com/Path"},
+		{"trailing dot in host", "https://example.com./a", "https://example.com/a"},
+		{"default http port", "http://example.com:80/a", "http://example.com/a"},
+		{"default https port", "https://example.com:443/a", "https://example.com/a"},
+		{"non-default port kept", "https://example.com:8443/a", "https://example.com:8443/a"},
+		{"port of the other scheme kept", "http://example.com:443/a", "http://example.com:443/a"},
+		{"IPv6 host", "http://[2001:DB8::1]:80/", "http://[2001:db8::1]/"},
+		{"IPv6 host with port", "http://[2001:db8::1]:8080/", "http://[2001:db8::1]:8080/"},
+		{"empty path", "https://example.com", "https://example.com/"},
+		{"trailing slash", "https://example.com/a/", "https://example.com/a"},
+		{"repeated trailing slashes", "https://example.com/a//", "https://example.com/a"},
+		{"root with slashes", "https://example.com//", "https://example.com/"},
+		{"dot segments", "https://example.com/a/./b/../c", "https://example.com/a/c"},
+		{"dot segments above root", "https://example.com/../a", "https://example.com/a"},
+		{"escape case in path", "https://example.com/a%2fb", "https://example.com/a%2Fb"},
+		{"encoded slash is not a separator", "https://example.com/a%2F..%2Fb", "https://example.com/a%2F..%2Fb"},
+		{"query sorted by name", "https://example.com/?b=2&a=1", "https://example.com/?a=1&b=2"},
+		{"values of a name keep their order", "https://example.com/?x=2&a=1&x=1", "https://example.com/?a=1&x=2&x=1"},
+		{"empty query segments", "https://example.com/?&a=1&&b=2&", "https://example.com/?a=1&b=2"},
+		{"trailing question mark", "https://example.com/a?", "https://example.com/a"},
+		{"escape case in query", "https://example.com/?q=a%2fb", "https://example.com/?q=a%2Fb"},
+		{"query encoding kept", "https://example.com/?q=a+b&r=c%20d", "https://example.com/?q=a+b&r=c%20d"},
+		{"fragment kept", "https://example.com/a/#Top", "https://example.com/a#Top"},
+		{"surrounding spaces", "  https://example.com/a  ", "https://example.com/a"},
+		{"opaque URL unchanged", "mailto:Someone@Example.com", "mailto:Someone@Example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Canonicalize(tt.raw)
+			if err != nil {
+				t.Fatalf("Canonicalize(%q): %v", tt.raw, err)
+			}
+			if got != tt.want {
+				t.Errorf("Canonicalize(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeIsIdempotent(t *testing.T) {
+	for _, raw := range []string{
+		"HTTP://Example.com:80/a/./b/?z=1&&a=%2f",
+		"https://example.com/a%2Fb/",
+		"https://[::1]:443",
+	} {
+		once, err := Canonicalize(raw)
+		if err != nil {
+			t.Fatalf("Canonicalize(%q): %v", raw, err)
+		}
+		twice, err := Canonicalize(once)
+		if err != nil {
+			t.Fatalf("Canonicalize(%q): %v", once, err)
+		}
+		if once != twice {
+			t.Errorf("Canonicalize is not idempotent: %q -> %q -> %q", raw, once, twice)
+		}
+	}
+}
+
+func TestCanonicalizeRejectsInvalidURL(t *testing.T) {
+	for _, raw := range []string{"http://exa mple.com/", "https://example.com/%zz", "://missing-scheme"} {
+		if got, err := Canonicalize(raw); err == nil {
+			t.Errorf("Canonicalize(%q) = %q, want an error", raw, got)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package workers
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/geoip"
+)
+
+// StartGeoIPReload recharge la base GeoIP à chaque signal reçu sur 'reload' (SIGHUP côté serveur)
+// et, si 'interval' est positif, dès que le fichier .mmdb est modifié sur le disque.
+// Cette fonction est conçue pour être lancée dans une goroutine séparée.
+func StartGeoIPReload(resolver *geoip.Resolver, interval time.Duration, reload <-chan os.Signal) {
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+		log.Printf("[GEOIP] Surveillance de %s toutes les %v...", resolver.Path(), interval)
+	}
+
+	for {
+		select {
+		case <-reload:
+			if err := resolver.Reload(); err != nil {
+				log.Printf("[GEOIP] ERREUR lors du rechargement de la base %s : %v", resolver.Path(), err)
+				continue
+			}
+			log.Printf("[GEOIP] Base %s rechargée.", resolver.Path())
+		case <-tick:
+			reloaded, err := resolver.ReloadIfChanged()
+			if err != nil {
+				log.Printf("[GEOIP] ERREUR lors du rechargement de la base %s : %v", resolver.Path(), err)
+				continue
+			}
+			if reloaded {
+				log.Printf("[GEOIP] Nouvelle version de la base %s chargée.", resolver.Path())
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package workers
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/axellelanca/urlshortener/internal/threats"
+)
+
+// StartThreatFeedReload revérifie tous les liens existants dans les flux de menaces déjà chargés, puis
+// recharge les flux à chaque signal reçu sur 'reload' (SIGHUP côté serveur) et, si 'interval' est positif,
+// dès qu'un des fichiers est modifié sur le disque. Chaque rechargement réussi est suivi d'une nouvelle
+// vérification des liens. Cette fonction est conçue pour être lancée dans une goroutine séparée.
+func StartThreatFeedReload(checker *threats.Checker, linkService *services.LinkService, interval time.Duration, reload <-chan os.Signal) {
+	rescanThreats(linkService)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+		log.Printf("[THREATS] Surveillance de %d flux toutes les %v...", len(checker.Feeds()), interval)
+	}
+
+	for {
+		select {
+		case <-reload:
+			if err := checker.Reload(); err != nil {
+				log.Printf("[THREATS] ERREUR lors du rechargement des flux : %v", err)
+				continue
+			}
+		case <-tick:
+			reloaded, err := checker.ReloadIfChanged()
+			if err != nil {
+				log.Printf("[THREATS] ERREUR lors du rechargement des flux : %v", err)
+				continue
+			}
+			if !reloaded {
+				continue
+			}
+		}
+		hosts, prefixes := checker.Len()
+		log.Printf("[THREATS] Flux rechargés : %d hôte(s), %d préfixe(s).", hosts, prefixes)
+		rescanThreats(linkService)
+	}
+}
+
+// rescanThreats revérifie tous les liens et journalise le résultat.
+func rescanThreats(linkService *services.LinkService) {
+	result, err := linkService.RescanThreats()
+	if err != nil {
+		log.Printf("[THREATS] ERREUR lors de la vérification des liens : %v", err)
+		return
+	}
+	log.Printf("[THREATS] %d lien(s) vérifié(s) : %d signalé(s), %d signalement(s) levé(s).", result.Scanned, result.Flagged, result.Cleared)
+}
@@ -0,0 +1,37 @@
+package workers
+
+import (
+	"log"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/services"
+)
+
+// StartTrashPurge lance la boucle de purge périodique de la corbeille.
+// Cette fonction est conçue pour être lancée dans une goroutine séparée.
+// Les liens supprimés depuis plus de 'retention' sont effacés définitivement,
+// et leurs codes restent en quarantaine pendant 'quarantine'.
+func StartTrashPurge(linkService *services.LinkService, interval, retention, quarantine time.Duration) {
+	log.Printf("[TRASH] Démarrage de la purge de la corbeille avec un intervalle de %v...", interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// Exécute une première purge immédiatement au démarrage
+	purgeTrash(linkService, retention, quarantine)
+
+	for range ticker.C {
+		purgeTrash(linkService, retention, quarantine)
+	}
+}
+
+// purgeTrash effectue un passage de purge et journalise les codes supprimés.
+func purgeTrash(linkService *services.LinkService, retention, quarantine time.Duration) {
+	codes, err := linkService.PurgeTrash(retention, quarantine)
+	if err != nil {
+		log.Printf("[TRASH] ERREUR lors de la purge de la corbeille : %v", err)
+		return
+	}
+	if len(codes) > 0 {
+		log.Printf("[TRASH] %d lien(s) purgé(s) définitivement : %v", len(codes), codes)
+	}
+}
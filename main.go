@@ -7,6 +7,6 @@ import (
 )
 
 func main() {
-	// TODO Exécute la commande racine de Cobra.
+	// Exécute la commande racine de Cobra.
 	cmd.Execute()
 }